@@ -0,0 +1,281 @@
+package cst
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/martinkreibe-wk/geneva/elements"
+)
+
+const (
+
+	// ErrNotCollection defines the error for an edit that requires a collection.
+	ErrNotCollection = elements.Error("Node is not a collection")
+
+	// ErrNotMap defines the error for an edit that requires a map.
+	ErrNotMap = elements.Error("Node is not a map")
+
+	// ErrDetached defines the error for an edit of a node that has no parent.
+	ErrDetached = elements.Error("Node has no parent")
+
+	// ErrOddMap defines the error for a map with a key but no value.
+	ErrOddMap = elements.Error("Map has a key without a value")
+
+	// defaultSeparator is used between values when the collection gives no example to copy.
+	defaultSeparator = " "
+)
+
+// Entry is a key and value pair within a map node.
+type Entry struct {
+
+	// Key node of the entry.
+	Key *Node
+
+	// Value node of the entry.
+	Value *Node
+}
+
+// Entries returns the key and value pairs of a map node.
+func (node *Node) Entries() (entries []Entry, err error) {
+	if node.Kind == MapNode {
+		values := node.Values()
+		if len(values)%2 == 0 {
+			for i := 0; i < len(values); i += 2 {
+				entries = append(entries, Entry{Key: values[i], Value: values[i+1]})
+			}
+		} else {
+			err = ErrOddMap
+		}
+	} else {
+		err = ErrNotMap
+	}
+
+	return entries, err
+}
+
+// Lookup finds the value for the key in a map node. The key is compared with the source text of the map keys, so
+// ":db/ident" finds the entry written as :db/ident. A nil value is returned if the key is not found.
+func (node *Node) Lookup(key string) (value *Node, err error) {
+	var entries []Entry
+	if entries, err = node.Entries(); err == nil {
+		for _, entry := range entries {
+			if entry.Key.String() == key {
+				value = entry.Value
+				break
+			}
+		}
+	}
+
+	return value, err
+}
+
+// Replace this node within its parent. Only the replaced region changes when the tree is printed.
+func (node *Node) Replace(with *Node) (err error) {
+	if parent := node.parent; parent != nil {
+		if index := parent.indexOf(node); index >= 0 {
+			parent.Children[index] = with
+			with.parent = parent
+			node.parent = nil
+		} else {
+			err = ErrDetached
+		}
+	} else {
+		err = ErrDetached
+	}
+
+	return err
+}
+
+// Remove this node from its parent along with the whitespace that precedes it, or the whitespace that follows it if
+// it is the first node in the collection.
+func (node *Node) Remove() (err error) {
+	if parent := node.parent; parent != nil {
+		if index := parent.indexOf(node); index >= 0 {
+			from, to := index, index+1
+			if from > 0 && parent.Children[from-1].Kind == WhitespaceNode {
+				from--
+			} else if to < len(parent.Children) && parent.Children[to].Kind == WhitespaceNode {
+				to++
+			}
+
+			parent.Children = append(parent.Children[:from:from], parent.Children[to:]...)
+			node.parent = nil
+		} else {
+			err = ErrDetached
+		}
+	} else {
+		err = ErrDetached
+	}
+
+	return err
+}
+
+// Append adds the value to the end of a collection, copying the separator used before the last existing value.
+func (node *Node) Append(value *Node) (err error) {
+	if node.Kind.IsCollection() || node.Kind == DocumentNode {
+		node.insertAfterLast(node.separator(1), value)
+	} else {
+		err = ErrNotCollection
+	}
+
+	return err
+}
+
+// SetEntry sets the value of the key within a map node. An existing value is replaced in place. A new entry is added
+// after the last entry, on a line of its own if the entries are, with its value lined up with the value of the last
+// entry if the values are in a column.
+func (node *Node) SetEntry(key, value *Node) (err error) {
+
+	var existing *Node
+	if existing, err = node.Lookup(key.String()); err == nil {
+		if existing != nil {
+			err = existing.Replace(value)
+		} else {
+			node.insertAfterLast(node.separator(2), key, node.keyValueSeparator(key), value)
+		}
+	}
+
+	return err
+}
+
+// RemoveEntry removes the key and its value from a map node. It is not an error if the key is not present.
+func (node *Node) RemoveEntry(key string) (err error) {
+	var entries []Entry
+	if entries, err = node.Entries(); err == nil {
+		for _, entry := range entries {
+			if entry.Key.String() == key {
+				if err = entry.Value.Remove(); err == nil {
+					err = entry.Key.Remove()
+				}
+				break
+			}
+		}
+	}
+
+	return err
+}
+
+// indexOf returns the index of the child, or -1 if not found.
+func (node *Node) indexOf(child *Node) int {
+	for i, c := range node.Children {
+		if c == child {
+			return i
+		}
+	}
+	return -1
+}
+
+// separator finds the whitespace that comes before the last item in the collection, where an item is stride values
+// wide (1 for sequences, 2 for maps). If there is no such example the default separator is used. The first item of a
+// collection has no separator before it, so an empty collection yields no separator.
+func (node *Node) separator(stride int) (separator *Node) {
+	values := node.Values()
+	switch {
+	case len(values) == 0:
+		// Nothing to separate from.
+
+	case len(values) > stride:
+		last := values[len(values)-stride]
+		previous := values[len(values)-stride-1]
+		text := node.whitespaceBetween(previous, last)
+		if text == "" {
+			text = defaultSeparator
+		}
+		separator = &Node{Kind: WhitespaceNode, Text: text}
+
+	default:
+		separator = &Node{Kind: WhitespaceNode, Text: defaultSeparator}
+	}
+
+	return separator
+}
+
+// keyValueSeparator returns the whitespace between a new key and its value. If the last entry pads its key with spaces
+// to line the values up in a column, the new key is padded to the same column when it fits, otherwise a single space
+// is used.
+func (node *Node) keyValueSeparator(key *Node) *Node {
+
+	text := defaultSeparator
+	if entries, _ := node.Entries(); len(entries) > 0 {
+		last := entries[len(entries)-1]
+		lastKey, newKey := last.Key.String(), key.String()
+		padding := node.whitespaceBetween(last.Key, last.Value)
+
+		if node.indexOf(last.Value)-node.indexOf(last.Key) == 2 && len(padding) > len(defaultSeparator) &&
+			strings.Trim(padding, " ") == "" && !strings.Contains(lastKey+newKey, "\n") {
+
+			column := utf8.RuneCountInString(lastKey) + len(padding)
+			if width := column - utf8.RuneCountInString(newKey); width > len(defaultSeparator) {
+				text = strings.Repeat(" ", width)
+			}
+		}
+	}
+
+	return &Node{Kind: WhitespaceNode, Text: text}
+}
+
+// whitespaceBetween returns the last run of whitespace between the two children, or an empty string. Only the last run
+// is used so that comments between the children are not copied.
+func (node *Node) whitespaceBetween(from, to *Node) (text string) {
+	if start, end := node.indexOf(from), node.indexOf(to); start >= 0 && end-1 > start {
+		if child := node.Children[end-1]; child.Kind == WhitespaceNode {
+			text = child.Text
+		}
+	}
+
+	return text
+}
+
+// insertAfterLast inserts the nodes after the last value of the collection, and after any comment on the same line as
+// that value, so that trailing comments stay with the value they describe.
+func (node *Node) insertAfterLast(separator *Node, nodes ...*Node) {
+
+	index := 0
+	for i, child := range node.Children {
+		if !child.Kind.IsTrivia() {
+			index = i + 1
+		}
+	}
+
+	// keep a trailing comment on the same line with the value it follows.
+	if index > 0 {
+		next := index
+		if next < len(node.Children) && node.Children[next].Kind == WhitespaceNode && !containsLineEnd(node.Children[next].Text) {
+			next++
+		}
+		if next < len(node.Children) && node.Children[next].Kind == CommentNode {
+			index = next + 1
+
+			// the comment runs to the end of the line, so the new nodes must start on the next one.
+			if separator == nil || !containsLineEnd(separator.Text) {
+				text := "\n"
+				if separator != nil {
+					text += separator.Text
+				}
+				separator = &Node{Kind: WhitespaceNode, Text: text}
+			}
+		}
+	}
+
+	if separator != nil {
+		nodes = append([]*Node{separator}, nodes...)
+	}
+
+	children := make([]*Node, 0, len(node.Children)+len(nodes))
+	children = append(children, node.Children[:index]...)
+	children = append(children, nodes...)
+	children = append(children, node.Children[index:]...)
+	node.Children = children
+
+	node.adopt(nodes...)
+}
+
+// containsLineEnd is true if the text contains a line break.
+func containsLineEnd(text string) bool {
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' || text[i] == '\r' {
+			return true
+		}
+	}
+	return false
+}
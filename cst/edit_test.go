@@ -0,0 +1,153 @@
+package cst
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Editing a concrete syntax tree", func() {
+
+	schema := `;; people
+[{:db/ident       :person/name   ; primary name
+  :db/valueType   :db.type/string
+  :db/cardinality :db.cardinality/one
+  :db/doc         "A person's name"}
+
+ ;; contact details
+ {:db/ident :person/email}]
+`
+
+	form := func(src string) *Node {
+		node, err := ParseForm(src)
+		Ω(err).Should(BeNil())
+		return node
+	}
+
+	first := func(doc *Node) *Node {
+		return doc.Values()[0].Values()[0]
+	}
+
+	It("should replace a value and leave the rest of the file alone", func() {
+		doc, err := Parse([]byte(schema))
+		Ω(err).Should(BeNil())
+
+		var doc1 *Node
+		doc1, err = first(doc).Lookup(":db/doc")
+		Ω(err).Should(BeNil())
+		Ω(doc1).ShouldNot(BeNil())
+
+		err = doc1.Replace(form(`"The full name of a person"`))
+		Ω(err).Should(BeNil())
+
+		Ω(doc.String()).Should(BeIdenticalTo(`;; people
+[{:db/ident       :person/name   ; primary name
+  :db/valueType   :db.type/string
+  :db/cardinality :db.cardinality/one
+  :db/doc         "The full name of a person"}
+
+ ;; contact details
+ {:db/ident :person/email}]
+`))
+	})
+
+	It("should insert a map entry using the existing layout", func() {
+		doc, err := Parse([]byte(schema))
+		Ω(err).Should(BeNil())
+
+		err = first(doc).SetEntry(form(":db/index"), form("true"))
+		Ω(err).Should(BeNil())
+
+		Ω(doc.String()).Should(BeIdenticalTo(`;; people
+[{:db/ident       :person/name   ; primary name
+  :db/valueType   :db.type/string
+  :db/cardinality :db.cardinality/one
+  :db/doc         "A person's name"
+  :db/index       true}
+
+ ;; contact details
+ {:db/ident :person/email}]
+`))
+	})
+
+	It("should use a single space after a new key unless the values are lined up", func() {
+		for _, test := range []struct {
+			src      string
+			expected string
+		}{
+			{`{:a 1}`, `{:a 1 :b 2}`},
+			{`{:long-key 1}`, `{:long-key 1 :b 2}`},
+			{"{:a   1\n :bc  2}", "{:a   1\n :bc  2\n :b   2}"},
+			{"{:a 1\n :bc  2}", "{:a 1\n :bc  2\n :b   2}"},
+			{"{:a  1\n :c  2}", "{:a  1\n :c  2\n :b  2}"},
+			{"{:a ; key\n   1}", "{:a ; key\n   1 :b 2}"},
+			{"{:a   1\n :long  2}", "{:a   1\n :long  2\n :b     2}"},
+			{"{:a\t1}", `{:a	1 :b 2}`},
+		} {
+			doc, err := Parse([]byte(test.src))
+			Ω(err).Should(BeNil())
+
+			err = doc.Values()[0].SetEntry(form(":b"), form("2"))
+			Ω(err).Should(BeNil())
+			Ω(doc.String()).Should(BeIdenticalTo(test.expected), test.src)
+		}
+	})
+
+	It("should replace the value of an existing map entry", func() {
+		doc, err := Parse([]byte(`{:a 1 :b 2}`))
+		Ω(err).Should(BeNil())
+
+		err = doc.Values()[0].SetEntry(form(":b"), form("[3]"))
+		Ω(err).Should(BeNil())
+		Ω(doc.String()).Should(BeIdenticalTo(`{:a 1 :b [3]}`))
+	})
+
+	It("should keep a trailing comment with its entry", func() {
+		doc, err := Parse([]byte("{:a 1 ; first\n}"))
+		Ω(err).Should(BeNil())
+
+		err = doc.Values()[0].SetEntry(form(":b"), form("2"))
+		Ω(err).Should(BeNil())
+		Ω(doc.String()).Should(BeIdenticalTo("{:a 1 ; first\n :b 2\n}"))
+	})
+
+	It("should append to sequences", func() {
+		doc, err := Parse([]byte("[]"))
+		Ω(err).Should(BeNil())
+
+		vec := doc.Values()[0]
+		Ω(vec.Append(form("1"))).Should(BeNil())
+		Ω(vec.Append(form("2"))).Should(BeNil())
+		Ω(doc.String()).Should(BeIdenticalTo("[1 2]"))
+
+		doc, err = Parse([]byte("[1\n 2]"))
+		Ω(err).Should(BeNil())
+		Ω(doc.Values()[0].Append(form("3"))).Should(BeNil())
+		Ω(doc.String()).Should(BeIdenticalTo("[1\n 2\n 3]"))
+
+		Ω(form("1").Append(form("2"))).Should(BeIdenticalTo(ErrNotCollection))
+	})
+
+	It("should remove entries", func() {
+		doc, err := Parse([]byte(`{:a 1, :b 2, :c 3}`))
+		Ω(err).Should(BeNil())
+
+		err = doc.Values()[0].RemoveEntry(":b")
+		Ω(err).Should(BeNil())
+		Ω(doc.String()).Should(BeIdenticalTo(`{:a 1, :c 3}`))
+
+		err = doc.Values()[0].RemoveEntry(":a")
+		Ω(err).Should(BeNil())
+		Ω(doc.String()).Should(BeIdenticalTo(`{:c 3}`))
+	})
+
+	It("should not edit detached nodes or non maps", func() {
+		Ω(form("1").Replace(form("2"))).Should(BeIdenticalTo(ErrDetached))
+		Ω(form("1").Remove()).Should(BeIdenticalTo(ErrDetached))
+
+		_, err := form("[1 2]").Lookup(":a")
+		Ω(err).Should(BeIdenticalTo(ErrNotMap))
+
+		_, err = form("{:a}").Entries()
+		Ω(err).Should(BeIdenticalTo(ErrOddMap))
+	})
+})
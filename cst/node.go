@@ -0,0 +1,172 @@
+package cst
+
+import (
+	"bytes"
+	"io"
+)
+
+// NodeKind identifies the syntactic construct a node represents.
+type NodeKind int
+
+const (
+
+	// DocumentNode is the root of a parsed file. It has no delimiters and holds every top level form and the trivia
+	// between them.
+	DocumentNode NodeKind = iota
+
+	// WhitespaceNode holds a run of whitespace, which in EDN includes commas.
+	WhitespaceNode
+
+	// CommentNode holds a line comment, from the ; up to (but not including) the line end.
+	CommentNode
+
+	// DiscardNode holds a #_ discard marker, the trivia after it and the discarded form.
+	DiscardNode
+
+	// TaggedNode holds a #tag, the trivia after it and the tagged form.
+	TaggedNode
+
+	// ListNode is a (...) collection.
+	ListNode
+
+	// VectorNode is a [...] collection.
+	VectorNode
+
+	// MapNode is a {...} collection.
+	MapNode
+
+	// SetNode is a #{...} collection.
+	SetNode
+
+	// StringNode is a "..." literal, the text includes the quotes and escapes as written.
+	StringNode
+
+	// CharacterNode is a \c literal.
+	CharacterNode
+
+	// TokenNode is any other atom: nil, booleans, numbers, symbols and keywords.
+	TokenNode
+)
+
+// kindNames hold the printable names of the node kinds.
+var kindNames = map[NodeKind]string{
+	DocumentNode:   "document",
+	WhitespaceNode: "whitespace",
+	CommentNode:    "comment",
+	DiscardNode:    "discard",
+	TaggedNode:     "tagged",
+	ListNode:       "list",
+	VectorNode:     "vector",
+	MapNode:        "map",
+	SetNode:        "set",
+	StringNode:     "string",
+	CharacterNode:  "character",
+	TokenNode:      "token",
+}
+
+// String returns the name of the kind.
+func (kind NodeKind) String() string {
+	return kindNames[kind]
+}
+
+// IsTrivia is true for nodes that carry no data: whitespace, comments and discarded forms.
+func (kind NodeKind) IsTrivia() bool {
+	return kind == WhitespaceNode || kind == CommentNode || kind == DiscardNode
+}
+
+// IsCollection is true for nodes that are delimited sequences of other nodes.
+func (kind NodeKind) IsCollection() bool {
+	return kind == ListNode || kind == VectorNode || kind == MapNode || kind == SetNode
+}
+
+// Node is a single node within the concrete syntax tree. Every byte of the source belongs to exactly one leaf, so
+// printing the tree reproduces the source exactly.
+type Node struct {
+
+	// Kind of this node.
+	Kind NodeKind
+
+	// Text holds the source text for leaves, the #tag (including the #) for tagged nodes, and the #_ marker for
+	// discards. It is empty for documents and collections.
+	Text string
+
+	// Open holds the opening delimiter of a collection.
+	Open string
+
+	// Close holds the closing delimiter of a collection.
+	Close string
+
+	// Children of this node, including all trivia, in source order.
+	Children []*Node
+
+	// Offset is the byte offset where this node started in the parsed source. Nodes created by an edit have no
+	// meaningful offset.
+	Offset int
+
+	// parent of this node, nil for the root.
+	parent *Node
+}
+
+// Parent returns the node that holds this node, or nil for the root.
+func (node *Node) Parent() *Node {
+	return node.parent
+}
+
+// Values returns the children that carry data, skipping whitespace, comments and discarded forms.
+func (node *Node) Values() (values []*Node) {
+	for _, child := range node.Children {
+		if !child.Kind.IsTrivia() {
+			values = append(values, child)
+		}
+	}
+	return values
+}
+
+// Value returns the form held by a tagged or discard node, or nil if there is none.
+func (node *Node) Value() (value *Node) {
+	if node.Kind == TaggedNode || node.Kind == DiscardNode {
+		for _, child := range node.Children {
+			if !child.Kind.IsTrivia() {
+				value = child
+				break
+			}
+		}
+	}
+	return value
+}
+
+// WriteTo writes the source text of this node to the writer.
+func (node *Node) WriteTo(w io.Writer) (n int64, err error) {
+	var buf bytes.Buffer
+	node.write(&buf)
+	return buf.WriteTo(w)
+}
+
+// Bytes returns the source text of this node.
+func (node *Node) Bytes() []byte {
+	var buf bytes.Buffer
+	node.write(&buf)
+	return buf.Bytes()
+}
+
+// String returns the source text of this node.
+func (node *Node) String() string {
+	return string(node.Bytes())
+}
+
+// write appends the source text of this node into the buffer.
+func (node *Node) write(buf *bytes.Buffer) {
+	buf.WriteString(node.Text)
+	buf.WriteString(node.Open)
+	for _, child := range node.Children {
+		child.write(buf)
+	}
+	buf.WriteString(node.Close)
+}
+
+// adopt will take ownership of the children.
+func (node *Node) adopt(children ...*Node) {
+	for _, child := range children {
+		child.parent = node
+	}
+}
@@ -0,0 +1,311 @@
+package cst
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/martinkreibe-wk/geneva/elements"
+)
+
+const (
+
+	// ErrUnexpectedEnd defines the error for input that stops in the middle of a form.
+	ErrUnexpectedEnd = elements.Error("Unexpected end of input")
+
+	// ErrUnexpectedDelimiter defines the error for a closing delimiter that does not close anything.
+	ErrUnexpectedDelimiter = elements.Error("Unexpected delimiter")
+
+	// ErrMissingForm defines the error for a tag or discard without a following form.
+	ErrMissingForm = elements.Error("Missing form")
+
+	// ErrInvalidDispatch defines the error for a # that is not followed by a known dispatch character.
+	ErrInvalidDispatch = elements.Error("Invalid dispatch")
+)
+
+// delimiters pair every opening delimiter with its closing delimiter and kind.
+var delimiters = map[string]struct {
+	close string
+	kind  NodeKind
+}{
+	"(":  {")", ListNode},
+	"[":  {"]", VectorNode},
+	"{":  {"}", MapNode},
+	"#{": {"}", SetNode},
+}
+
+// SyntaxError defines a parse failure and where it happened.
+type SyntaxError struct {
+
+	// Offset is the byte offset of the failure.
+	Offset int
+
+	// Line is the 1 based line of the failure.
+	Line int
+
+	// Column is the 1 based column (in runes) of the failure.
+	Column int
+
+	// Err is the underlying reason.
+	Err error
+}
+
+// Error returns the error message.
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Err)
+}
+
+// Unwrap returns the underlying reason.
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// parser holds the state while building the tree.
+type parser struct {
+	src    string
+	offset int
+}
+
+// Parse the source into a document node. The document prints back to exactly the source.
+func Parse(src []byte) (doc *Node, err error) {
+	p := &parser{src: string(src)}
+
+	doc = &Node{Kind: DocumentNode}
+	for err == nil && p.offset < len(p.src) {
+		var child *Node
+		if child, err = p.next(); err == nil {
+			if child.Kind == TokenNode && delimiterClose(child.Text) {
+				err = p.fail(child.Offset, ErrUnexpectedDelimiter)
+			} else {
+				doc.Children = append(doc.Children, child)
+			}
+		}
+	}
+
+	if err == nil {
+		doc.adopt(doc.Children...)
+	} else {
+		doc = nil
+	}
+
+	return doc, err
+}
+
+// ParseForm parses a single form, with no surrounding trivia. This is the way to build nodes to insert into a tree.
+func ParseForm(src string) (node *Node, err error) {
+	var doc *Node
+	if doc, err = Parse([]byte(src)); err == nil {
+		if values := doc.Values(); len(values) == 1 && len(doc.Children) == 1 {
+			node = values[0]
+			node.parent = nil
+		} else {
+			err = elements.NewError("Expected exactly one form: %q", src)
+		}
+	}
+
+	return node, err
+}
+
+// fail builds the syntax error for the given offset.
+func (p *parser) fail(offset int, reason error) error {
+	line := strings.Count(p.src[:offset], "\n") + 1
+	lineStart := strings.LastIndex(p.src[:offset], "\n") + 1
+
+	return &SyntaxError{
+		Offset: offset,
+		Line:   line,
+		Column: utf8.RuneCountInString(p.src[lineStart:offset]) + 1,
+		Err:    reason,
+	}
+}
+
+// delimiterClose is true if the text is a closing delimiter.
+func delimiterClose(text string) bool {
+	return text == ")" || text == "]" || text == "}"
+}
+
+// isWhitespace is true for runes that separate forms. Commas are whitespace in EDN.
+func isWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == ','
+}
+
+// isTerminator is true for bytes that end a token.
+func isTerminator(c byte) bool {
+	return isWhitespace(c) || strings.IndexByte(`()[]{}";`, c) >= 0
+}
+
+// next reads the next node, trivia or form. A stray closing delimiter is returned as a token so the caller can decide
+// if it closes the current collection.
+func (p *parser) next() (node *Node, err error) {
+	start := p.offset
+	c := p.src[start]
+
+	switch {
+	case isWhitespace(c):
+		for p.offset < len(p.src) && isWhitespace(p.src[p.offset]) {
+			p.offset++
+		}
+		node = &Node{Kind: WhitespaceNode, Text: p.src[start:p.offset], Offset: start}
+
+	case c == ';':
+		for p.offset < len(p.src) && p.src[p.offset] != '\n' && p.src[p.offset] != '\r' {
+			p.offset++
+		}
+		node = &Node{Kind: CommentNode, Text: p.src[start:p.offset], Offset: start}
+
+	case delimiterClose(string(c)):
+		p.offset++
+		node = &Node{Kind: TokenNode, Text: string(c), Offset: start}
+
+	case c == '(' || c == '[' || c == '{':
+		node, err = p.collection(string(c))
+
+	case c == '"':
+		node, err = p.str()
+
+	case c == '\\':
+		p.offset++
+		if p.offset < len(p.src) {
+			_, size := utf8.DecodeRuneInString(p.src[p.offset:])
+			p.offset += size
+			for p.offset < len(p.src) && !isTerminator(p.src[p.offset]) {
+				p.offset++
+			}
+			node = &Node{Kind: CharacterNode, Text: p.src[start:p.offset], Offset: start}
+		} else {
+			err = p.fail(start, ErrUnexpectedEnd)
+		}
+
+	case c == '#':
+		node, err = p.dispatch()
+
+	default:
+		for p.offset < len(p.src) && !isTerminator(p.src[p.offset]) {
+			p.offset++
+		}
+		node = &Node{Kind: TokenNode, Text: p.src[start:p.offset], Offset: start}
+	}
+
+	return node, err
+}
+
+// collection reads a delimited collection.
+func (p *parser) collection(open string) (node *Node, err error) {
+	start := p.offset
+	p.offset += len(open)
+
+	def := delimiters[open]
+	node = &Node{Kind: def.kind, Open: open, Offset: start}
+
+	closed := false
+	for err == nil && !closed {
+		if p.offset >= len(p.src) {
+			err = p.fail(start, ErrUnexpectedEnd)
+		} else {
+			var child *Node
+			if child, err = p.next(); err == nil {
+				if child.Kind == TokenNode && delimiterClose(child.Text) {
+					if child.Text == def.close {
+						node.Close = child.Text
+						closed = true
+					} else {
+						err = p.fail(child.Offset, ErrUnexpectedDelimiter)
+					}
+				} else {
+					node.Children = append(node.Children, child)
+				}
+			}
+		}
+	}
+
+	if err == nil {
+		node.adopt(node.Children...)
+	}
+
+	return node, err
+}
+
+// str reads a string literal, escapes are kept as written.
+func (p *parser) str() (node *Node, err error) {
+	start := p.offset
+	p.offset++
+
+	closed := false
+	for !closed && p.offset < len(p.src) {
+		switch p.src[p.offset] {
+		case '\\':
+			p.offset += 2
+		case '"':
+			closed = true
+			p.offset++
+		default:
+			p.offset++
+		}
+	}
+
+	if closed {
+		node = &Node{Kind: StringNode, Text: p.src[start:p.offset], Offset: start}
+	} else {
+		p.offset = len(p.src)
+		err = p.fail(start, ErrUnexpectedEnd)
+	}
+
+	return node, err
+}
+
+// dispatch reads the forms that start with #: sets, discards and tagged forms.
+func (p *parser) dispatch() (node *Node, err error) {
+	start := p.offset
+
+	switch {
+	case strings.HasPrefix(p.src[start:], "#{"):
+		node, err = p.collection("#{")
+
+	case strings.HasPrefix(p.src[start:], "#_"):
+		p.offset += 2
+		node = &Node{Kind: DiscardNode, Text: "#_", Offset: start}
+		err = p.prefixed(node)
+
+	default:
+		p.offset++
+		for p.offset < len(p.src) && !isTerminator(p.src[p.offset]) {
+			p.offset++
+		}
+
+		if p.offset-start > 1 {
+			node = &Node{Kind: TaggedNode, Text: p.src[start:p.offset], Offset: start}
+			err = p.prefixed(node)
+		} else {
+			err = p.fail(start, ErrInvalidDispatch)
+		}
+	}
+
+	return node, err
+}
+
+// prefixed reads the trivia and the single form that follow a tag or discard marker.
+func (p *parser) prefixed(node *Node) (err error) {
+
+	found := false
+	for err == nil && !found {
+		if p.offset >= len(p.src) {
+			err = p.fail(node.Offset, ErrMissingForm)
+		} else {
+			var child *Node
+			if child, err = p.next(); err == nil {
+				if child.Kind == TokenNode && delimiterClose(child.Text) {
+					err = p.fail(node.Offset, ErrMissingForm)
+				} else {
+					node.Children = append(node.Children, child)
+					found = !child.Kind.IsTrivia()
+				}
+			}
+		}
+	}
+
+	if err == nil {
+		node.adopt(node.Children...)
+	}
+
+	return err
+}
@@ -0,0 +1,116 @@
+package cst
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Parsing a concrete syntax tree", func() {
+	Context("with well formed input", func() {
+
+		sources := []string{
+			"",
+			"   ",
+			"nil",
+			"; only a comment",
+			"{:a 1, :b 2}",
+			"[1 2.5 \"three\" \\c \\newline :four five]",
+			"#{1 2 3}",
+			"(def my/foo [])",
+			"#inst \"1985-04-12T23:20:50.52Z\"",
+			"#db/id [:db.part/db]",
+			"[1 #_ 2 3]",
+			"[1 #_ #_ 2 3 4]",
+			"\"escaped \\\" quote\"",
+			";; schema\n[{:db/ident :person/name ; the name\n  :db/valueType :db.type/string}\n\n ;; trailing\n]\n",
+			"{:a\t1\r\n,,,:b 2}",
+		}
+
+		It("should print every source back byte for byte", func() {
+			for _, src := range sources {
+				doc, err := Parse([]byte(src))
+				Ω(err).Should(BeNil(), src)
+				Ω(doc.String()).Should(BeIdenticalTo(src))
+			}
+		})
+
+		It("should separate trivia from values", func() {
+			doc, err := Parse([]byte("; lead\n[1 #_ 2 3] ; trail\n"))
+			Ω(err).Should(BeNil())
+			Ω(doc.Kind).Should(BeEquivalentTo(DocumentNode))
+
+			values := doc.Values()
+			Ω(values).Should(HaveLen(1))
+			Ω(values[0].Kind).Should(BeEquivalentTo(VectorNode))
+			Ω(values[0].Parent()).Should(BeIdenticalTo(doc))
+
+			items := values[0].Values()
+			Ω(items).Should(HaveLen(2))
+			Ω(items[0].Text).Should(BeEquivalentTo("1"))
+			Ω(items[1].Text).Should(BeEquivalentTo("3"))
+
+			kinds := []NodeKind{}
+			for _, child := range values[0].Children {
+				kinds = append(kinds, child.Kind)
+			}
+			Ω(kinds).Should(Equal([]NodeKind{TokenNode, WhitespaceNode, DiscardNode, WhitespaceNode, TokenNode}))
+		})
+
+		It("should hold the tagged value", func() {
+			node, err := ParseForm("#db/id [:db.part/db]")
+			Ω(err).Should(BeNil())
+			Ω(node.Kind).Should(BeEquivalentTo(TaggedNode))
+			Ω(node.Text).Should(BeEquivalentTo("#db/id"))
+			Ω(node.Value()).ShouldNot(BeNil())
+			Ω(node.Value().Kind).Should(BeEquivalentTo(VectorNode))
+			Ω(node.Value().String()).Should(BeEquivalentTo("[:db.part/db]"))
+		})
+
+		It("should record the offsets", func() {
+			doc, err := Parse([]byte("  [:a \"b\"]"))
+			Ω(err).Should(BeNil())
+			vec := doc.Values()[0]
+			Ω(vec.Offset).Should(BeEquivalentTo(2))
+			Ω(vec.Values()[1].Offset).Should(BeEquivalentTo(6))
+		})
+	})
+
+	Context("with malformed input", func() {
+
+		It("should report where the failure happened", func() {
+			malformed := map[string]struct {
+				err    error
+				line   int
+				column int
+			}{
+				"[1 2":             {ErrUnexpectedEnd, 1, 1},
+				"{:a 1]":           {ErrUnexpectedDelimiter, 1, 6},
+				"\n  )":            {ErrUnexpectedDelimiter, 2, 3},
+				"\"open":           {ErrUnexpectedEnd, 1, 1},
+				"[#_]":             {ErrMissingForm, 1, 2},
+				"#tag":             {ErrMissingForm, 1, 1},
+				"[1\n # 2]":        {ErrInvalidDispatch, 2, 2},
+				"{:a \"é\" :b 1)}": {ErrUnexpectedDelimiter, 1, 13},
+			}
+
+			for src, expected := range malformed {
+				doc, err := Parse([]byte(src))
+				Ω(doc).Should(BeNil(), src)
+				Ω(err).Should(BeAssignableToTypeOf(&SyntaxError{}), src)
+
+				syntaxErr := err.(*SyntaxError)
+				Ω(syntaxErr.Err).Should(BeIdenticalTo(expected.err), src)
+				Ω(syntaxErr.Line).Should(BeEquivalentTo(expected.line), src)
+				Ω(syntaxErr.Column).Should(BeEquivalentTo(expected.column), src)
+			}
+		})
+
+		It("should only build a single form", func() {
+			_, err := ParseForm("1 2")
+			Ω(err).ShouldNot(BeNil())
+
+			_, err = ParseForm(" 1")
+			Ω(err).ShouldNot(BeNil())
+		})
+	})
+})
@@ -0,0 +1,244 @@
+package edn
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/martinkreibe-wk/geneva/elements"
+	"github.com/mattrobenolt/gocql/uuid"
+)
+
+// Builder collects the children of a map, vector, list or set. Every method adds one child and returns the builder,
+// maps take their children as alternating keys and values. Once a child fails to build the rest are ignored, and the
+// error is returned by Build.
+type Builder struct {
+
+	// elemType is the type of collection being built.
+	elemType elements.ElementType
+
+	// items added so far.
+	items []elements.Element
+
+	// err is the first error.
+	err error
+}
+
+// Map starts a map. Its children are added as alternating keys and values.
+func Map() *Builder {
+	return &Builder{elemType: elements.MapType}
+}
+
+// Vec starts a vector.
+func Vec() *Builder {
+	return &Builder{elemType: elements.VectorType}
+}
+
+// List starts a list.
+func List() *Builder {
+	return &Builder{elemType: elements.GroupingType}
+}
+
+// Set starts a set.
+func Set() *Builder {
+	return &Builder{elemType: elements.SetType}
+}
+
+// add appends the child, or records the error with the location of the child.
+func (b *Builder) add(value interface{}, elem elements.Element, err error) *Builder {
+	if b.err == nil {
+		if err == nil {
+			b.items = append(b.items, elem)
+		} else {
+			b.err = b.wrap(value, err)
+		}
+	}
+
+	return b
+}
+
+// wrap adds the location of the next child to the error. Errors from nested builders already hold the rest of the
+// path, they are copied rather than changed since the nested builder keeps and returns its own error.
+func (b *Builder) wrap(value interface{}, err error) error {
+
+	var segment interface{} = len(b.items)
+	if b.elemType == elements.MapType && len(b.items)%2 == 1 {
+		segment = b.items[len(b.items)-1]
+	}
+
+	elemErr, is := err.(*elements.ElementError)
+	if is {
+		elemErr = &elements.ElementError{
+			Path:  append([]interface{}{segment}, elemErr.Path...),
+			Type:  elemErr.Type,
+			Value: elemErr.Value,
+			Err:   elemErr.Err,
+		}
+	} else {
+		elemErr = &elements.ElementError{Path: []interface{}{segment}, Value: value, Err: err}
+	}
+
+	return elemErr
+}
+
+// Kw adds a keyword, e.g. Kw(":db/ident") or Kw("db", "ident").
+func (b *Builder) Kw(parts ...string) *Builder {
+	elem, err := elements.NewKeywordElement(append([]string{}, parts...)...)
+	return b.add(parts, elem, err)
+}
+
+// Sym adds a symbol, e.g. Sym("my/fn") or Sym("my", "fn").
+func (b *Builder) Sym(parts ...string) *Builder {
+	elem, err := elements.NewSymbolElement(parts...)
+	return b.add(parts, elem, err)
+}
+
+// Str adds a string.
+func (b *Builder) Str(value string) *Builder {
+	elem, err := elements.NewStringElement(value)
+	return b.add(value, elem, err)
+}
+
+// Int adds an integer.
+func (b *Builder) Int(value int64) *Builder {
+	elem, err := elements.NewIntegerElement(value)
+	return b.add(value, elem, err)
+}
+
+// BigInt adds an arbitrary precision integer.
+func (b *Builder) BigInt(value *big.Int) *Builder {
+	elem, err := elements.NewBigIntElement(value)
+	return b.add(value, elem, err)
+}
+
+// Float adds a floating point number.
+func (b *Builder) Float(value float64) *Builder {
+	elem, err := elements.NewFloatElement(value)
+	return b.add(value, elem, err)
+}
+
+// BigDec adds an arbitrary precision floating point number.
+func (b *Builder) BigDec(value *big.Float) *Builder {
+	elem, err := elements.NewBigDecElement(value)
+	return b.add(value, elem, err)
+}
+
+// Bool adds a boolean.
+func (b *Builder) Bool(value bool) *Builder {
+	elem, err := elements.NewBooleanElement(value)
+	return b.add(value, elem, err)
+}
+
+// Nil adds nil.
+func (b *Builder) Nil() *Builder {
+	elem, err := elements.NewNilElement()
+	return b.add(nil, elem, err)
+}
+
+// Char adds a character.
+func (b *Builder) Char(value rune) *Builder {
+	elem, err := elements.NewCharacterElement(value)
+	return b.add(value, elem, err)
+}
+
+// Inst adds an instant.
+func (b *Builder) Inst(value time.Time) *Builder {
+	elem, err := elements.NewInstantElement(value)
+	return b.add(value, elem, err)
+}
+
+// UUID adds a UUID.
+func (b *Builder) UUID(value uuid.UUID) *Builder {
+	elem, err := elements.NewUUIDElement(value)
+	return b.add(value, elem, err)
+}
+
+// Val adds a value: an element, a builder, which is built, or a native value converted by elements.NewElement. Plain
+// ints are accepted as integers.
+func (b *Builder) Val(value interface{}) *Builder {
+	elem, err := resolve(value)
+	return b.add(value, elem, err)
+}
+
+// Tagged adds the value under the tag, e.g. Tagged("db/id", Vec().Kw(":db.part/user")). The value is resolved as with
+// Val.
+func (b *Builder) Tagged(tag string, value interface{}) *Builder {
+
+	var elem elements.Element
+	var symbol elements.SymbolElement
+	inner, err := resolve(value)
+	if err == nil {
+		if symbol, err = elements.NewSymbolElement(tag); err == nil {
+			elem, err = elements.NewTaggedElement(symbol, inner)
+		} else {
+			err = elements.ErrInvalidTag
+		}
+	}
+
+	return b.add(value, elem, err)
+}
+
+// Err returns the first error so far, if any.
+func (b *Builder) Err() error {
+	return b.err
+}
+
+// Build creates the collection, or returns the first error.
+func (b *Builder) Build() (elem elements.Element, err error) {
+	return b.BuildCollection()
+}
+
+// BuildCollection creates the collection, or returns the first error.
+func (b *Builder) BuildCollection() (elem elements.CollectionElement, err error) {
+
+	if err = b.err; err == nil {
+		switch b.elemType {
+		case elements.MapType:
+			if len(b.items)%2 == 0 {
+				if elem, err = elements.NewMap(); err == nil {
+					for i := 0; err == nil && i < len(b.items); i += 2 {
+						// maps find keys by type and value, so only a key that equals the new one is a duplicate.
+						if _, e := elem.Get(b.items[i]); e == nil {
+							err = &elements.ElementError{Path: []interface{}{b.items[i]}, Err: elements.ErrDuplicateKey}
+						} else {
+							err = elem.Append(b.items[i], b.items[i+1])
+						}
+					}
+				}
+			} else {
+				err = &elements.ElementError{Path: []interface{}{b.items[len(b.items)-1]}, Err: ErrMissingValue}
+			}
+
+		case elements.VectorType:
+			elem, err = elements.NewVector(b.items...)
+
+		case elements.GroupingType:
+			elem, err = elements.NewGroup(b.items...)
+
+		case elements.SetType:
+			elem, err = elements.NewSet(b.items...)
+		}
+	}
+
+	if err != nil {
+		elem = nil
+	}
+
+	return elem, err
+}
+
+// resolve converts a value to an element.
+func resolve(value interface{}) (elem elements.Element, err error) {
+
+	switch v := value.(type) {
+	case nil:
+		elem, err = elements.NewNilElement()
+	case elements.Element:
+		elem = v
+	case int:
+		elem, err = elements.NewIntegerElement(int64(v))
+	default:
+		elem, err = elements.NewElement(v)
+	}
+
+	return elem, err
+}
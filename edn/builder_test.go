@@ -0,0 +1,120 @@
+package edn
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/martinkreibe-wk/geneva/elements"
+	"github.com/mattrobenolt/gocql/uuid"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Building EDN", func() {
+
+	parse := func(src string) elements.Element {
+		elem, err := elements.Parse([]byte(src))
+		Ω(err).Should(BeNil(), src)
+		return elem
+	}
+
+	It("should build a transaction map", func() {
+		elem, err := Map().
+			Kw(":db/id").Tagged("db/id", Vec().Kw(":db.part/user")).
+			Kw(":person/name").Str("x").
+			Build()
+		Ω(err).Should(BeNil())
+		Ω(elem.Equals(parse(`{:db/id #db/id [:db.part/user] :person/name "x"}`))).Should(BeTrue())
+	})
+
+	It("should build every kind of child", func() {
+		instant := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+		id, err := uuid.ParseUUID("f81d4fae-7dec-11d0-a765-00a0c91e6bf6")
+		Ω(err).Should(BeNil())
+
+		elem, err := Vec().
+			Kw("a", "b").Sym("my/fn").Str("s").Int(1).BigInt(big.NewInt(2)).Float(2.5).BigDec(big.NewFloat(0.5)).
+			Bool(true).Nil().Char('c').Inst(instant).UUID(id).
+			Val(3).Val(int64(4)).Val(nil).Val(parse(`:x`)).Val(List().Int(5)).Val(Set().Int(6)).
+			Build()
+		Ω(err).Should(BeNil())
+		Ω(elem.Equals(parse(`[:a/b my/fn "s" 1 2N 2.5 0.5M true nil \c #inst "2020-01-02T03:04:05Z" ` +
+			`#uuid "f81d4fae-7dec-11d0-a765-00a0c91e6bf6" 3 4 nil :x (5) #{6}]`))).Should(BeTrue())
+	})
+
+	It("should build empty collections", func() {
+		for src, builder := range map[string]*Builder{`{}`: Map(), `[]`: Vec(), `()`: List(), `#{}`: Set()} {
+			elem, err := builder.BuildCollection()
+			Ω(err).Should(BeNil())
+			Ω(elem.Equals(parse(src))).Should(BeTrue(), src)
+		}
+	})
+
+	It("should keep the first error and ignore the rest", func() {
+		builder := Vec().Int(1).Kw("1bad").Sym("2bad")
+		Ω(errors.Is(builder.Err(), elements.ErrInvalidKeyword)).Should(BeTrue())
+
+		elem, err := builder.Build()
+		Ω(elem).Should(BeNil())
+		Ω(errors.Is(err, elements.ErrInvalidKeyword)).Should(BeTrue())
+
+		var elemErr *elements.ElementError
+		Ω(errors.As(err, &elemErr)).Should(BeTrue())
+		Ω(elemErr.Path).Should(Equal([]interface{}{1}))
+	})
+
+	It("should report the path to errors in nested builders", func() {
+		_, err := Map().Kw(":a").Val(Vec().Int(1).Tagged("1tag", 2)).Build()
+		Ω(errors.Is(err, elements.ErrInvalidTag)).Should(BeTrue())
+
+		var elemErr *elements.ElementError
+		Ω(errors.As(err, &elemErr)).Should(BeTrue())
+		Ω(elemErr.Path).Should(HaveLen(2))
+		Ω(elemErr.Path[0].(elements.Element).Equals(parse(`:a`))).Should(BeTrue())
+		Ω(elemErr.Path[1]).Should(Equal(1))
+		Ω(err.Error()).Should(HavePrefix("at [:a 1]: "))
+	})
+
+	It("should leave the errors of nested builders unchanged", func() {
+		nested := Vec().Int(1).Tagged("1tag", 2)
+		outer := Vec().Val(nested).Val(nested)
+
+		for i := 0; i < 2; i++ {
+			_, err := outer.Build()
+			Ω(err.Error()).Should(HavePrefix("at [0 1]: "))
+		}
+
+		_, err := Map().Kw(":a").Val(nested).Build()
+		Ω(err.Error()).Should(HavePrefix("at [:a 1]: "))
+
+		_, err = nested.Build()
+		Ω(err.Error()).Should(HavePrefix("at [1]: "))
+	})
+
+	It("should reject maps with a missing value or a duplicate key", func() {
+		_, err := Map().Kw(":a").Int(1).Kw(":b").Build()
+		Ω(errors.Is(err, ErrMissingValue)).Should(BeTrue())
+
+		_, err = Map().Kw(":a").Int(1).Kw(":a").Int(2).Build()
+		Ω(errors.Is(err, elements.ErrDuplicateKey)).Should(BeTrue())
+	})
+
+	It("should keep keys of different types apart", func() {
+		elem, err := Map().Str("a").Int(1).Sym("a").Int(2).Int(1).Kw(":x").Str("1").Kw(":y").Build()
+		Ω(err).Should(BeNil())
+		Ω(elem.Equals(parse(`{"a" 1 a 2 1 :x "1" :y}`))).Should(BeTrue())
+		Ω(elem.(elements.CollectionElement).Len()).Should(Equal(4))
+	})
+
+	It("should take an empty string as a string", func() {
+		elem, err := Vec().Val("").Str("").Build()
+		Ω(err).Should(BeNil())
+		Ω(elem.Serialize()).Should(Equal(`["" ""]`))
+	})
+
+	It("should reject values that are not elements", func() {
+		_, err := Vec().Val(struct{}{}).Build()
+		Ω(err).ShouldNot(BeNil())
+	})
+})
@@ -0,0 +1,17 @@
+// Package edn composes EDN declaratively. Builders collect the children of a collection with chained calls, keep the
+// first error they run into and report it when the collection is built, so that a transaction map can be written as
+//
+//	edn.Map().Kw(":db/id").Tagged("db/id", edn.Vec().Kw(":db.part/user")).Kw(":person/name").Str("x").Build()
+//
+// rather than as a chain of constructors each followed by an error check.
+package edn
+
+import (
+	"github.com/martinkreibe-wk/geneva/elements"
+)
+
+const (
+
+	// ErrMissingValue defines the error for a map built with a key but no value.
+	ErrMissingValue = elements.Error("Missing value for the key")
+)
@@ -0,0 +1,268 @@
+package edn
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/martinkreibe-wk/geneva/elements"
+)
+
+const (
+
+	// ParamTag marks a placeholder in a template, e.g. #param name, or #param [name :db.type/long] to require the bound
+	// value to be of that type.
+	ParamTag = "param"
+
+	// ParamPrefix marks a placeholder symbol in a template, e.g. ?name.
+	ParamPrefix = "?"
+
+	// ErrInvalidParam defines the error for a placeholder that is not a name, or a name and a type.
+	ErrInvalidParam = elements.Error("Invalid template parameter")
+
+	// ErrMissingParam defines the error for a placeholder without a bound value.
+	ErrMissingParam = elements.Error("Missing template parameter")
+
+	// ErrUnusedParam defines the error for a bound value without a placeholder.
+	ErrUnusedParam = elements.Error("Unused template parameter")
+
+	// ErrParamType defines the error for a bound value that is not of the type the placeholder requires.
+	ErrParamType = elements.Error("Wrong type for template parameter")
+)
+
+// Placeholders selects the forms of placeholder a template recognises.
+type Placeholders int
+
+const (
+
+	// TagPlaceholders recognises #param name and #param [name type].
+	TagPlaceholders Placeholders = 1 << iota
+
+	// SymbolPlaceholders recognises symbols that start with ?, e.g. ?name. Queries use these symbols as variables, so
+	// leave this out for query templates.
+	SymbolPlaceholders
+
+	// AllPlaceholders recognises both forms.
+	AllPlaceholders = TagPlaceholders | SymbolPlaceholders
+)
+
+// Template is EDN with named placeholders. Binding values to the names builds a new element with the placeholders
+// replaced by elements constructed from the values, so the values never pass through EDN text. The parsed template is
+// frozen, so it can be bound any number of times, from any number of goroutines.
+type Template struct {
+
+	// root of the parsed template.
+	root elements.Element
+
+	// placeholders recognised by this template.
+	placeholders Placeholders
+
+	// params holds the required type of every parameter, UnknownType if any type will do.
+	params map[string]elements.ElementType
+}
+
+// ParseTemplate reads the template source.
+func ParseTemplate(src []byte, placeholders Placeholders) (tmpl *Template, err error) {
+
+	var root elements.Element
+	if root, err = elements.Parse(src); err == nil {
+		tmpl = &Template{
+			root:         elements.Freeze(root),
+			placeholders: placeholders,
+			params:       map[string]elements.ElementType{},
+		}
+
+		err = elements.Walk(root, func(path []elements.Element, elem elements.Element) (e error) {
+			var name string
+			var elemType elements.ElementType
+			var is bool
+			if name, elemType, is, e = tmpl.placeholder(elem); e == nil && is {
+				if existing, has := tmpl.params[name]; has && existing != elemType {
+					e = ErrInvalidParam
+				} else {
+					tmpl.params[name] = elemType
+					e = elements.SkipChildren
+				}
+			}
+
+			if e != nil && e != elements.SkipChildren {
+				e = &elements.ElementError{Path: segments(path), Value: elem, Err: e}
+			}
+
+			return e
+		})
+	}
+
+	if err != nil {
+		tmpl = nil
+	}
+
+	return tmpl, err
+}
+
+// Params returns the names of the parameters, sorted.
+func (tmpl *Template) Params() (names []string) {
+	for name := range tmpl.params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Bind builds the element with every placeholder replaced by its value. Strings are always bound as strings, other
+// values are converted as with Builder.Val. Every parameter must have a value and every value must have a parameter,
+// all such problems are reported together. The element is a copy that shares nothing with the template, the values or
+// other binds, so it can be changed freely.
+func (tmpl *Template) Bind(values map[string]interface{}) (elem elements.Element, err error) {
+
+	bound := map[string]elements.Element{}
+	var errs []error
+	for _, name := range tmpl.Params() {
+		if value, has := values[name]; has {
+			var e error
+			var converted elements.Element
+			if converted, e = bindValue(value); e == nil {
+				if required := tmpl.params[name]; required != elements.UnknownType && converted.ElementType() != required {
+					e = ErrParamType
+				}
+			}
+
+			if e == nil {
+				bound[name] = converted
+			} else {
+				errs = append(errs, &elements.ElementError{Path: []interface{}{name}, Value: value, Err: e})
+			}
+		} else {
+			errs = append(errs, &elements.ElementError{Path: []interface{}{name}, Err: ErrMissingParam})
+		}
+	}
+
+	unused := []string{}
+	for name := range values {
+		if _, has := tmpl.params[name]; !has {
+			unused = append(unused, name)
+		}
+	}
+	sort.Strings(unused)
+	for _, name := range unused {
+		errs = append(errs, &elements.ElementError{Path: []interface{}{name}, Value: values[name], Err: ErrUnusedParam})
+	}
+
+	if err = elements.AppendError(errs...); err == nil {
+
+		// bound values are not walked, so they are never taken for placeholders themselves.
+		elem, err = elements.Prewalk(tmpl.root, func(_ []elements.Element, node elements.Element) (replacement elements.Element, e error) {
+			replacement = node
+			if name, _, is, _ := tmpl.placeholder(node); is {
+				replacement, e = bound[name], elements.SkipChildren
+			}
+
+			return replacement, e
+		})
+	}
+
+	if err == nil {
+		elem = elements.Clone(elem)
+	}
+
+	return elem, err
+}
+
+// bindValue converts a bound value to an element. Strings are taken as strings only, so that a value can never become a
+// keyword, nil or anything else by what it holds.
+func bindValue(value interface{}) (elem elements.Element, err error) {
+	if str, is := value.(string); is {
+		elem, err = elements.NewStringElement(str)
+	} else {
+		elem, err = resolve(value)
+	}
+
+	return elem, err
+}
+
+// placeholder returns the name and required type if the element is a placeholder.
+func (tmpl *Template) placeholder(elem elements.Element) (name string, elemType elements.ElementType, is bool, err error) {
+
+	if tagged, isTagged := elem.(elements.TaggedElement); isTagged && tmpl.placeholders&TagPlaceholders != 0 && tagged.Tag() == ParamTag {
+		is = true
+		switch param := tagged.Tagged(); param.ElementType() {
+		case elements.SymbolType:
+			name, err = paramName(param)
+
+		case elements.VectorType:
+			var kind elements.Element
+			coll := param.(elements.CollectionElement)
+			if coll.Len() != 2 {
+				err = ErrInvalidParam
+			} else if kind, err = coll.Get(1); err == nil && kind.ElementType() == elements.KeywordType && !kind.HasTag() {
+				var first elements.Element
+				if first, err = coll.Get(0); err == nil {
+					if name, err = paramName(first); err == nil {
+						elemType, err = paramType(kind)
+					}
+				}
+			} else {
+				err = ErrInvalidParam
+			}
+
+		default:
+			err = ErrInvalidParam
+		}
+	} else if symbol, isSymbol := elem.(elements.SymbolElement); isSymbol && tmpl.placeholders&SymbolPlaceholders != 0 &&
+		elem.ElementType() == elements.SymbolType && !elem.HasTag() && len(symbol.Prefix()) == 0 &&
+		strings.HasPrefix(symbol.Name(), ParamPrefix) && len(symbol.Name()) > len(ParamPrefix) {
+
+		is, name = true, strings.TrimPrefix(symbol.Name(), ParamPrefix)
+	}
+
+	return name, elemType, is, err
+}
+
+// paramName returns the name of a #param placeholder, which must be an untagged symbol.
+func paramName(elem elements.Element) (name string, err error) {
+	if elem.ElementType() == elements.SymbolType && !elem.HasTag() {
+		name, err = elem.Serialize()
+	} else {
+		err = ErrInvalidParam
+	}
+
+	return name, err
+}
+
+// paramType returns the element type named by the keyword, e.g. :db.type/long.
+func paramType(kind elements.Element) (elemType elements.ElementType, err error) {
+	var text string
+	if text, err = kind.Serialize(); err == nil {
+		if elemType = elements.ElementType(text); !elemType.IsCollection() && !knownTypes[elemType] {
+			err = ErrInvalidParam
+		}
+	}
+
+	return elemType, err
+}
+
+// knownTypes holds the scalar types a placeholder can require.
+var knownTypes = map[elements.ElementType]bool{
+	elements.NilType:       true,
+	elements.BooleanType:   true,
+	elements.StringType:    true,
+	elements.CharacterType: true,
+	elements.SymbolType:    true,
+	elements.KeywordType:   true,
+	elements.IntegerType:   true,
+	elements.BigIntType:    true,
+	elements.FloatType:     true,
+	elements.BigDecType:    true,
+	elements.InstantType:   true,
+	elements.UUIDType:      true,
+	elements.TaggedType:    true,
+}
+
+// segments converts a walk path to the path of an error.
+func segments(path []elements.Element) (out []interface{}) {
+	for _, segment := range path {
+		out = append(out, segment)
+	}
+
+	return out
+}
@@ -0,0 +1,139 @@
+package edn
+
+import (
+	"errors"
+
+	"github.com/martinkreibe-wk/geneva/elements"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EDN templates", func() {
+
+	parse := func(src string) elements.Element {
+		elem, err := elements.Parse([]byte(src))
+		Ω(err).Should(BeNil(), src)
+		return elem
+	}
+
+	It("should substitute tag placeholders", func() {
+		tmpl, err := ParseTemplate([]byte(`{:db/id #param id :person/name #param [name :db.type/string] :person/age #param age}`), TagPlaceholders)
+		Ω(err).Should(BeNil())
+		Ω(tmpl.Params()).Should(Equal([]string{"age", "id", "name"}))
+
+		elem, err := tmpl.Bind(map[string]interface{}{
+			"id":   Vec().Kw(":db.part/user"),
+			"name": `x" :person/admin true`,
+			"age":  42,
+		})
+		Ω(err).Should(BeNil())
+		Ω(elem.Equals(parse(`{:db/id [:db.part/user] :person/name "x\" :person/admin true" :person/age 42}`))).Should(BeTrue())
+	})
+
+	It("should substitute symbol placeholders and leave the template unchanged", func() {
+		tmpl, err := ParseTemplate([]byte(`[?a #{?b} {?a 1}]`), SymbolPlaceholders)
+		Ω(err).Should(BeNil())
+		Ω(tmpl.Params()).Should(Equal([]string{"a", "b"}))
+
+		first, err := tmpl.Bind(map[string]interface{}{"a": parse(":k"), "b": int64(2)})
+		Ω(err).Should(BeNil())
+		Ω(first.Equals(parse(`[:k #{2} {:k 1}]`))).Should(BeTrue())
+
+		second, err := tmpl.Bind(map[string]interface{}{"a": "s", "b": nil})
+		Ω(err).Should(BeNil())
+		Ω(second.Equals(parse(`["s" #{nil} {"s" 1}]`))).Should(BeTrue())
+	})
+
+	It("should bind strings as strings whatever they hold", func() {
+		tmpl, err := ParseTemplate([]byte(`[?a #param [b :db.type/string]]`), AllPlaceholders)
+		Ω(err).Should(BeNil())
+
+		for _, value := range []string{"", ":admin", "nil", "?b", "1"} {
+			elem, err := tmpl.Bind(map[string]interface{}{"a": value, "b": value})
+			Ω(err).Should(BeNil(), value)
+
+			expected, err := elements.NewVector()
+			Ω(err).Should(BeNil())
+			str, err := elements.NewStringElement(value)
+			Ω(err).Should(BeNil())
+			Ω(expected.Append(str, str)).Should(Succeed())
+			Ω(elem.Equals(expected)).Should(BeTrue(), value)
+		}
+	})
+
+	It("should return elements that share nothing with the template or each other", func() {
+		tmpl, err := ParseTemplate([]byte(`[?a [1 2] ?a]`), SymbolPlaceholders)
+		Ω(err).Should(BeNil())
+
+		value := parse(`[3]`)
+		first, err := tmpl.Bind(map[string]interface{}{"a": value})
+		Ω(err).Should(BeNil())
+
+		coll := first.(elements.CollectionElement)
+		for i := 0; i < 3; i++ {
+			child, err := coll.Get(i)
+			Ω(err).Should(BeNil())
+			Ω(child.(elements.CollectionElement).Append(parse(`99`))).Should(Succeed())
+		}
+		Ω(first.Equals(parse(`[[3 99] [1 2 99] [3 99]]`))).Should(BeTrue())
+		Ω(value.Equals(parse(`[3]`))).Should(BeTrue())
+
+		second, err := tmpl.Bind(map[string]interface{}{"a": int64(2)})
+		Ω(err).Should(BeNil())
+		Ω(second.Equals(parse(`[2 [1 2] 2]`))).Should(BeTrue())
+	})
+
+	It("should not treat query variables as placeholders unless asked to", func() {
+		tmpl, err := ParseTemplate([]byte(`[:find ?e :where [?e :person/name #param name]]`), TagPlaceholders)
+		Ω(err).Should(BeNil())
+		Ω(tmpl.Params()).Should(Equal([]string{"name"}))
+
+		elem, err := tmpl.Bind(map[string]interface{}{"name": "x"})
+		Ω(err).Should(BeNil())
+		Ω(elem.Equals(parse(`[:find ?e :where [?e :person/name "x"]]`))).Should(BeTrue())
+	})
+
+	It("should not look for placeholders in bound values", func() {
+		tmpl, err := ParseTemplate([]byte(`[?a ?b]`), AllPlaceholders)
+		Ω(err).Should(BeNil())
+
+		elem, err := tmpl.Bind(map[string]interface{}{"a": parse(`?b`), "b": parse(`#param a`)})
+		Ω(err).Should(BeNil())
+		Ω(elem.Serialize()).Should(BeEquivalentTo(`[?b #param a]`))
+	})
+
+	It("should report every missing, unused and mistyped parameter", func() {
+		tmpl, err := ParseTemplate([]byte(`[#param a #param [b :db.type/long] #param c]`), TagPlaceholders)
+		Ω(err).Should(BeNil())
+
+		_, err = tmpl.Bind(map[string]interface{}{"b": "not a long", "c": 1, "d": 2, "e": 3})
+		Ω(errors.Is(err, ErrMissingParam)).Should(BeTrue())
+		Ω(errors.Is(err, ErrParamType)).Should(BeTrue())
+		Ω(errors.Is(err, ErrUnusedParam)).Should(BeTrue())
+
+		var cumErr *elements.CumulativeError
+		Ω(errors.As(err, &cumErr)).Should(BeTrue())
+		Ω(cumErr.ErrorList()).Should(HaveLen(4))
+	})
+
+	It("should reject invalid placeholders", func() {
+		for _, src := range []string{
+			`#param 1`, `#param :a`, `#param [a]`, `#param [a :db.type/nope]`, `#param [a "b"]`, `[#param [a :db.type/long] #param a]`,
+		} {
+			_, err := ParseTemplate([]byte(src), TagPlaceholders)
+			Ω(errors.Is(err, ErrInvalidParam)).Should(BeTrue(), src)
+		}
+
+		_, err := ParseTemplate([]byte(`[1`), AllPlaceholders)
+		Ω(errors.Is(err, elements.ErrUnexpectedEnd)).Should(BeTrue())
+	})
+
+	It("should accept the same typed placeholder more than once", func() {
+		tmpl, err := ParseTemplate([]byte(`[#param [a :db.type/vector] #param [a :db.type/vector]]`), TagPlaceholders)
+		Ω(err).Should(BeNil())
+
+		elem, err := tmpl.Bind(map[string]interface{}{"a": Vec().Int(1)})
+		Ω(err).Should(BeNil())
+		Ω(elem.Equals(parse(`[[1] [1]]`))).Should(BeTrue())
+	})
+})
@@ -0,0 +1,159 @@
+// Package ednlines reads and writes EDN-lines, streams with one element per line as used for logs and exports. Lines
+// are decoded and encoded by a pool of workers, and handed on in the order of the stream. Only a window of lines is in
+// flight at a time, so a slow consumer holds the whole pipeline back rather than letting it fill memory. A line that
+// fails is reported with its line number and the stream goes on past it.
+package ednlines
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+
+	"github.com/martinkreibe-wk/geneva/elements"
+)
+
+const (
+
+	// ErrMultipleLines defines the error for an element that serializes to more than one line.
+	ErrMultipleLines = elements.Error("Element spans several lines")
+
+	// windowPerWorker is the number of lines in flight for each worker when no window is set.
+	windowPerWorker = 4
+)
+
+// LineError defines the failure of a single line, the stream goes on past it.
+type LineError struct {
+
+	// Line is the 1 based number of the line.
+	Line int
+
+	// Err is the reason.
+	Err error
+}
+
+// Error returns the error message.
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+// Unwrap returns the reason.
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// job is a single line on its way through the pool. Reading turns src into elem, writing turns elem into src.
+type job struct {
+	line int
+	src  []byte
+	elem elements.Element
+	err  error
+	done chan struct{}
+}
+
+// pool runs jobs on a number of workers and hands them on in the order they were produced.
+type pool struct {
+
+	// workers is the number of goroutines doing the work.
+	workers int
+
+	// window is the number of jobs in flight, produced but not yet consumed.
+	window int
+}
+
+// newPool creates a pool with a worker for each CPU.
+func newPool() pool {
+	workers := runtime.GOMAXPROCS(0)
+	return pool{
+		workers: workers,
+		window:  workers * windowPerWorker,
+	}
+}
+
+// setWorkers sets the number of workers, the window grows with them unless it was set apart.
+func (p *pool) setWorkers(workers int) {
+	if workers > 0 {
+		if p.window == p.workers*windowPerWorker {
+			p.window = workers * windowPerWorker
+		}
+		p.workers = workers
+	}
+}
+
+// setWindow sets the number of jobs in flight.
+func (p *pool) setWindow(window int) {
+	if window > 0 {
+		p.window = window
+	}
+}
+
+// run passes every job from produce through work on the workers and then to consume, in the order they were produced.
+// produce sends its jobs with the send function it is given, which is false once the run is over, and the context it is
+// given is done then too. The run is over when produce returns, consume returns false or the context is done. A produce
+// that is blocked, such as on a read, is not waited for, it stops when its next send fails.
+func (p pool) run(parent context.Context, produce func(ctx context.Context, send func(*job) bool) error,
+	work func(*job), consume func(*job) bool) (err error) {
+
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	queue := make(chan *job, p.window)
+	jobs := make(chan *job)
+	for i := 0; i < p.workers; i++ {
+		go func() {
+			for j := range jobs {
+				work(j)
+				close(j.done)
+			}
+		}()
+	}
+
+	produced := make(chan error, 1)
+	go func() {
+		defer close(jobs)
+		defer close(queue)
+
+		produced <- produce(ctx, func(j *job) (sent bool) {
+			j.done = make(chan struct{})
+			select {
+			case queue <- j:
+				select {
+				case jobs <- j:
+					sent = true
+				case <-ctx.Done():
+				}
+			case <-ctx.Done():
+			}
+
+			return sent
+		})
+	}()
+
+	for open := true; open; {
+		var j *job
+		select {
+		case j, open = <-queue:
+		case <-ctx.Done():
+			open = false
+		}
+
+		if open {
+			select {
+			case <-j.done:
+				open = consume(j)
+			case <-ctx.Done():
+				open = false
+			}
+		}
+	}
+
+	select {
+	case err = <-produced:
+	default:
+	}
+
+	if err == nil {
+		err = parent.Err()
+	}
+
+	return err
+}
@@ -0,0 +1,163 @@
+package ednlines
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/martinkreibe-wk/geneva/elements"
+)
+
+// Result holds the element read from a line, or the LineError that kept it from being read.
+type Result struct {
+
+	// Line is the 1 based number of the line.
+	Line int
+
+	// Element read from the line. Its span is within the line.
+	Element elements.Element
+
+	// Err is the LineError for the line.
+	Err error
+}
+
+// Reader reads EDN-lines, decoding the lines on a pool of workers. Lines that hold only whitespace or comments are
+// skipped, any other line must hold exactly one element.
+type Reader struct {
+	in       *bufio.Reader
+	pool     pool
+	registry *elements.Registry
+	limits   elements.Limits
+	err      error
+}
+
+// NewReader creates a reader over the input, with a worker for each CPU, using the tag handlers of the default
+// registry and the default limits.
+func NewReader(in io.Reader) *Reader {
+	return &Reader{
+		in:       bufio.NewReader(in),
+		pool:     newPool(),
+		registry: elements.DefaultRegistry(),
+	}
+}
+
+// SetWorkers sets the number of lines decoded at the same time.
+func (r *Reader) SetWorkers(workers int) {
+	r.pool.setWorkers(workers)
+}
+
+// SetWindow sets the number of lines read ahead of the results that have been received.
+func (r *Reader) SetWindow(window int) {
+	r.pool.setWindow(window)
+}
+
+// SetRegistry sets the registry whose tag handlers are used.
+func (r *Reader) SetRegistry(registry *elements.Registry) {
+	r.registry = registry
+}
+
+// SetLimits sets the limits on what is read. They apply to each line, so MaxInputSize bounds the length of a line.
+func (r *Reader) SetLimits(limits elements.Limits) {
+	r.limits = limits
+}
+
+// Read starts reading and returns the channel the results are sent on, in the order of the lines. The channel is
+// closed at the end of the input, when reading the input fails or when the context is done, Err tells which. The
+// channel must be received from until it is closed, or the context cancelled, for the reader to finish.
+func (r *Reader) Read(ctx context.Context) <-chan Result {
+	results := make(chan Result)
+
+	go func() {
+		defer close(results)
+
+		r.err = r.pool.run(ctx, r.produce, r.decode, func(j *job) (open bool) {
+			if j.elem != nil || j.err != nil {
+				select {
+				case results <- Result{Line: j.line, Element: j.elem, Err: j.err}:
+					open = true
+				case <-ctx.Done():
+				}
+			} else {
+				open = true
+			}
+
+			return open
+		})
+	}()
+
+	return results
+}
+
+// Err returns the error that ended reading, nil at the end of the input. It is only set once the channel returned by
+// Read is closed.
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// produce splits the input into lines.
+func (r *Reader) produce(_ context.Context, send func(*job) bool) (err error) {
+	limit := r.limits
+	if limit.MaxInputSize == 0 {
+		limit = elements.DefaultLimits()
+	}
+
+	line := 0
+	for sent := true; sent && err == nil; {
+		var src []byte
+		if src, err = r.readLine(limit.MaxInputSize); len(src) > 0 {
+			line++
+			sent = send(&job{line: line, src: src})
+		}
+	}
+
+	if err == io.EOF {
+		err = nil
+	}
+
+	return err
+}
+
+// readLine returns the next line. A line longer than the limit is cut to one byte past it, which the decoder fails on,
+// and the rest of it is dropped.
+func (r *Reader) readLine(limit int) (src []byte, err error) {
+	for more := true; more && err == nil; {
+		var chunk []byte
+		chunk, err = r.in.ReadSlice('\n')
+		more = err == bufio.ErrBufferFull
+		if more {
+			err = nil
+		}
+
+		if room := limit + 1 - len(src); limit < 0 || room >= len(chunk) {
+			src = append(src, chunk...)
+		} else if room > 0 {
+			src = append(src, chunk[:room]...)
+		}
+	}
+
+	return src, err
+}
+
+// decode reads the element of the line.
+func (r *Reader) decode(j *job) {
+	dec := r.registry.NewDecoder(bytes.NewReader(j.src))
+	dec.SetLimits(r.limits)
+
+	var err error
+	if j.elem, err = dec.Decode(); err == nil {
+		if _, err = dec.Decode(); err == io.EOF {
+			err = nil
+		} else if err == nil {
+			j.elem, err = nil, elements.ErrTrailingInput
+		} else {
+			j.elem = nil
+		}
+	} else if err == io.EOF {
+		err = nil
+	}
+
+	if err != nil {
+		j.err = &LineError{Line: j.line, Err: err}
+	}
+}
@@ -0,0 +1,111 @@
+package ednlines
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/martinkreibe-wk/geneva/elements"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// endlessLines is an input of long string lines that never ends, counting the lines handed out.
+type endlessLines struct {
+	lines int64
+	rest  string
+}
+
+// Read hands out the next part of the input.
+func (e *endlessLines) Read(p []byte) (n int, err error) {
+	if len(e.rest) == 0 {
+		atomic.AddInt64(&e.lines, 1)
+		e.rest = `"` + strings.Repeat("x", 5000) + "\"\n"
+	}
+	n = copy(p, e.rest)
+	e.rest = e.rest[n:]
+	return n, err
+}
+
+var _ = Describe("Reading EDN-lines", func() {
+
+	collect := func(rd *Reader) (results []Result) {
+		for result := range rd.Read(context.Background()) {
+			results = append(results, result)
+		}
+		return results
+	}
+
+	It("should read the lines in order", func() {
+		var src strings.Builder
+		for i := 0; i < 1000; i++ {
+			src.WriteString("[" + strconv.Itoa(i) + " :x]\n")
+		}
+
+		rd := NewReader(strings.NewReader(src.String()))
+		rd.SetWorkers(8)
+		results := collect(rd)
+		Ω(rd.Err()).Should(BeNil())
+		Ω(results).Should(HaveLen(1000))
+
+		for i, result := range results {
+			Ω(result.Err).Should(BeNil())
+			Ω(result.Line).Should(Equal(i + 1))
+			Ω(result.Element.Serialize()).Should(Equal("[" + strconv.Itoa(i) + " :x]"))
+		}
+	})
+
+	It("should report the lines that fail and go on", func() {
+		rd := NewReader(strings.NewReader("1\n\n[2\n ;c\n3 4\n#inst \"x\"\r\n" + strings.Repeat("5", 20) + "\n6"))
+		rd.SetLimits(elements.Limits{MaxInputSize: 12})
+		results := collect(rd)
+		Ω(rd.Err()).Should(BeNil())
+		Ω(results).Should(HaveLen(6))
+
+		Ω(results[0].Line).Should(Equal(1))
+		Ω(results[0].Element.Value()).Should(BeEquivalentTo(1))
+
+		for i, failure := range []struct {
+			line   int
+			reason error
+		}{
+			{3, elements.ErrUnexpectedEnd},
+			{5, elements.ErrTrailingInput},
+			{6, elements.ErrInvalidTag},
+			{7, elements.ErrInputLimit},
+		} {
+			result := results[i+1]
+			Ω(result.Line).Should(Equal(failure.line))
+			Ω(result.Element).Should(BeNil())
+			Ω(errors.Is(result.Err, failure.reason)).Should(BeTrue(), result.Err.Error())
+
+			var lineErr *LineError
+			Ω(errors.As(result.Err, &lineErr)).Should(BeTrue())
+			Ω(lineErr.Line).Should(Equal(failure.line))
+		}
+
+		Ω(results[5].Line).Should(Equal(8))
+		Ω(results[5].Element.Value()).Should(BeEquivalentTo(6))
+	})
+
+	It("should hold back reading while results are not received", func() {
+		in := &endlessLines{}
+		rd := NewReader(in)
+		rd.SetWorkers(2)
+		rd.SetWindow(2)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		results := rd.Read(ctx)
+		Ω((<-results).Err).Should(BeNil())
+
+		time.Sleep(50 * time.Millisecond)
+		Ω(atomic.LoadInt64(&in.lines)).Should(BeNumerically("<", 10))
+
+		cancel()
+		Eventually(results).Should(BeClosed())
+		Ω(rd.Err()).Should(Equal(context.Canceled))
+	})
+})
@@ -0,0 +1,114 @@
+package ednlines
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/martinkreibe-wk/geneva/elements"
+)
+
+// Writer writes EDN-lines, serializing the elements on a pool of workers.
+type Writer struct {
+	out  *bufio.Writer
+	pool pool
+	err  error
+}
+
+// NewWriter creates a writer to the output, with a worker for each CPU.
+func NewWriter(out io.Writer) *Writer {
+	return &Writer{
+		out:  bufio.NewWriter(out),
+		pool: newPool(),
+	}
+}
+
+// SetWorkers sets the number of elements serialized at the same time.
+func (w *Writer) SetWorkers(workers int) {
+	w.pool.setWorkers(workers)
+}
+
+// SetWindow sets the number of elements taken from the channel ahead of the ones written.
+func (w *Writer) SetWindow(window int) {
+	w.pool.setWindow(window)
+}
+
+// Write starts writing the elements received from the channel, one per line in the order they are received, and
+// returns the channel the errors are sent on. An element that cannot be serialized is left out and reported as a
+// LineError, where the line is the 1 based number of the element in the stream. The error channel is closed once the
+// elements channel is closed and everything is written, when writing the output fails or when the context is done, Err
+// tells which. The error channel must be received from until it is closed, or the context cancelled, for the writer to
+// finish.
+func (w *Writer) Write(ctx context.Context, elems <-chan elements.Element) <-chan error {
+	errs := make(chan error)
+
+	go func() {
+		defer close(errs)
+
+		var err, writeErr error
+		err = w.pool.run(ctx, func(running context.Context, send func(*job) bool) error {
+			line := 0
+			for open, sent := true, true; open && sent; {
+				var elem elements.Element
+				select {
+				case elem, open = <-elems:
+				case <-running.Done():
+					open = false
+				}
+
+				if open {
+					line++
+					sent = send(&job{line: line, elem: elem})
+				}
+			}
+
+			return nil
+		}, encode, func(j *job) (open bool) {
+			if j.err == nil {
+				if _, writeErr = w.out.Write(j.src); writeErr == nil {
+					open = true
+				}
+			} else {
+				select {
+				case errs <- j.err:
+					open = true
+				case <-ctx.Done():
+				}
+			}
+
+			return open
+		})
+
+		if writeErr != nil {
+			err = writeErr
+		} else if flushErr := w.out.Flush(); err == nil {
+			err = flushErr
+		}
+		w.err = err
+	}()
+
+	return errs
+}
+
+// Err returns the error that ended writing, nil once every element has been written. It is only set once the channel
+// returned by Write is closed.
+func (w *Writer) Err() error {
+	return w.err
+}
+
+// encode serializes the element of the job into its line.
+func encode(j *job) {
+	var err error
+	if j.elem == nil {
+		err = elements.ErrInvalidElement
+	} else if j.src, err = j.elem.AppendEDN(nil); err == nil && bytes.ContainsAny(j.src, "\r\n") {
+		err = ErrMultipleLines
+	}
+
+	if err == nil {
+		j.src = append(j.src, '\n')
+	} else {
+		j.err = &LineError{Line: j.line, Err: err}
+	}
+}
@@ -0,0 +1,98 @@
+package ednlines
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/martinkreibe-wk/geneva/elements"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+// Write fails.
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+var _ = Describe("Writing EDN-lines", func() {
+
+	send := func(elems ...elements.Element) <-chan elements.Element {
+		ch := make(chan elements.Element)
+		go func() {
+			defer close(ch)
+			for _, elem := range elems {
+				ch <- elem
+			}
+		}()
+		return ch
+	}
+
+	It("should write the elements in order and read them back", func() {
+		var elems []elements.Element
+		var expected strings.Builder
+		for i := 0; i < 1000; i++ {
+			elem, err := elements.MarshalElement([]interface{}{int64(i), "a\nb"})
+			Ω(err).Should(BeNil())
+			elems = append(elems, elem)
+			expected.WriteString("[" + strconv.Itoa(i) + " \"a\\nb\"]\n")
+		}
+
+		out := &bytes.Buffer{}
+		wr := NewWriter(out)
+		wr.SetWorkers(8)
+		for err := range wr.Write(context.Background(), send(elems...)) {
+			Fail(err.Error())
+		}
+		Ω(wr.Err()).Should(BeNil())
+		Ω(out.String()).Should(Equal(expected.String()))
+
+		rd := NewReader(out)
+		i := 0
+		for result := range rd.Read(context.Background()) {
+			Ω(result.Err).Should(BeNil())
+			Ω(result.Element.Equals(elems[i])).Should(BeTrue())
+			i++
+		}
+		Ω(i).Should(Equal(1000))
+	})
+
+	It("should leave out the elements that cannot be written", func() {
+		one, err := elements.NewIntegerElement(1)
+		Ω(err).Should(BeNil())
+
+		out := &bytes.Buffer{}
+		wr := NewWriter(out)
+
+		var errs []error
+		for err := range wr.Write(context.Background(), send(one, nil, one)) {
+			errs = append(errs, err)
+		}
+		Ω(wr.Err()).Should(BeNil())
+		Ω(out.String()).Should(Equal("1\n1\n"))
+
+		Ω(errs).Should(HaveLen(1))
+		Ω(errors.Is(errs[0], elements.ErrInvalidElement)).Should(BeTrue())
+		Ω(errs[0].(*LineError).Line).Should(Equal(2))
+	})
+
+	It("should stop when the output fails", func() {
+		elems := make(chan elements.Element, 10000)
+		for i := int64(0); i < 10000; i++ {
+			elem, err := elements.NewIntegerElement(i)
+			Ω(err).Should(BeNil())
+			elems <- elem
+		}
+		close(elems)
+
+		wr := NewWriter(failingWriter{})
+		for range wr.Write(context.Background(), elems) {
+		}
+		Ω(wr.Err()).Should(MatchError("disk full"))
+	})
+})
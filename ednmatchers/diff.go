@@ -0,0 +1,154 @@
+package ednmatchers
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/martinkreibe-wk/geneva/elements"
+)
+
+// difference describes the first place the actual element differs from the expected one, or is empty if they are
+// equivalent. Collections of the same type and tag are compared child by child, so the description points at the
+// deepest element that differs.
+func difference(path []interface{}, actual, expected elements.Element) (diff string) {
+
+	if !actual.Equals(expected) {
+		actualColl, isColl := actual.(elements.CollectionElement)
+		expectedColl, _ := expected.(elements.CollectionElement)
+		actualTagged, isTagged := actual.(elements.TaggedElement)
+		expectedTagged, _ := expected.(elements.TaggedElement)
+
+		switch {
+		case actual.ElementType() != expected.ElementType() || actual.Tag() != expected.Tag():
+		case isTagged:
+			diff = difference(path, actualTagged.Tagged(), expectedTagged.Tagged())
+		case isColl && actual.ElementType() == elements.MapType:
+			diff = mapDifference(path, actualColl, expectedColl)
+		case isColl && actual.ElementType() == elements.SetType:
+			diff = setDifference(path, actualColl, expectedColl)
+		case isColl:
+			diff = sequenceDifference(path, actualColl, expectedColl)
+		}
+
+		if len(diff) == 0 {
+			diff = fmt.Sprintf("%s: found %s, expected %s", at(path), text(actual), text(expected))
+		}
+	}
+
+	return diff
+}
+
+// mapDifference compares the maps entry by entry, in the order of their keys.
+func mapDifference(path []interface{}, actual, expected elements.CollectionElement) (diff string) {
+
+	for _, key := range sortedKeys(expected) {
+		if len(diff) == 0 {
+			expectedValue, _ := valueOf(expected, key)
+			if actualValue, has := valueOf(actual, key); !has {
+				diff = fmt.Sprintf("%s: missing key %s", at(path), text(key))
+			} else {
+				diff = difference(append(path[:len(path):len(path)], key), actualValue, expectedValue)
+			}
+		}
+	}
+
+	for _, key := range sortedKeys(actual) {
+		if _, has := valueOf(expected, key); !has && len(diff) == 0 {
+			diff = fmt.Sprintf("%s: unexpected key %s", at(path), text(key))
+		}
+	}
+
+	return diff
+}
+
+// setDifference reports the first member missing from the actual set, or else the first one it should not hold.
+func setDifference(path []interface{}, actual, expected elements.CollectionElement) (diff string) {
+
+	missing := members(expected, actual)
+	unexpected := members(actual, expected)
+	if len(missing) > 0 {
+		diff = fmt.Sprintf("%s: missing member %s", at(path), missing[0])
+	} else if len(unexpected) > 0 {
+		diff = fmt.Sprintf("%s: unexpected member %s", at(path), unexpected[0])
+	}
+
+	return diff
+}
+
+// sequenceDifference compares vectors and lists element by element, then by length.
+func sequenceDifference(path []interface{}, actual, expected elements.CollectionElement) (diff string) {
+
+	for i := 0; i < actual.Len() && i < expected.Len() && len(diff) == 0; i++ {
+		actualChild, _ := actual.Get(i)
+		expectedChild, _ := expected.Get(i)
+		diff = difference(append(path[:len(path):len(path)], i), actualChild, expectedChild)
+	}
+
+	if len(diff) == 0 && actual.Len() != expected.Len() {
+		diff = fmt.Sprintf("%s: found %d elements, expected %d", at(path), actual.Len(), expected.Len())
+	}
+
+	return diff
+}
+
+// valueOf returns the value of the map under the key equal to the key, like members matches the members of sets.
+func valueOf(coll elements.CollectionElement, key elements.Element) (value elements.Element, has bool) {
+	_ = coll.IterateChildren(func(candidate elements.Element, child elements.Element) (e error) {
+		if has = key.Equals(candidate); has {
+			value, e = child, elements.StopWalk
+		}
+		return e
+	})
+
+	return value, has
+}
+
+// sortedKeys returns the keys of the map, sorted by their EDN with sets and maps sorted within.
+func sortedKeys(coll elements.CollectionElement) (keys []elements.Element) {
+	_ = coll.IterateChildren(func(key elements.Element, _ elements.Element) error {
+		keys = append(keys, key)
+		return nil
+	})
+
+	sorted := make(map[elements.Element]string, len(keys))
+	for _, key := range keys {
+		sorted[key], _ = elements.SerializePretty(key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return sorted[keys[i]] < sorted[keys[j]]
+	})
+
+	return keys
+}
+
+// members returns the EDN of the members of the set that the other set does not hold, sorted.
+func members(set, other elements.CollectionElement) (found []string) {
+	_ = set.IterateChildren(func(_ elements.Element, member elements.Element) error {
+		held := false
+		_ = other.IterateChildren(func(_ elements.Element, candidate elements.Element) (e error) {
+			if held = member.Equals(candidate); held {
+				e = elements.StopWalk
+			}
+			return e
+		})
+
+		if !held {
+			found = append(found, text(member))
+		}
+
+		return nil
+	})
+
+	sort.Strings(found)
+	return found
+}
+
+// at describes where in the element a difference is.
+func at(path []interface{}) (where string) {
+	if where = "at the top"; len(path) > 0 {
+		where = "at " + formatPath(path)
+	}
+
+	return where
+}
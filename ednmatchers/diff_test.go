@@ -0,0 +1,56 @@
+package ednmatchers
+
+import (
+	"github.com/martinkreibe-wk/geneva/elements"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EDN differences", func() {
+
+	It("should point at the first place the elements differ", func() {
+		for _, test := range []struct {
+			actual, expected, diff string
+		}{
+			{`{:a 1}`, `{:a 1}`, ``},
+			{`1`, `2`, `at the top: found 1, expected 2`},
+			{`1`, `1.0`, `at the top: found 1, expected 1E+00`},
+			{`{:a 1}`, `{:a 1 :b 2}`, `at the top: missing key :b`},
+			{`{:a 1 :b 2}`, `{:a 1}`, `at the top: unexpected key :b`},
+			{`{:a [1 {:b #{1}}]}`, `{:a [1 {:b #{2}}]}`, `at [:a 1 :b]: missing member 2`},
+			{`#{1 2}`, `#{1}`, `at the top: unexpected member 2`},
+			{`[1 2 3]`, `[1 2]`, `at the top: found 3 elements, expected 2`},
+			{`(1 2)`, `[1 2]`, `at the top: found (1 2), expected [1 2]`},
+			{`#a [1 2]`, `#b [1 2]`, `at the top: found #a [1 2], expected #b [1 2]`},
+			{`#a [1 2]`, `#a [1 3]`, `at [1]: found 2, expected 3`},
+			{`{"k" {1 :x}}`, `{"k" {1 :y}}`, `at ["k" 1]: found :x, expected :y`},
+		} {
+			actual, err := elements.Parse([]byte(test.actual))
+			Ω(err).Should(BeNil())
+			expected, err := elements.Parse([]byte(test.expected))
+			Ω(err).Should(BeNil())
+
+			Ω(difference(nil, actual, expected)).Should(Equal(test.diff), test.actual)
+		}
+	})
+
+	It("should match keys that are sets or maps whatever their order", func() {
+		for i := 0; i < 20; i++ {
+			for _, test := range []struct {
+				actual, expected, diff string
+			}{
+				{`{#{1 2 3 4 5 6} 1}`, `{#{6 5 4 3 2 1} 1}`, `^$`},
+				{`{{:a 1 :b 2 :c 3 :d 4} 1}`, `{{:d 4 :c 3 :b 2 :a 1} 1}`, `^$`},
+				{`{#{1 2 3 4 5 6} 1}`, `{#{6 5 4 3 2 1} 2}`, `^at \[#\{[1-6 ]+\}\]: found 1, expected 2$`},
+				{`{#{1 2} 1}`, `{#{1 3} 1}`, `^at the top: missing key #\{(1 3|3 1)\}$`},
+			} {
+				actual, err := elements.Parse([]byte(test.actual))
+				Ω(err).Should(BeNil())
+				expected, err := elements.Parse([]byte(test.expected))
+				Ω(err).Should(BeNil())
+
+				Ω(difference(nil, actual, expected)).Should(MatchRegexp(test.diff), test.actual)
+			}
+		}
+	})
+})
@@ -0,0 +1,93 @@
+// Package ednmatchers provides gomega matchers for EDN. The matchers read EDN from strings and byte slices, and
+// serialize anything else that can be, such as elements and datoms, to read it back. Comparisons are by
+// meaning rather than by text, so whitespace, commas and the order of map entries and set members do not matter, and
+// failures print both sides sorted and spread over lines with the first place they differ.
+//
+//	Ω(out).Should(BeEquivalentEDN(`{:db/id 1 :db/ident :person/name}`))
+//	Ω(out).Should(HaveEDNKey(":db/id"))
+//	Ω(out).Should(MatchEDNPath([]interface{}{":person/friends", 0}, BeEquivalentEDN(`{:person/name "x"}`)))
+package ednmatchers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/martinkreibe-wk/geneva/elements"
+	"github.com/onsi/gomega/format"
+)
+
+// ErrNotEDN defines the error for a value the matchers can not read as EDN.
+const ErrNotEDN = elements.Error("Value is not EDN")
+
+// toElement reads the value as EDN. Strings and byte slices are parsed, and any serializer is serialized and read back.
+// Elements are read back too, so that built elements compare the way the same text read by the parser does, e.g. a
+// vector given a tag with SetTag and the vector read under that tag.
+func toElement(value interface{}) (elem elements.Element, err error) {
+	switch v := value.(type) {
+	case string:
+		elem, err = elements.Parse([]byte(v))
+	case []byte:
+		elem, err = elements.Parse(v)
+	case elements.Serializer:
+		var text string
+		if text, err = v.Serialize(); err == nil {
+			elem, err = elements.Parse([]byte(text))
+		}
+	default:
+		err = elements.NewError("%w: %T", ErrNotEDN, value)
+	}
+
+	if elem == nil && err == nil {
+		err = elements.NewError("%w: %T", ErrNotEDN, value)
+	}
+
+	return elem, err
+}
+
+// describe writes the element for a failure message, sorted and spread over lines by elements.SerializePretty and
+// indented to line up with the gomega messages.
+func describe(elem elements.Element) string {
+	text, err := elements.SerializePretty(elem)
+	if err != nil {
+		text = err.Error()
+	}
+
+	return format.IndentString(text, 1)
+}
+
+// text writes the element on a single line for a failure message.
+func text(elem elements.Element) (out string) {
+	var err error
+	if out, err = elem.Serialize(); err != nil {
+		out = err.Error()
+	}
+
+	return out
+}
+
+// formatPath writes the path as an EDN vector.
+func formatPath(path []interface{}) string {
+	parts := make([]string, len(path))
+	for i, segment := range path {
+		parts[i] = formatSegment(segment)
+	}
+
+	return elements.VectorStartLiteral + strings.Join(parts, elements.VectorSeparatorLiteral) + elements.VectorEndLiteral
+}
+
+// formatSegment writes a path segment in EDN, reading it the way elements.GetIn does.
+func formatSegment(segment interface{}) (out string) {
+	switch v := segment.(type) {
+	case elements.Element:
+		out = text(v)
+	case string:
+		if out = v; !strings.HasPrefix(v, elements.KeywordPrefix) {
+			out = strconv.Quote(v)
+		}
+	default:
+		out = fmt.Sprint(v)
+	}
+
+	return out
+}
@@ -0,0 +1,167 @@
+package ednmatchers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/martinkreibe-wk/geneva/elements"
+	"github.com/onsi/gomega/format"
+	"github.com/onsi/gomega/types"
+)
+
+// BeEquivalentEDN succeeds when the actual value is the same EDN as the expected one, regardless of whitespace, commas
+// and the order of map entries and set members. Both sides can be a string, a byte slice, an element or a serializer.
+//
+//	Ω(attr.Serialize()).Should(BeEquivalentEDN(`{:db/ident :person/name :db/cardinality :db.cardinality/one}`))
+func BeEquivalentEDN(expected interface{}) types.GomegaMatcher {
+	return &equivalentMatcher{
+		expected: expected,
+	}
+}
+
+// HaveEDNKey succeeds when the actual map holds the key. The key is read the way elements.GetIn reads path segments, so
+// a string starting with : is a keyword and an integer indexes a vector or list. Sets hold the keys of their members.
+//
+//	Ω(attr).Should(HaveEDNKey(":db/id"))
+func HaveEDNKey(key interface{}) types.GomegaMatcher {
+	return &keyMatcher{
+		key: key,
+	}
+}
+
+// MatchEDNPath succeeds when the element at the end of the path matches. The path is followed like elements.GetIn and
+// fails the match if it cannot be. The matcher is given the element, a value that is not a matcher is compared with
+// BeEquivalentEDN.
+//
+//	Ω(schema).Should(MatchEDNPath([]interface{}{":attributes", 0, ":db/ident"}, ":person/name"))
+func MatchEDNPath(path []interface{}, matcher interface{}) types.GomegaMatcher {
+	m, is := matcher.(types.GomegaMatcher)
+	if !is {
+		m = BeEquivalentEDN(matcher)
+	}
+
+	return &pathMatcher{
+		path:    path,
+		matcher: m,
+	}
+}
+
+// equivalentMatcher implements BeEquivalentEDN.
+type equivalentMatcher struct {
+
+	// expected value as it was given.
+	expected interface{}
+
+	// actualElem and expectedElem are the two sides as read by the last match.
+	actualElem, expectedElem elements.Element
+
+	// diff describes the first difference found by the last match.
+	diff string
+}
+
+// Match reads both sides and compares them.
+func (m *equivalentMatcher) Match(actual interface{}) (success bool, err error) {
+	if m.expectedElem, err = toElement(m.expected); err != nil {
+		err = elements.NewError("BeEquivalentEDN expected: %w", err)
+	} else if m.actualElem, err = toElement(actual); err == nil {
+		m.diff = difference(nil, m.actualElem, m.expectedElem)
+		success = len(m.diff) == 0
+	}
+
+	return success, err
+}
+
+// FailureMessage shows both sides and where they differ.
+func (m *equivalentMatcher) FailureMessage(interface{}) string {
+	return fmt.Sprintf("Expected\n%s\nto be equivalent EDN to\n%s\n%s", describe(m.actualElem), describe(m.expectedElem),
+		format.IndentString(m.diff, 1))
+}
+
+// NegatedFailureMessage shows both sides.
+func (m *equivalentMatcher) NegatedFailureMessage(interface{}) string {
+	return fmt.Sprintf("Expected\n%s\nnot to be equivalent EDN to\n%s", describe(m.actualElem), describe(m.expectedElem))
+}
+
+// keyMatcher implements HaveEDNKey.
+type keyMatcher struct {
+
+	// key looked for.
+	key interface{}
+
+	// actualElem is the actual value as read by the last match.
+	actualElem elements.Element
+}
+
+// Match looks the key up in the actual collection. A key that does not fit the collection, or an actual value that is
+// not one, is an error.
+func (m *keyMatcher) Match(actual interface{}) (success bool, err error) {
+	if m.actualElem, err = toElement(actual); err == nil {
+		if _, err = elements.GetIn(m.actualElem, m.key); err == nil {
+			success = true
+		} else if errors.Is(err, elements.ErrNoValue) || errors.Is(err, elements.ErrIndexOutOfRange) {
+			err = nil
+		} else {
+			err = elements.NewError("HaveEDNKey: %w", err)
+		}
+	}
+
+	return success, err
+}
+
+// FailureMessage shows the collection and the key.
+func (m *keyMatcher) FailureMessage(interface{}) string {
+	return fmt.Sprintf("Expected\n%s\nto have EDN key\n%s", describe(m.actualElem), format.IndentString(formatSegment(m.key), 1))
+}
+
+// NegatedFailureMessage shows the collection and the key.
+func (m *keyMatcher) NegatedFailureMessage(interface{}) string {
+	return fmt.Sprintf("Expected\n%s\nnot to have EDN key\n%s", describe(m.actualElem),
+		format.IndentString(formatSegment(m.key), 1))
+}
+
+// pathMatcher implements MatchEDNPath.
+type pathMatcher struct {
+
+	// path followed.
+	path []interface{}
+
+	// matcher for the element at the end of the path.
+	matcher types.GomegaMatcher
+
+	// actualElem is the actual value as read by the last match.
+	actualElem elements.Element
+
+	// found is the element at the end of the path, nil if the path could not be followed.
+	found elements.Element
+
+	// failure is why the path could not be followed.
+	failure error
+}
+
+// Match follows the path and matches the element found.
+func (m *pathMatcher) Match(actual interface{}) (success bool, err error) {
+	if m.actualElem, err = toElement(actual); err == nil {
+		if m.found, m.failure = elements.GetIn(m.actualElem, m.path...); m.failure == nil {
+			success, err = m.matcher.Match(m.found)
+		}
+	}
+
+	return success, err
+}
+
+// FailureMessage shows why the path could not be followed, or the failure of the matcher.
+func (m *pathMatcher) FailureMessage(interface{}) (message string) {
+	if m.failure != nil {
+		message = fmt.Sprintf("Expected\n%s\nto have EDN path %s\n%s", describe(m.actualElem), formatPath(m.path),
+			format.IndentString(m.failure.Error(), 1))
+	} else {
+		message = fmt.Sprintf("At EDN path %s\n%s", formatPath(m.path), m.matcher.FailureMessage(m.found))
+	}
+
+	return message
+}
+
+// NegatedFailureMessage shows the negated failure of the matcher.
+func (m *pathMatcher) NegatedFailureMessage(interface{}) string {
+	return fmt.Sprintf("At EDN path %s\n%s", formatPath(m.path), m.matcher.NegatedFailureMessage(m.found))
+}
@@ -0,0 +1,126 @@
+package ednmatchers
+
+import (
+	"errors"
+
+	"github.com/martinkreibe-wk/geneva/elements"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EDN matchers", func() {
+
+	Context("BeEquivalentEDN", func() {
+
+		It("should ignore whitespace, commas and the order of maps and sets", func() {
+			Ω(`{:a 1, :b [1 2] :c #{1 2}}`).Should(BeEquivalentEDN("{:c #{2 1}\n :b [1  2]\n :a 1}"))
+			Ω([]byte(`(1 2)`)).Should(BeEquivalentEDN(`( 1,2 )`))
+			Ω(`[1 2]`).ShouldNot(BeEquivalentEDN(`[2 1]`))
+			Ω(`[1 2]`).ShouldNot(BeEquivalentEDN(`(1 2)`))
+		})
+
+		It("should take elements and serializers", func() {
+			elem, err := elements.Parse([]byte(`{:k "v"}`))
+			Ω(err).Should(BeNil())
+			Ω(elem).Should(BeEquivalentEDN(`{:k "v"}`))
+			Ω(`{:k "v"}`).Should(BeEquivalentEDN(elem))
+
+			datom, err := elements.NewDatom(1, 2, "x", elements.T(3), true)
+			Ω(err).Should(BeNil())
+			Ω(datom).Should(BeEquivalentEDN(`#datom [1 2 "x" 3 true]`))
+		})
+
+		It("should ignore the order of keys that are sets or maps", func() {
+			for i := 0; i < 20; i++ {
+				Ω(`{#{6 5 4 3 2 1} 1}`).Should(BeEquivalentEDN(`{#{1 2 3 4 5 6} 1}`))
+				Ω(`{{:d 4 :c 3 :b 2 :a 1} [1]}`).Should(BeEquivalentEDN(`{{:a 1 :b 2 :c 3 :d 4} [1]}`))
+				Ω(`{#{6 5 4 3 2 1} 1}`).ShouldNot(BeEquivalentEDN(`{#{1 2 3 4 5 6} 2}`))
+			}
+		})
+
+		It("should read built elements back like parsed ones", func() {
+			vector, err := elements.NewVector()
+			Ω(err).Should(BeNil())
+			one, err := elements.NewIntegerElement(1)
+			Ω(err).Should(BeNil())
+			Ω(vector.Append(one)).Should(Succeed())
+			Ω(vector.SetTag("my/tag")).Should(Succeed())
+
+			Ω(vector).Should(BeEquivalentEDN(`#my/tag [1]`))
+			Ω(`#my/tag [1]`).Should(BeEquivalentEDN(vector))
+			Ω(vector).ShouldNot(BeEquivalentEDN(`#my/tag [2]`))
+		})
+
+		It("should show both sides sorted and where they differ", func() {
+			matcher := BeEquivalentEDN(`{:b {:c [1 2]} :a 1}`)
+			success, err := matcher.Match(`{:a 1 :b {:c [1 3]}}`)
+			Ω(err).Should(BeNil())
+			Ω(success).Should(BeFalse())
+			Ω(matcher.FailureMessage(nil)).Should(Equal("Expected\n    {:a 1, :b {:c [1 3]}}\nto be equivalent EDN to\n" +
+				"    {:a 1, :b {:c [1 2]}}\n    at [:b :c 1]: found 3, expected 2"))
+		})
+
+		It("should fail on values that are not EDN", func() {
+			_, err := BeEquivalentEDN(`1`).Match(42)
+			Ω(errors.Is(err, ErrNotEDN)).Should(BeTrue())
+
+			_, err = BeEquivalentEDN(`1`).Match(`{:a`)
+			var parseErr *elements.ParseError
+			Ω(errors.As(err, &parseErr)).Should(BeTrue())
+
+			_, err = BeEquivalentEDN(nil).Match(`1`)
+			Ω(errors.Is(err, ErrNotEDN)).Should(BeTrue())
+		})
+	})
+
+	Context("HaveEDNKey", func() {
+
+		It("should find the keys of maps, vectors and sets", func() {
+			Ω(`{:db/id 1 "name" 2}`).Should(HaveEDNKey(":db/id"))
+			Ω(`{:db/id 1 "name" 2}`).Should(HaveEDNKey("name"))
+			Ω(`{:db/id 1}`).ShouldNot(HaveEDNKey(":db/ident"))
+			Ω(`[:a :b]`).Should(HaveEDNKey(1))
+			Ω(`[:a :b]`).ShouldNot(HaveEDNKey(2))
+			Ω(`#{:a}`).Should(HaveEDNKey(":a"))
+			Ω(`#tagged {:a 1}`).Should(HaveEDNKey(":a"))
+		})
+
+		It("should fail on values that have no keys", func() {
+			_, err := HaveEDNKey(":a").Match(`1`)
+			Ω(errors.Is(err, elements.ErrWrongCollection)).Should(BeTrue())
+
+			_, err = HaveEDNKey(":a").Match(`[1]`)
+			Ω(errors.Is(err, elements.ErrInvalidKey)).Should(BeTrue())
+		})
+
+		It("should show the collection and the key", func() {
+			matcher := HaveEDNKey(":b")
+			Ω(matcher.Match(`{:a 1}`)).Should(BeFalse())
+			Ω(matcher.FailureMessage(nil)).Should(Equal("Expected\n    {:a 1}\nto have EDN key\n    :b"))
+		})
+	})
+
+	Context("MatchEDNPath", func() {
+
+		It("should match the element at the end of the path", func() {
+			doc := `{:person/friends [{:person/name "x"} {:person/name "y" :person/age 3}]}`
+			Ω(doc).Should(MatchEDNPath([]interface{}{":person/friends", 1}, `{:person/age 3 :person/name "y"}`))
+			Ω(doc).Should(MatchEDNPath([]interface{}{":person/friends", 0}, HaveEDNKey(":person/name")))
+			Ω(doc).ShouldNot(MatchEDNPath([]interface{}{":person/friends", 0, ":person/name"}, `"y"`))
+			Ω(doc).ShouldNot(MatchEDNPath([]interface{}{":person/friends", 2}, `{}`))
+		})
+
+		It("should show where the path could not be followed", func() {
+			matcher := MatchEDNPath([]interface{}{":a", 1}, `1`)
+			Ω(matcher.Match(`{:a [1]}`)).Should(BeFalse())
+			Ω(matcher.FailureMessage(nil)).Should(HavePrefix("Expected\n    {:a [1]}\nto have EDN path [:a 1]\n    path "))
+		})
+
+		It("should show the failure of the matcher with the path", func() {
+			matcher := MatchEDNPath([]interface{}{":a", "b"}, `1`)
+			Ω(matcher.Match(`{:a {"b" 2}}`)).Should(BeFalse())
+			Ω(matcher.FailureMessage(nil)).Should(Equal("At EDN path [:a \"b\"]\nExpected\n    2\n" +
+				"to be equivalent EDN to\n    1\n    at the top: found 2, expected 1"))
+		})
+	})
+})
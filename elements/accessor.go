@@ -0,0 +1,426 @@
+package elements
+
+import (
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/mattrobenolt/gocql/uuid"
+)
+
+// Accessor reads the value of an element as a Go type. Each method returns an ElementError wrapping ErrTypeMismatch if
+// the element is not of a type that fits, instead of panicking like a type assertion on Value. Numbers are widened:
+// AsInt64 and AsBigInt accept integers and big integers, AsFloat64 and AsBigFloat accept any number. AsInt64Exact and
+// AsFloat64Exact do not widen, they accept only integers and only floats.
+type Accessor interface {
+
+	// AsBool returns the value of a boolean.
+	AsBool() (value bool, err error)
+
+	// AsInt64 returns the value of an integer, or of a big integer that fits.
+	AsInt64() (value int64, err error)
+
+	// AsFloat64 returns the value of any number as a float, if it fits.
+	AsFloat64() (value float64, err error)
+
+	// AsInt64Exact returns the value of an integer, a big integer is a mismatch even if it fits.
+	AsInt64Exact() (value int64, err error)
+
+	// AsFloat64Exact returns the value of a float, any other number is a mismatch.
+	AsFloat64Exact() (value float64, err error)
+
+	// AsBigInt returns the value of an integer or big integer.
+	AsBigInt() (value *big.Int, err error)
+
+	// AsBigFloat returns the value of any number as a big float.
+	AsBigFloat() (value *big.Float, err error)
+
+	// AsString returns the value of a string.
+	AsString() (value string, err error)
+
+	// AsRune returns the value of a character.
+	AsRune() (value rune, err error)
+
+	// AsKeyword returns the element as a keyword.
+	AsKeyword() (value KeywordElement, err error)
+
+	// AsSymbol returns the element as a symbol.
+	AsSymbol() (value SymbolElement, err error)
+
+	// AsTime returns the value of an instant.
+	AsTime() (value time.Time, err error)
+
+	// AsUUID returns the value of a UUID.
+	AsUUID() (value uuid.UUID, err error)
+
+	// AsGroup returns the element as a group.
+	AsGroup() (value CollectionElement, err error)
+
+	// AsVector returns the element as a vector.
+	AsVector() (value CollectionElement, err error)
+
+	// AsSet returns the element as a set.
+	AsSet() (value CollectionElement, err error)
+
+	// AsMap returns the element as a map.
+	AsMap() (value CollectionElement, err error)
+
+	// AsTagged returns the element as a tagged element.
+	AsTagged() (value TaggedElement, err error)
+}
+
+// CollectionAccessor reads the children of a collection as Go types. Each method gets the child like Get and converts
+// it like the Accessor method of the same type, errors carry the key in their path.
+type CollectionAccessor interface {
+
+	// GetBool returns the boolean under the key.
+	GetBool(key interface{}) (value bool, err error)
+
+	// GetInt64 returns the integer under the key.
+	GetInt64(key interface{}) (value int64, err error)
+
+	// GetFloat64 returns the number under the key as a float.
+	GetFloat64(key interface{}) (value float64, err error)
+
+	// GetString returns the string under the key.
+	GetString(key interface{}) (value string, err error)
+
+	// GetKeyword returns the keyword under the key.
+	GetKeyword(key interface{}) (value KeywordElement, err error)
+
+	// GetTime returns the instant under the key.
+	GetTime(key interface{}) (value time.Time, err error)
+
+	// GetUUID returns the UUID under the key.
+	GetUUID(key interface{}) (value uuid.UUID, err error)
+
+	// GetVector returns the vector under the key.
+	GetVector(key interface{}) (value CollectionElement, err error)
+
+	// GetMap returns the map under the key.
+	GetMap(key interface{}) (value CollectionElement, err error)
+}
+
+// mismatch returns the error for an element that is not of a type the accessor accepts. The error holds the element
+// that embeds the base, if any, as that is the element the caller has.
+func (elem *baseElemImpl) mismatch() error {
+	var value Element = elem
+	if elem.outer != nil {
+		value = elem.outer
+	}
+
+	return &ElementError{Type: elem.elemType, Value: value, Err: ErrTypeMismatch}
+}
+
+// AsBool returns the value of a boolean.
+func (elem *baseElemImpl) AsBool() (value bool, err error) {
+	if elem.elemType == BooleanType {
+		value = elem.value.(bool)
+	} else {
+		err = elem.mismatch()
+	}
+
+	return value, err
+}
+
+// AsInt64 returns the value of an integer, or of a big integer that fits.
+func (elem *baseElemImpl) AsInt64() (value int64, err error) {
+	switch v := elem.value.(type) {
+	case int64:
+		value = v
+	case *big.Int:
+		if v.IsInt64() {
+			value = v.Int64()
+		} else {
+			err = elem.mismatch()
+		}
+	default:
+		err = elem.mismatch()
+	}
+
+	return value, err
+}
+
+// AsFloat64 returns the value of any number as a float. Integers and big integers must fit the float exactly, and big
+// decimals, which seldom have an exact binary form, are rounded to the nearest float but must be within its range.
+func (elem *baseElemImpl) AsFloat64() (value float64, err error) {
+	var f *big.Float
+	if v, is := elem.value.(float64); is {
+		value = v
+	} else if f, err = elem.AsBigFloat(); err == nil {
+		var accuracy big.Accuracy
+		value, accuracy = f.Float64()
+		if math.IsInf(value, 0) || (value == 0 && f.Sign() != 0) || (accuracy != big.Exact && elem.elemType != BigDecType) {
+			value, err = 0, elem.mismatch()
+		}
+	}
+
+	return value, err
+}
+
+// AsInt64Exact returns the value of an integer, a big integer is a mismatch even if it fits.
+func (elem *baseElemImpl) AsInt64Exact() (value int64, err error) {
+	if elem.elemType == IntegerType {
+		value = elem.value.(int64)
+	} else {
+		err = elem.mismatch()
+	}
+
+	return value, err
+}
+
+// AsFloat64Exact returns the value of a float, any other number is a mismatch.
+func (elem *baseElemImpl) AsFloat64Exact() (value float64, err error) {
+	if elem.elemType == FloatType {
+		value = elem.value.(float64)
+	} else {
+		err = elem.mismatch()
+	}
+
+	return value, err
+}
+
+// AsBigInt returns the value of an integer or big integer.
+func (elem *baseElemImpl) AsBigInt() (value *big.Int, err error) {
+	switch v := elem.value.(type) {
+	case int64:
+		value = big.NewInt(v)
+	case *big.Int:
+		value = new(big.Int).Set(v)
+	default:
+		err = elem.mismatch()
+	}
+
+	return value, err
+}
+
+// AsBigFloat returns the value of any number as a big float.
+func (elem *baseElemImpl) AsBigFloat() (value *big.Float, err error) {
+	switch v := elem.value.(type) {
+	case int64:
+		value = new(big.Float).SetInt64(v)
+	case float64:
+		value = big.NewFloat(v)
+	case *big.Int:
+		value = new(big.Float).SetInt(v)
+	case *big.Float:
+		value = new(big.Float).Copy(v)
+	default:
+		err = elem.mismatch()
+	}
+
+	return value, err
+}
+
+// AsString returns the value of a string.
+func (elem *baseElemImpl) AsString() (value string, err error) {
+	if elem.elemType == StringType {
+		value = elem.value.(string)
+	} else {
+		err = elem.mismatch()
+	}
+
+	return value, err
+}
+
+// AsRune returns the value of a character.
+func (elem *baseElemImpl) AsRune() (value rune, err error) {
+	if elem.elemType == CharacterType {
+		value = elem.value.(rune)
+	} else {
+		err = elem.mismatch()
+	}
+
+	return value, err
+}
+
+// AsKeyword returns the element as a keyword.
+func (elem *baseElemImpl) AsKeyword() (value KeywordElement, err error) {
+	if elem.elemType == KeywordType {
+		value = elem.value.(KeywordElement)
+	} else {
+		err = elem.mismatch()
+	}
+
+	return value, err
+}
+
+// AsSymbol returns the element as a symbol.
+func (elem *baseElemImpl) AsSymbol() (value SymbolElement, err error) {
+	if elem.elemType == SymbolType {
+		value = elem.value.(SymbolElement)
+	} else {
+		err = elem.mismatch()
+	}
+
+	return value, err
+}
+
+// AsTime returns the value of an instant.
+func (elem *baseElemImpl) AsTime() (value time.Time, err error) {
+	if elem.elemType == InstantType {
+		value = elem.value.(time.Time)
+	} else {
+		err = elem.mismatch()
+	}
+
+	return value, err
+}
+
+// AsUUID returns the value of a UUID.
+func (elem *baseElemImpl) AsUUID() (value uuid.UUID, err error) {
+	if elem.elemType == UUIDType {
+		value = elem.value.(uuid.UUID)
+	} else {
+		err = elem.mismatch()
+	}
+
+	return value, err
+}
+
+// AsGroup returns the element as a group.
+func (elem *baseElemImpl) AsGroup() (value CollectionElement, err error) {
+	return elem.asCollection(GroupingType)
+}
+
+// AsVector returns the element as a vector.
+func (elem *baseElemImpl) AsVector() (value CollectionElement, err error) {
+	return elem.asCollection(VectorType)
+}
+
+// AsSet returns the element as a set.
+func (elem *baseElemImpl) AsSet() (value CollectionElement, err error) {
+	return elem.asCollection(SetType)
+}
+
+// AsMap returns the element as a map.
+func (elem *baseElemImpl) AsMap() (value CollectionElement, err error) {
+	return elem.asCollection(MapType)
+}
+
+// asCollection returns the element as a collection of the type.
+func (elem *baseElemImpl) asCollection(elemType ElementType) (value CollectionElement, err error) {
+	if elem.elemType == elemType {
+		value = elem.value.(CollectionElement)
+	} else {
+		err = elem.mismatch()
+	}
+
+	return value, err
+}
+
+// AsTagged returns the element as a tagged element.
+func (elem *baseElemImpl) AsTagged() (value TaggedElement, err error) {
+	return value, elem.mismatch()
+}
+
+// AsTagged returns the element as a tagged element.
+func (elem *taggedElemImpl) AsTagged() (value TaggedElement, err error) {
+	return elem, err
+}
+
+// GetBool returns the boolean under the key.
+func (elem *collectionElemImpl) GetBool(key interface{}) (value bool, err error) {
+	var child Element
+	if child, err = elem.Get(key); err == nil {
+		value, err = child.AsBool()
+	}
+
+	return value, keyError(key, err)
+}
+
+// GetInt64 returns the integer under the key.
+func (elem *collectionElemImpl) GetInt64(key interface{}) (value int64, err error) {
+	var child Element
+	if child, err = elem.Get(key); err == nil {
+		value, err = child.AsInt64()
+	}
+
+	return value, keyError(key, err)
+}
+
+// GetFloat64 returns the number under the key as a float.
+func (elem *collectionElemImpl) GetFloat64(key interface{}) (value float64, err error) {
+	var child Element
+	if child, err = elem.Get(key); err == nil {
+		value, err = child.AsFloat64()
+	}
+
+	return value, keyError(key, err)
+}
+
+// GetString returns the string under the key.
+func (elem *collectionElemImpl) GetString(key interface{}) (value string, err error) {
+	var child Element
+	if child, err = elem.Get(key); err == nil {
+		value, err = child.AsString()
+	}
+
+	return value, keyError(key, err)
+}
+
+// GetKeyword returns the keyword under the key.
+func (elem *collectionElemImpl) GetKeyword(key interface{}) (value KeywordElement, err error) {
+	var child Element
+	if child, err = elem.Get(key); err == nil {
+		value, err = child.AsKeyword()
+	}
+
+	return value, keyError(key, err)
+}
+
+// GetTime returns the instant under the key.
+func (elem *collectionElemImpl) GetTime(key interface{}) (value time.Time, err error) {
+	var child Element
+	if child, err = elem.Get(key); err == nil {
+		value, err = child.AsTime()
+	}
+
+	return value, keyError(key, err)
+}
+
+// GetUUID returns the UUID under the key.
+func (elem *collectionElemImpl) GetUUID(key interface{}) (value uuid.UUID, err error) {
+	var child Element
+	if child, err = elem.Get(key); err == nil {
+		value, err = child.AsUUID()
+	}
+
+	return value, keyError(key, err)
+}
+
+// GetVector returns the vector under the key.
+func (elem *collectionElemImpl) GetVector(key interface{}) (value CollectionElement, err error) {
+	var child Element
+	if child, err = elem.Get(key); err == nil {
+		value, err = child.AsVector()
+	}
+
+	return value, keyError(key, err)
+}
+
+// GetMap returns the map under the key.
+func (elem *collectionElemImpl) GetMap(key interface{}) (value CollectionElement, err error) {
+	var child Element
+	if child, err = elem.Get(key); err == nil {
+		value, err = child.AsMap()
+	}
+
+	return value, keyError(key, err)
+}
+
+// keyError adds the key to the path of the error.
+func keyError(key interface{}, err error) error {
+	if elemErr, is := err.(*ElementError); is {
+		err = &ElementError{
+			Path:  append([]interface{}{key}, elemErr.Path...),
+			Type:  elemErr.Type,
+			Value: elemErr.Value,
+			Err:   elemErr.Err,
+		}
+	} else if err != nil {
+		err = &ElementError{Path: []interface{}{key}, Err: err}
+	}
+
+	return err
+}
@@ -0,0 +1,213 @@
+package elements
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Accessors", func() {
+
+	parse := func(src string) Element {
+		elem, err := Parse([]byte(src))
+		Ω(err).Should(BeNil(), src)
+		return elem
+	}
+
+	It("should read scalars", func() {
+		b, err := parse(`true`).AsBool()
+		Ω(err).Should(BeNil())
+		Ω(b).Should(BeTrue())
+
+		s, err := parse(`"x"`).AsString()
+		Ω(err).Should(BeNil())
+		Ω(s).Should(Equal("x"))
+
+		r, err := parse(`\x`).AsRune()
+		Ω(err).Should(BeNil())
+		Ω(r).Should(Equal('x'))
+
+		kw, err := parse(`:person/name`).AsKeyword()
+		Ω(err).Should(BeNil())
+		Ω(kw.Prefix()).Should(Equal("person"))
+		Ω(kw.Name()).Should(Equal("name"))
+
+		sym, err := parse(`my/fn`).AsSymbol()
+		Ω(err).Should(BeNil())
+		Ω(sym.Name()).Should(Equal("fn"))
+
+		t, err := parse(`#inst "2020-01-02T03:04:05Z"`).AsTime()
+		Ω(err).Should(BeNil())
+		Ω(t.Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))).Should(BeTrue())
+
+		u, err := parse(`#uuid "f81d4fae-7dec-11d0-a765-00a0c91e6bf6"`).AsUUID()
+		Ω(err).Should(BeNil())
+		Ω(u.String()).Should(Equal("f81d4fae-7dec-11d0-a765-00a0c91e6bf6"))
+
+		tagged, err := parse(`#myapp/money "1 USD"`).AsTagged()
+		Ω(err).Should(BeNil())
+		Ω(tagged.Tag()).Should(Equal("myapp/money"))
+	})
+
+	It("should widen numbers", func() {
+		i, err := parse(`12N`).AsInt64()
+		Ω(err).Should(BeNil())
+		Ω(i).Should(BeEquivalentTo(12))
+
+		f, err := parse(`2`).AsFloat64()
+		Ω(err).Should(BeNil())
+		Ω(f).Should(BeEquivalentTo(2))
+
+		f, err = parse(`0.5M`).AsFloat64()
+		Ω(err).Should(BeNil())
+		Ω(f).Should(BeEquivalentTo(0.5))
+
+		bi, err := parse(`7`).AsBigInt()
+		Ω(err).Should(BeNil())
+		Ω(bi.Cmp(big.NewInt(7))).Should(BeZero())
+
+		bf, err := parse(`1.5`).AsBigFloat()
+		Ω(err).Should(BeNil())
+		Ω(bf.Cmp(big.NewFloat(1.5))).Should(BeZero())
+
+		_, err = parse(`99999999999999999999N`).AsInt64()
+		Ω(errors.Is(err, ErrTypeMismatch)).Should(BeTrue())
+
+		_, err = parse(`1.5`).AsInt64()
+		Ω(errors.Is(err, ErrTypeMismatch)).Should(BeTrue())
+
+		f, err = parse(`9007199254740992`).AsFloat64()
+		Ω(err).Should(BeNil())
+		Ω(f).Should(BeEquivalentTo(1 << 53))
+
+		f, err = parse(`0.1M`).AsFloat64()
+		Ω(err).Should(BeNil())
+		Ω(f).Should(BeEquivalentTo(0.1))
+
+		for _, src := range []string{`9007199254740993`, `99999999999999999999N`, `1e400M`, `-1e400M`, `1e-400M`,
+			`1` + strings.Repeat("0", 400) + `N`} {
+			_, err = parse(src).AsFloat64()
+			Ω(errors.Is(err, ErrTypeMismatch)).Should(BeTrue(), src)
+		}
+	})
+
+	It("should read collections", func() {
+		for src, as := range map[string]func(Element) (CollectionElement, error){
+			`(1)`:   Element.AsGroup,
+			`[1]`:   Element.AsVector,
+			`#{1}`:  Element.AsSet,
+			`{1 1}`: Element.AsMap,
+		} {
+			coll, err := as(parse(src))
+			Ω(err).Should(BeNil(), src)
+			Ω(coll.Len()).Should(Equal(1), src)
+
+			_, err = as(parse(`1`))
+			Ω(errors.Is(err, ErrTypeMismatch)).Should(BeTrue(), src)
+		}
+	})
+
+	It("should read numbers without widening on request", func() {
+		i, err := parse(`12`).AsInt64Exact()
+		Ω(err).Should(BeNil())
+		Ω(i).Should(BeEquivalentTo(12))
+
+		f, err := parse(`1.5`).AsFloat64Exact()
+		Ω(err).Should(BeNil())
+		Ω(f).Should(BeEquivalentTo(1.5))
+
+		for _, src := range []string{`12N`, `1.5`, `"12"`} {
+			_, err = parse(src).AsInt64Exact()
+			Ω(errors.Is(err, ErrTypeMismatch)).Should(BeTrue(), src)
+		}
+
+		for _, src := range []string{`2`, `2N`, `0.5M`, `"1.5"`} {
+			_, err = parse(src).AsFloat64Exact()
+			Ω(errors.Is(err, ErrTypeMismatch)).Should(BeTrue(), src)
+		}
+	})
+
+	It("should report mismatches without panicking", func() {
+		var elemErr *ElementError
+
+		_, err := parse(`:a`).AsString()
+		Ω(errors.As(err, &elemErr)).Should(BeTrue())
+		Ω(elemErr.Type).Should(BeEquivalentTo(KeywordType))
+		Ω(err.Error()).Should(Equal(`Element does not fit the target (type :db.type/keyword, value :a)`))
+
+		_, err = parse(`a`).AsKeyword()
+		Ω(errors.Is(err, ErrTypeMismatch)).Should(BeTrue())
+		Ω(errors.As(err, &elemErr)).Should(BeTrue())
+		Ω(elemErr.Value).Should(BeAssignableToTypeOf(&symbolElemImpl{}))
+
+		_, err = parse(`#myapp/n 1`).AsInt64()
+		Ω(errors.Is(err, ErrTypeMismatch)).Should(BeTrue())
+		Ω(errors.As(err, &elemErr)).Should(BeTrue())
+		Ω(elemErr.Value).Should(BeAssignableToTypeOf(&taggedElemImpl{}))
+
+		vector := parse(`[1]`)
+		_, err = vector.AsMap()
+		Ω(errors.As(err, &elemErr)).Should(BeTrue())
+		Ω(elemErr.Value).Should(BeIdenticalTo(vector))
+		_, err = Clone(vector).AsMap()
+		Ω(errors.As(err, &elemErr)).Should(BeTrue())
+		Ω(elemErr.Value).ShouldNot(BeIdenticalTo(vector))
+
+		_, err = parse(`1`).AsTagged()
+		Ω(errors.Is(err, ErrTypeMismatch)).Should(BeTrue())
+	})
+
+	It("should read the children of maps and vectors", func() {
+		coll, err := parse(`{:person/name "x" :person/age 42 :person/admin false :person/role :role/user ` +
+			`:person/born #inst "2000-01-01T00:00:00Z" :person/tags ["a"] :person/address {:city "y"} "score" 1.5}`).AsMap()
+		Ω(err).Should(BeNil())
+
+		name, err := coll.GetString(":person/name")
+		Ω(err).Should(BeNil())
+		Ω(name).Should(Equal("x"))
+
+		age, err := coll.GetInt64(":person/age")
+		Ω(err).Should(BeNil())
+		Ω(age).Should(BeEquivalentTo(42))
+
+		admin, err := coll.GetBool(":person/admin")
+		Ω(err).Should(BeNil())
+		Ω(admin).Should(BeFalse())
+
+		role, err := coll.GetKeyword(":person/role")
+		Ω(err).Should(BeNil())
+		Ω(role.Name()).Should(Equal("user"))
+
+		born, err := coll.GetTime(":person/born")
+		Ω(err).Should(BeNil())
+		Ω(born.Year()).Should(Equal(2000))
+
+		score, err := coll.GetFloat64("score")
+		Ω(err).Should(BeNil())
+		Ω(score).Should(Equal(1.5))
+
+		tags, err := coll.GetVector(":person/tags")
+		Ω(err).Should(BeNil())
+		tag, err := tags.GetString(0)
+		Ω(err).Should(BeNil())
+		Ω(tag).Should(Equal("a"))
+
+		address, err := coll.GetMap(parse(`:person/address`))
+		Ω(err).Should(BeNil())
+		Ω(address.Len()).Should(Equal(1))
+
+		var elemErr *ElementError
+		_, err = coll.GetString(":person/age")
+		Ω(errors.Is(err, ErrTypeMismatch)).Should(BeTrue())
+		Ω(errors.As(err, &elemErr)).Should(BeTrue())
+		Ω(elemErr.Path).Should(Equal([]interface{}{":person/age"}))
+
+		_, err = coll.GetUUID(":person/id")
+		Ω(errors.Is(err, ErrNoValue)).Should(BeTrue())
+		Ω(err.Error()).Should(HavePrefix(`at [":person/id"]: `))
+	})
+})
@@ -0,0 +1,133 @@
+package elements
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// appendInput is the document used to check and benchmark appending collections.
+const appendInput = `[1 -2.5E3 "a\"b" \c \newline \é :db/ident :a/_b sym nil true 10N 1.5M #inst "2018-01-02T03:04:05Z"
+	#uuid "f81d4fae-7dec-11d0-a765-00a0c91e6bf6" #my/tag (1 2) #{3} {:k [4]}]`
+
+var _ = Describe("Appending EDN", func() {
+
+	It("should append what Serialize writes after what is in the buffer", func() {
+		elem, err := Parse([]byte(appendInput))
+		Ω(err).Should(BeNil())
+
+		out, err := elem.AppendEDN([]byte("prefix "))
+		Ω(err).Should(BeNil())
+		Ω(string(out)).Should(Equal(`prefix [1 -2.5E+03 "a\"b" \c \newline \u00e9 :db/ident :a/_b sym nil true 10N 1.5M ` +
+			`#inst 2018-01-02T03:04:05Z #uuid f81d4fae-7dec-11d0-a765-00a0c91e6bf6 #my/tag (1 2) #{3} {:k [4]}]`))
+
+		str, err := elem.Serialize()
+		Ω(err).Should(BeNil())
+		Ω("prefix " + str).Should(Equal(string(out)))
+	})
+
+	It("should append datoms", func() {
+		for _, value := range []interface{}{int64(42), "x", ":db/ident", "nil", mustParse(`:db/ident`)} {
+			datom, err := NewDatom(1, 2, value, T(3), false)
+			Ω(err).Should(BeNil())
+
+			out, err := datom.AppendEDN([]byte("prefix "))
+			Ω(err).Should(BeNil())
+
+			str, err := datom.Serialize()
+			Ω(err).Should(BeNil())
+			Ω(string(out)).Should(Equal("prefix " + str))
+			Ω(str).Should(MatchRegexp(`^#datom \[1 2 (42|"x"|:db/ident|nil) 3 false\]$`))
+		}
+	})
+
+	It("should return the error of a datom value that is not an element", func() {
+		datom, err := NewDatom(1, 2, true, T(3), true)
+		Ω(err).Should(BeNil())
+
+		_, err = datom.AppendEDN(nil)
+		Ω(err).ShouldNot(BeNil())
+	})
+
+	It("should not allocate when the buffer has room, except for big numbers", func() {
+		datom, err := NewDatom(17592186045422, 63, "The Goonies", T(13194139534317), true)
+		Ω(err).Should(BeNil())
+		ref, err := NewDatom(17592186045422, 64, int64(17592186045423), T(13194139534317), false)
+		Ω(err).Should(BeNil())
+
+		buf := make([]byte, 0, 1024)
+		for _, appender := range []Appender{mustParse(`12345`), mustParse(`"text"`), mustParse(`:db/ident`),
+			mustParse(`\newline`), mustParse(`#uuid "f81d4fae-7dec-11d0-a765-00a0c91e6bf6"`),
+			mustParse(`[1 2.5 #inst "2018-01-02T03:04:05Z" #my/tag (nil true) #{sym} {:k [4]}]`), datom, ref} {
+			allocs := testing.AllocsPerRun(100, func() {
+				if _, err := appender.AppendEDN(buf); err != nil {
+					panic(err)
+				}
+			})
+			Ω(allocs).Should(BeZero())
+		}
+	})
+})
+
+// benchmarkAppend measures appending the element to a reused buffer.
+func benchmarkAppend(b *testing.B, appender Appender) {
+	buf := make([]byte, 0, 1024)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := appender.AppendEDN(buf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchmarkSerialize measures serializing the element into a string.
+func benchmarkSerialize(b *testing.B, serializer Serializer) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := serializer.Serialize(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchmarkDatom is the datom used for the benchmarks.
+func benchmarkDatom(b *testing.B) Datom {
+	datom, err := NewDatom(17592186045422, 63, "The Goonies", T(13194139534317), true)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return datom
+}
+
+// BenchmarkAppendScalar measures appending a keyword.
+func BenchmarkAppendScalar(b *testing.B) {
+	benchmarkAppend(b, mustParse(`:db/ident`))
+}
+
+// BenchmarkSerializeScalar measures serializing a keyword.
+func BenchmarkSerializeScalar(b *testing.B) {
+	benchmarkSerialize(b, mustParse(`:db/ident`))
+}
+
+// BenchmarkAppendCollection measures appending a vector of every kind of element.
+func BenchmarkAppendCollection(b *testing.B) {
+	benchmarkAppend(b, mustParse(appendInput))
+}
+
+// BenchmarkSerializeCollection measures serializing a vector of every kind of element.
+func BenchmarkSerializeCollection(b *testing.B) {
+	benchmarkSerialize(b, mustParse(appendInput))
+}
+
+// BenchmarkAppendDatom measures appending a datom.
+func BenchmarkAppendDatom(b *testing.B) {
+	benchmarkAppend(b, benchmarkDatom(b))
+}
+
+// BenchmarkSerializeDatom measures serializing a datom.
+func BenchmarkSerializeDatom(b *testing.B) {
+	benchmarkSerialize(b, benchmarkDatom(b))
+}
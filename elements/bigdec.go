@@ -0,0 +1,44 @@
+package elements
+
+import (
+	"math/big"
+)
+
+const (
+
+	// BigDecSuffix defines the suffix marking an arbitrary precision decimal.
+	BigDecSuffix = "M"
+)
+
+// init will add the element factory to the collection of factories
+func initBigDec(registry *Registry) error {
+	return registry.AddFactory(BigDecType, func(input interface{}) (elem Element, err error) {
+		if v, ok := input.(*big.Float); ok && v != nil {
+			elem, err = NewBigDecElement(v)
+		} else {
+			err = ErrInvalidInput
+		}
+		return elem, err
+	})
+}
+
+// NewBigDecElement creates a new arbitrary precision decimal element or an error. The value is copied.
+func NewBigDecElement(value *big.Float) (elem Element, err error) {
+
+	if value == nil || value.IsInf() {
+		err = ErrInvalidInput
+	} else {
+		var base *baseElemImpl
+		if base, err = makeBaseElement(new(big.Float).Copy(value), BigDecType, func(dst []byte, value interface{}) (out []byte, e error) {
+			out = append(value.(*big.Float).Append(dst, 'g', -1), BigDecSuffix...)
+			return out, e
+		}); err == nil {
+			base.equality = func(left, right Element) bool {
+				return left.Value().(*big.Float).Cmp(right.Value().(*big.Float)) == 0
+			}
+			elem = base
+		}
+	}
+
+	return elem, err
+}
@@ -0,0 +1,80 @@
+package elements
+
+import (
+	"math/big"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BigDec in EDN", func() {
+	Context("", func() {
+
+		It("should initialize without issue", func() {
+			registry := NewRegistry()
+			registry.RemoveFactory(BigDecType)
+			err := initBigDec(registry)
+			Ω(err).Should(BeNil())
+			_, has := registry.Factory(BigDecType)
+			Ω(has).Should(BeTrue())
+
+			err = initBigDec(registry)
+			Ω(err).ShouldNot(BeNil())
+			Ω(err).Should(BeEquivalentTo(ErrInvalidFactory))
+		})
+
+		It("should create elements from the factory", func() {
+			v := big.NewFloat(1.5)
+
+			factory, has := defaultRegistry.Factory(BigDecType)
+			Ω(has).Should(BeTrue())
+
+			elem, err := factory(v)
+			Ω(err).Should(BeNil())
+			Ω(elem.ElementType()).Should(BeEquivalentTo(BigDecType))
+			Ω(elem.Value().(*big.Float).Cmp(v)).Should(BeZero())
+		})
+
+		It("should not create elements from the factory if the input is not a the right type", func() {
+			v := "foo"
+
+			factory, has := defaultRegistry.Factory(BigDecType)
+			Ω(has).Should(BeTrue())
+
+			elem, err := factory(v)
+			Ω(err).ShouldNot(BeNil())
+			Ω(err).Should(BeEquivalentTo(ErrInvalidInput))
+			Ω(elem).Should(BeNil())
+		})
+	})
+
+	Context("with the default marshaller", func() {
+
+		It("should serialize with the suffix", func() {
+			elem, err := NewBigDecElement(big.NewFloat(1.5))
+			Ω(err).Should(BeNil())
+
+			edn, err := elem.Serialize()
+			Ω(err).Should(BeNil())
+			Ω(edn).Should(BeEquivalentTo("1.5M"))
+		})
+
+		It("should compare by value and copy the input", func() {
+			v := big.NewFloat(1.5)
+			elem, err := NewBigDecElement(v)
+			Ω(err).Should(BeNil())
+
+			other, err := NewElement(big.NewFloat(1.5))
+			Ω(err).Should(BeNil())
+			Ω(elem.Equals(other)).Should(BeTrue())
+
+			v.Neg(v)
+			Ω(elem.Equals(other)).Should(BeTrue())
+		})
+
+		It("should not accept nil", func() {
+			_, err := NewBigDecElement(nil)
+			Ω(err).Should(BeEquivalentTo(ErrInvalidInput))
+		})
+	})
+})
@@ -0,0 +1,44 @@
+package elements
+
+import (
+	"math/big"
+)
+
+const (
+
+	// BigIntSuffix defines the suffix marking an arbitrary precision integer.
+	BigIntSuffix = "N"
+)
+
+// init will add the element factory to the collection of factories
+func initBigInt(registry *Registry) error {
+	return registry.AddFactory(BigIntType, func(input interface{}) (elem Element, err error) {
+		if v, ok := input.(*big.Int); ok && v != nil {
+			elem, err = NewBigIntElement(v)
+		} else {
+			err = ErrInvalidInput
+		}
+		return elem, err
+	})
+}
+
+// NewBigIntElement creates a new arbitrary precision integer element or an error. The value is copied.
+func NewBigIntElement(value *big.Int) (elem Element, err error) {
+
+	if value == nil {
+		err = ErrInvalidInput
+	} else {
+		var base *baseElemImpl
+		if base, err = makeBaseElement(new(big.Int).Set(value), BigIntType, func(dst []byte, value interface{}) (out []byte, e error) {
+			out = append(value.(*big.Int).Append(dst, 10), BigIntSuffix...)
+			return out, e
+		}); err == nil {
+			base.equality = func(left, right Element) bool {
+				return left.Value().(*big.Int).Cmp(right.Value().(*big.Int)) == 0
+			}
+			elem = base
+		}
+	}
+
+	return elem, err
+}
@@ -0,0 +1,80 @@
+package elements
+
+import (
+	"math/big"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BigInt in EDN", func() {
+	Context("", func() {
+
+		It("should initialize without issue", func() {
+			registry := NewRegistry()
+			registry.RemoveFactory(BigIntType)
+			err := initBigInt(registry)
+			Ω(err).Should(BeNil())
+			_, has := registry.Factory(BigIntType)
+			Ω(has).Should(BeTrue())
+
+			err = initBigInt(registry)
+			Ω(err).ShouldNot(BeNil())
+			Ω(err).Should(BeEquivalentTo(ErrInvalidFactory))
+		})
+
+		It("should create elements from the factory", func() {
+			v := new(big.Int).Exp(big.NewInt(10), big.NewInt(30), nil)
+
+			factory, has := defaultRegistry.Factory(BigIntType)
+			Ω(has).Should(BeTrue())
+
+			elem, err := factory(v)
+			Ω(err).Should(BeNil())
+			Ω(elem.ElementType()).Should(BeEquivalentTo(BigIntType))
+			Ω(elem.Value().(*big.Int).Cmp(v)).Should(BeZero())
+		})
+
+		It("should not create elements from the factory if the input is not a the right type", func() {
+			v := "foo"
+
+			factory, has := defaultRegistry.Factory(BigIntType)
+			Ω(has).Should(BeTrue())
+
+			elem, err := factory(v)
+			Ω(err).ShouldNot(BeNil())
+			Ω(err).Should(BeEquivalentTo(ErrInvalidInput))
+			Ω(elem).Should(BeNil())
+		})
+	})
+
+	Context("with the default marshaller", func() {
+
+		It("should serialize with the suffix", func() {
+			elem, err := NewBigIntElement(new(big.Int).Exp(big.NewInt(10), big.NewInt(30), nil))
+			Ω(err).Should(BeNil())
+
+			edn, err := elem.Serialize()
+			Ω(err).Should(BeNil())
+			Ω(edn).Should(BeEquivalentTo("1000000000000000000000000000000N"))
+		})
+
+		It("should compare by value and copy the input", func() {
+			v := new(big.Int).Exp(big.NewInt(10), big.NewInt(30), nil)
+			elem, err := NewBigIntElement(v)
+			Ω(err).Should(BeNil())
+
+			other, err := NewElement(new(big.Int).Exp(big.NewInt(10), big.NewInt(30), nil))
+			Ω(err).Should(BeNil())
+			Ω(elem.Equals(other)).Should(BeTrue())
+
+			v.Neg(v)
+			Ω(elem.Equals(other)).Should(BeTrue())
+		})
+
+		It("should not accept nil", func() {
+			_, err := NewBigIntElement(nil)
+			Ω(err).Should(BeEquivalentTo(ErrInvalidInput))
+		})
+	})
+})
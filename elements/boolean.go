@@ -3,8 +3,8 @@ package elements
 import "strconv"
 
 // initBoolean will add the element factory to the collection of factories
-func initBoolean() error {
-	return AddElementTypeFactory(BooleanType, func(input interface{}) (elem Element, err error) {
+func initBoolean(registry *Registry) error {
+	return registry.AddFactory(BooleanType, func(input interface{}) (elem Element, err error) {
 		if v, ok := input.(bool); ok {
 			elem, err = NewBooleanElement(v)
 		} else {
@@ -17,8 +17,8 @@ func initBoolean() error {
 // NewBooleanElement creates a new boolean element or an error.
 func NewBooleanElement(value bool) (elem Element, err error) {
 
-	elem, err = makeBaseElement(value, BooleanType, func(value interface{}) (out string, e error) {
-		out = strconv.FormatBool(value.(bool))
+	elem, err = makeBaseElement(value, BooleanType, func(dst []byte, value interface{}) (out []byte, e error) {
+		out = strconv.AppendBool(dst, value.(bool))
 		return out, e
 	})
 
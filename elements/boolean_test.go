@@ -9,13 +9,14 @@ var _ = Describe("Boolean in EDN", func() {
 	Context("", func() {
 
 		It("should initialize without issue", func() {
-			delete(typeFactories, BooleanType)
-			err := initBoolean()
+			registry := NewRegistry()
+			registry.RemoveFactory(BooleanType)
+			err := initBoolean(registry)
 			Ω(err).Should(BeNil())
-			_, has := typeFactories[BooleanType]
+			_, has := registry.Factory(BooleanType)
 			Ω(has).Should(BeTrue())
 
-			err = initBoolean()
+			err = initBoolean(registry)
 			Ω(err).ShouldNot(BeNil())
 			Ω(err).Should(BeEquivalentTo(ErrInvalidFactory))
 		})
@@ -23,7 +24,10 @@ var _ = Describe("Boolean in EDN", func() {
 		It("should create elements from the factory", func() {
 			v := true
 
-			elem, err := typeFactories[BooleanType](v)
+			factory, has := defaultRegistry.Factory(BooleanType)
+			Ω(has).Should(BeTrue())
+
+			elem, err := factory(v)
 			Ω(err).Should(BeNil())
 			Ω(elem.ElementType()).Should(BeEquivalentTo(BooleanType))
 			Ω(elem.Value()).Should(BeEquivalentTo(v))
@@ -32,7 +36,10 @@ var _ = Describe("Boolean in EDN", func() {
 		It("should not create elements from the factory if the input is not a the right type", func() {
 			v := "true"
 
-			elem, err := typeFactories[BooleanType](v)
+			factory, has := defaultRegistry.Factory(BooleanType)
+			Ω(has).Should(BeTrue())
+
+			elem, err := factory(v)
 			Ω(err).ShouldNot(BeNil())
 			Ω(err).Should(BeEquivalentTo(ErrInvalidInput))
 			Ω(elem).Should(BeNil())
@@ -1,8 +1,8 @@
 package elements
 
 import (
-	"fmt"
-	"strings"
+	"bytes"
+	"strconv"
 )
 
 const (
@@ -19,8 +19,8 @@ var specialCharacters = map[rune]string{
 }
 
 // init will add the element factory to the collection of factories
-func initCharacter() error {
-	return AddElementTypeFactory(CharacterType, func(input interface{}) (elem Element, err error) {
+func initCharacter(registry *Registry) error {
+	return registry.AddFactory(CharacterType, func(input interface{}) (elem Element, err error) {
 		if v, ok := input.(rune); ok {
 			elem, err = NewCharacterElement(v)
 		} else {
@@ -32,18 +32,22 @@ func initCharacter() error {
 
 // NewCharacterElement creates a new character element or an error.
 func NewCharacterElement(value rune) (elem Element, err error) {
-	elem, err = makeBaseElement(value, CharacterType, func(value interface{}) (out string, e error) {
+	elem, err = makeBaseElement(value, CharacterType, func(dst []byte, value interface{}) (out []byte, e error) {
 
 		r := value.(rune)
 
-		var has bool
-		if out, has = specialCharacters[r]; !has {
+		if special, has := specialCharacters[r]; has {
+			out = append(dst, special...)
+		} else {
 
 			// if there is no special character, then quote the rune, remove the single quotes around this, then
 			// if it is an ASCII then make sure to prefix is intact.
-			if out = strings.Trim(fmt.Sprintf("%+q", r), "'"); !strings.HasPrefix(out, CharacterPrefix) {
-				out = CharacterPrefix + out
+			var quoted [16]byte
+			q := bytes.Trim(strconv.AppendQuoteRuneToASCII(quoted[:0], r), "'")
+			if out = dst; !bytes.HasPrefix(q, []byte(CharacterPrefix)) {
+				out = append(out, CharacterPrefix...)
 			}
+			out = append(out, q...)
 		}
 
 		return out, e
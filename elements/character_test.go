@@ -11,13 +11,14 @@ var _ = Describe("Character in EDN", func() {
 	Context("", func() {
 
 		It("should initialize without issue", func() {
-			delete(typeFactories, CharacterType)
-			err := initCharacter()
+			registry := NewRegistry()
+			registry.RemoveFactory(CharacterType)
+			err := initCharacter(registry)
 			Ω(err).Should(BeNil())
-			_, has := typeFactories[CharacterType]
+			_, has := registry.Factory(CharacterType)
 			Ω(has).Should(BeTrue())
 
-			err = initCharacter()
+			err = initCharacter(registry)
 			Ω(err).ShouldNot(BeNil())
 			Ω(err).Should(BeEquivalentTo(ErrInvalidFactory))
 		})
@@ -25,7 +26,10 @@ var _ = Describe("Character in EDN", func() {
 		It("should create elements from the factory", func() {
 			v := 'g'
 
-			elem, err := typeFactories[CharacterType](v)
+			factory, has := defaultRegistry.Factory(CharacterType)
+			Ω(has).Should(BeTrue())
+
+			elem, err := factory(v)
 			Ω(err).Should(BeNil())
 			Ω(elem.ElementType()).Should(BeEquivalentTo(CharacterType))
 			Ω(elem.Value()).Should(BeEquivalentTo(v))
@@ -34,7 +38,10 @@ var _ = Describe("Character in EDN", func() {
 		It("should not create elements from the factory if the input is not a the right type", func() {
 			v := "foo"
 
-			elem, err := typeFactories[CharacterType](v)
+			factory, has := defaultRegistry.Factory(CharacterType)
+			Ω(has).Should(BeTrue())
+
+			elem, err := factory(v)
 			Ω(err).ShouldNot(BeNil())
 			Ω(err).Should(BeEquivalentTo(ErrInvalidInput))
 			Ω(elem).Should(BeNil())
@@ -1,7 +1,7 @@
 package elements
 
 import (
-	"fmt"
+	"reflect"
 	"strconv"
 )
 
@@ -20,6 +20,9 @@ type ChildIterator func(key Element, value Element) (err error)
 type CollectionElement interface {
 	Element
 
+	// CollectionAccessor mixin
+	CollectionAccessor
+
 	// Len return the quantity of items in this collection.
 	Len() int
 
@@ -58,7 +61,7 @@ func (elem *collectionElemImpl) Len() (l int) {
 	switch v := elem.collection.(type) {
 	case []Element:
 		l = len(v)
-	case map[string]Element:
+	case map[string]Pair:
 		l = len(v)
 	}
 	return l
@@ -74,15 +77,9 @@ func (elem *collectionElemImpl) IterateChildren(iterator ChildIterator) (err err
 				break
 			}
 		}
-	case map[string]Element:
-		for i, c := range v {
-
-			var k Element
-			if k, err = NewElement(i); err == nil {
-				err = iterator(k, c)
-			}
-
-			if err != nil {
+	case map[string]Pair:
+		for _, pair := range v {
+			if err = iterator(pair.Key(), pair.Value()); err != nil {
 				break
 			}
 		}
@@ -90,62 +87,88 @@ func (elem *collectionElemImpl) IterateChildren(iterator ChildIterator) (err err
 	return err
 }
 
-// collectionSerialization the element into a string or return the appropriate error.
-func collectionSerialization(hasKey bool) func(value interface{}) (composition string, err error) {
+// collectionAppender appends the collection or returns the appropriate error.
+func collectionAppender(hasKey bool) elemAppender {
 
-	return func(value interface{}) (composition string, err error) {
-		val := value.(*collectionElemImpl)
-		composition = val.startSymbol
+	return func(dst []byte, value interface{}) (out []byte, err error) {
+		return appendCollection(dst, value.(*collectionElemImpl), hasKey, appendElement, appendElement)
+	}
+}
 
-		first := true
-		if err = val.IterateChildren(func(key Element, child Element) (e error) {
-			if first {
-				first = false
-			} else {
-				composition += val.separatorSymbol
-			}
+// appendElement appends the serialized element.
+func appendElement(dst []byte, elem Element) ([]byte, error) {
+	return elem.AppendEDN(dst)
+}
 
-			var c string
-			if hasKey {
-				if c, e = key.Serialize(); e == nil {
-					composition += c + val.keyValueSeparatorSymbol
-				}
-			}
+// appendCollection appends the collection without its tag, using the appenders for the keys and the children.
+func appendCollection(dst []byte, val *collectionElemImpl, hasKey bool, keyAppender func([]byte, Element) ([]byte, error),
+	childAppender func([]byte, Element) ([]byte, error)) (out []byte, err error) {
 
-			if e == nil {
-				if c, e = child.Serialize(); e == nil {
-					composition += c
-				}
+	out = append(dst, val.startSymbol...)
+
+	appendChild := func(i int, key Element, child Element) {
+		if i > 0 {
+			out = append(out, val.separatorSymbol...)
+		}
+
+		if hasKey {
+			if out, err = keyAppender(out, key); err == nil {
+				out = append(out, val.keyValueSeparatorSymbol...)
 			}
+		}
 
-			return e
-		}); err == nil {
-			composition += val.endSymbol
+		if err == nil {
+			out, err = childAppender(out, child)
+		}
+	}
+
+	switch v := val.collection.(type) {
+	case []Element:
+		for i := 0; i < len(v) && err == nil; i++ {
+			appendChild(i, nil, v[i])
+		}
+	case map[string]Pair:
+		i := 0
+		for _, pair := range v {
+			appendChild(i, pair.Key(), pair.Value())
+			if err != nil {
+				break
+			}
+			i++
 		}
+	}
 
-		return composition, err
+	if err == nil {
+		out = append(out, val.endSymbol...)
 	}
+
+	return out, err
 }
 
-// Append will add the appropriate children. Note that a map must have 2 parameters.
+// Append will add the appropriate children. Note that a map must have 2 parameters. Children that are a Marshaler are
+// added as the element they marshal to. A key already in a map has its value replaced, and ErrDuplicateMember is
+// returned for a member already in a set. ErrFrozen is returned if the collection is frozen.
 func (elem *collectionElemImpl) Append(children ...Element) (err error) {
 
-	if len(children) != 0 {
+	if elem.frozen {
+		err = ErrFrozen
+	} else if children, err = marshalChildren(children); err == nil && len(children) != 0 {
 		switch v := elem.collection.(type) {
 		case []Element:
-			elem.collection = append(v, children...)
-		case map[string]Element:
+			if elem.elemType == SetType {
+				err = checkMembers(v, children)
+			}
+
+			if err == nil {
+				elem.collection = append(v, children...)
+			}
+		case map[string]Pair:
 
 			if len(children)%2 == 0 {
 				for i := 0; i < len(children); i += 2 {
-					var k string
-					if str, is := children[i].(*baseElemImpl); is && str.elemType == StringType {
-						k = str.value.(string)
-					} else {
-						k, err = children[i].Serialize()
-					}
-					if err == nil {
-						v[k] = children[i+1]
+					v[mapKey(children[i])] = &pairImpl{
+						key:   children[i],
+						value: children[i+1],
 					}
 				}
 			} else {
@@ -160,42 +183,185 @@ func (elem *collectionElemImpl) Append(children ...Element) (err error) {
 	return err
 }
 
-// Get the value from the collection.
+// Get the value from the collection. Sequences take an index. Maps take an element, or a string that is taken as a
+// string key first and then read as EDN, so that both "name" and ":person/name" find their keys.
 func (elem *collectionElemImpl) Get(key interface{}) (value Element, err error) {
 
-	var realKey string
+	switch v := elem.collection.(type) {
+	case []Element:
+		var index int
+		if index, err = collectionIndex(key); err == nil {
+			if index >= 0 && index < len(v) {
+				value = v[index]
+			} else {
+				err = ErrNoValue
+			}
+		}
+	case map[string]Pair:
+		var keyElem Element
+		switch k := key.(type) {
+		case int, int32, int64:
+			keyElem, err = NewIntegerElement(reflect.ValueOf(k).Int())
+		case string:
+			if keyElem, err = NewStringElement(k); err == nil && !elem.hasKey(keyElem) {
+				if parsed, e := Parse([]byte(k)); e == nil {
+					keyElem = parsed
+				}
+			}
+		case Element:
+			keyElem = k
+		default:
+			err = ErrInvalidInput
+		}
 
+		if err == nil {
+			if pair, has := v[mapKey(keyElem)]; has {
+				value = pair.Value()
+			} else {
+				err = ErrNoValue
+			}
+		}
+	default:
+		err = ErrInvalidElement
+	}
+
+	return value, err
+}
+
+// collectionIndex returns the index of a sequence the key stands for.
+func collectionIndex(key interface{}) (index int, err error) {
+	text := ""
 	switch k := key.(type) {
 	case int, int32, int64:
-		realKey = fmt.Sprintf("%d", k)
+		text = strconv.FormatInt(reflect.ValueOf(k).Int(), 10)
 	case string:
-		realKey = k
+		text = k
 	case Element:
-		realKey, err = k.Serialize()
+		text, err = k.Serialize()
 	default:
 		err = ErrInvalidInput
 	}
 
 	if err == nil {
-		switch v := elem.collection.(type) {
-		case []Element:
-			var index int
+		if index, err = strconv.Atoi(text); err != nil {
 			err = ErrNoValue
-			if index, err = strconv.Atoi(realKey); err == nil {
-				if index >= 0 && index < len(v) {
-					value = v[index]
-					err = nil
+		}
+	}
+
+	return index, err
+}
+
+// collectionEquality checks that the collections hold equal children. Sequences are compared in order, maps by key and
+// sets by membership. Map keys are matched by their equality group, so keys that are sets or maps match whatever the
+// order of their own children.
+func collectionEquality(left, right Element) (result bool) {
+	leftColl, isLeft := left.Value().(*collectionElemImpl)
+	rightColl, isRight := right.Value().(*collectionElemImpl)
+
+	if isLeft && isRight && leftColl.Len() == rightColl.Len() {
+		switch l := leftColl.collection.(type) {
+		case []Element:
+			if r, is := rightColl.collection.([]Element); is {
+				result = true
+				if leftColl.ElementType() == SetType {
+					for i := 0; result && i < len(l); i++ {
+						result = false
+						for j := 0; !result && j < len(r); j++ {
+							result = l[i].Equals(r[j])
+						}
+					}
+				} else {
+					for i := 0; result && i < len(l); i++ {
+						result = l[i].Equals(r[i])
+					}
 				}
 			}
-		case map[string]Element:
-			var has bool
-			if value, has = v[realKey]; !has {
-				err = ErrNoValue
+		case map[string]Pair:
+			if r, is := rightColl.collection.(map[string]Pair); is {
+				result = true
+				for k, leftPair := range l {
+					rightPair, has := r[k]
+					if result = has && leftPair.Key().Equals(rightPair.Key()) && leftPair.Value().Equals(rightPair.Value()); !result {
+						break
+					}
+				}
 			}
-		default:
-			err = ErrInvalidElement
 		}
 	}
 
-	return value, err
+	return result
+}
+
+// mapKey returns the string a map stores the key under, its equality group.
+func mapKey(key Element) string {
+	return equalityGroup(key)
+}
+
+// checkMembers returns ErrDuplicateMember if a child equals a member of the set or another child.
+func checkMembers(members []Element, children []Element) (err error) {
+	seen := make(map[string]bool, len(members)+len(children))
+	for _, member := range members {
+		seen[equalityGroup(member)] = true
+	}
+
+	for _, child := range children {
+		group := equalityGroup(child)
+		if seen[group] {
+			err = ErrDuplicateMember
+			break
+		}
+		seen[group] = true
+	}
+
+	return err
+}
+
+// collectionChildren returns the children of the collection. The children of a map alternate between keys and values.
+func collectionChildren(coll CollectionElement) (children []Element, err error) {
+	isMap := coll.ElementType() == MapType
+	err = coll.IterateChildren(func(key Element, child Element) error {
+		if isMap {
+			children = append(children, key)
+		}
+		children = append(children, child)
+		return nil
+	})
+
+	return children, err
+}
+
+// rebuildCollection creates a new collection of the same type and tag as the original holding the children. The
+// children of a map alternate between keys and values.
+func rebuildCollection(coll CollectionElement, children []Element) (rebuilt CollectionElement, err error) {
+	switch coll.ElementType() {
+	case GroupingType:
+		rebuilt, err = NewGroup(children...)
+	case VectorType:
+		rebuilt, err = NewVector(children...)
+	case SetType:
+		rebuilt, err = NewSet(children...)
+	case MapType:
+		if len(children)%2 == 0 {
+			pairs := make([]Pair, 0, len(children)/2)
+			for i := 0; err == nil && i < len(children); i += 2 {
+				var pair Pair
+				if pair, err = NewPair(children[i], children[i+1]); err == nil {
+					pairs = append(pairs, pair)
+				}
+			}
+			if err == nil {
+				rebuilt, err = NewMap(pairs...)
+			}
+		} else {
+			err = ErrInvalidInput
+		}
+	default:
+		err = ErrInvalidElement
+	}
+
+	if err == nil && coll.HasTag() {
+		err = rebuilt.SetTag(coll.Tag())
+	}
+
+	return rebuilt, err
 }
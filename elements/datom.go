@@ -1,5 +1,10 @@
 package elements
 
+import (
+	"strconv"
+	"strings"
+)
+
 const (
 
 	// DatomTag defines the tag for the datom structure.
@@ -13,6 +18,9 @@ type Datom interface {
 	// Serializer mixin
 	Serializer
 
+	// Appender mixin
+	Appender
+
 	// EntityId for this datom.
 	EntityId() int64
 
@@ -66,36 +74,49 @@ func NewDatom(entityId int64, attributeId int64, value interface{}, transaction
 
 // Serialize the element into a string or return the appropriate error.
 func (datom *datomImpl) Serialize() (composition string, err error) {
+	var out []byte
+	if out, err = datom.AppendEDN(nil); err == nil {
+		composition = string(out)
+	}
+	return composition, err
+}
 
-	var internal CollectionElement
+// AppendEDN appends the datom as a tagged vector, e.g. #datom [17592186045422 63 "The Goonies" 13194139534317 true].
+func (datom *datomImpl) AppendEDN(dst []byte) (out []byte, err error) {
 
-	if internal, err = NewVector(); err == nil {
-		if err = internal.SetTag(DatomTag); err == nil {
-			var eid, aid, v, t, a Element
+	out = append(append(append(dst, TagPrefix...), DatomTag...), ' ', '[')
+	out = append(strconv.AppendInt(out, datom.entityId, 10), ' ')
+	out = append(strconv.AppendInt(out, datom.attributeId, 10), ' ')
 
-			eid, err = NewIntegerElement(datom.entityId)
-			if err == nil {
-				aid, err = NewIntegerElement(datom.attributeId)
-			}
-			if err == nil {
-				v, err = NewElement(datom.value)
-			}
-			if err == nil {
-				t, err = NewIntegerElement(int64(datom.transaction))
-			}
-			if err == nil {
-				a, err = NewBooleanElement(datom.added)
-			}
+	if out, err = appendDatomValue(out, datom.value); err == nil {
+		out = append(strconv.AppendInt(append(out, ' '), int64(datom.transaction), 10), ' ')
+		out = append(strconv.AppendBool(out, datom.added), ']')
+	}
 
-			if err == nil {
-				if err = internal.Append(eid, aid, v, t, a); err == nil {
-					composition, err = internal.Serialize()
-				}
+	return out, err
+}
+
+// appendDatomValue appends the value of a datom. Integers, elements and plain strings are appended as they are, any
+// other value is turned into an element the way NewElement reads it.
+func appendDatomValue(dst []byte, value interface{}) (out []byte, err error) {
+
+	if str, is := value.(string); is && len(str) > 0 && str != NilLiteral && !strings.HasPrefix(str, KeywordPrefix) {
+		out = strconv.AppendQuote(dst, str)
+	} else {
+		switch v := value.(type) {
+		case int64:
+			out = strconv.AppendInt(dst, v, 10)
+		case Element:
+			out, err = v.AppendEDN(dst)
+		default:
+			var elem Element
+			if elem, err = NewElement(v); err == nil {
+				out, err = elem.AppendEDN(dst)
 			}
 		}
 	}
 
-	return composition, err
+	return out, err
 }
 
 // EntityId for this datom.
@@ -0,0 +1,299 @@
+package elements
+
+import (
+	"fmt"
+)
+
+const (
+
+	// AddedChange marks an element that is only in the second tree.
+	AddedChange ChangeType = "added"
+
+	// RemovedChange marks an element that is only in the first tree.
+	RemovedChange ChangeType = "removed"
+
+	// ChangedChange marks an element that is in both trees with different values.
+	ChangedChange ChangeType = "changed"
+
+	// ErrPatchConflict defines the error for a change that does not match the element it is applied to.
+	ErrPatchConflict = Error("Patch does not match the element")
+)
+
+// ChangeType describes the kind of difference.
+type ChangeType string
+
+// Change is a single difference between two element trees.
+type Change struct {
+
+	// Type of the change.
+	Type ChangeType
+
+	// Path to the element that changed. The segments are map keys, integer indexes for lists and vectors, and the
+	// member itself for sets.
+	Path []Element
+
+	// Old holds the element from the first tree, nil when added.
+	Old Element
+
+	// New holds the element from the second tree, nil when removed.
+	New Element
+}
+
+// String returns the change as the type, the path and the values.
+func (change Change) String() string {
+	path := make([]interface{}, len(change.Path))
+	for i, segment := range change.Path {
+		path[i] = segment
+	}
+
+	out := fmt.Sprintf("%s %s", change.Type, formatPath(path))
+	switch change.Type {
+	case AddedChange:
+		out += " " + formatValue(change.New)
+	case RemovedChange:
+		out += " " + formatValue(change.Old)
+	default:
+		out += " " + formatValue(change.Old) + " -> " + formatValue(change.New)
+	}
+
+	return out
+}
+
+// Diff returns the changes that turn the first element into the second. Maps are compared by key, sets by membership
+// and lists and vectors by index. Collections of different types or tags are reported as a single change. Applying
+// the changes in order with Patch yields an element equal to the second.
+func Diff(a, b Element) (changes []Change) {
+	return diff(nil, a, b, changes)
+}
+
+// diff appends the changes between the elements found at the path.
+func diff(path []Element, a, b Element, changes []Change) []Change {
+
+	aColl, aIs := a.(CollectionElement)
+	bColl, bIs := b.(CollectionElement)
+
+	switch {
+	case a.Equals(b):
+		// nothing changed.
+
+	case aIs && bIs && a.ElementType() == b.ElementType() && a.Tag() == b.Tag():
+		switch a.ElementType() {
+		case MapType:
+			changes = diffMaps(path, aColl, bColl, changes)
+		case SetType:
+			changes = diffSets(path, aColl, bColl, changes)
+		default:
+			changes = diffSequences(path, aColl, bColl, changes)
+		}
+
+	default:
+		changes = append(changes, Change{Type: ChangedChange, Path: path, Old: a, New: b})
+	}
+
+	return changes
+}
+
+// diffMaps compares the entries of the maps by key, found with Get so that equal keys match whatever their order.
+func diffMaps(path []Element, a, b CollectionElement, changes []Change) []Change {
+	aChildren, _ := collectionChildren(a)
+	bChildren, _ := collectionChildren(b)
+
+	for i := 0; i < len(aChildren); i += 2 {
+		key := aChildren[i]
+		if value, err := b.Get(key); err == nil {
+			changes = diff(childPath(path, key), aChildren[i+1], value, changes)
+		} else {
+			changes = append(changes, Change{Type: RemovedChange, Path: childPath(path, key), Old: aChildren[i+1]})
+		}
+	}
+
+	for i := 0; i < len(bChildren); i += 2 {
+		key := bChildren[i]
+		if _, err := a.Get(key); err != nil {
+			changes = append(changes, Change{Type: AddedChange, Path: childPath(path, key), New: bChildren[i+1]})
+		}
+	}
+
+	return changes
+}
+
+// diffSets compares the sets by membership.
+func diffSets(path []Element, a, b CollectionElement, changes []Change) []Change {
+	aMembers, _ := collectionChildren(a)
+	bMembers, _ := collectionChildren(b)
+
+	for _, member := range aMembers {
+		if !containsElement(bMembers, member) {
+			changes = append(changes, Change{Type: RemovedChange, Path: childPath(path, member), Old: member})
+		}
+	}
+
+	for _, member := range bMembers {
+		if !containsElement(aMembers, member) {
+			changes = append(changes, Change{Type: AddedChange, Path: childPath(path, member), New: member})
+		}
+	}
+
+	return changes
+}
+
+// diffSequences compares lists and vectors by index. Extra elements of the first are removed from the end backwards
+// so that each removal leaves the earlier indexes in place.
+func diffSequences(path []Element, a, b CollectionElement, changes []Change) []Change {
+	aChildren, _ := collectionChildren(a)
+	bChildren, _ := collectionChildren(b)
+
+	common := len(aChildren)
+	if len(bChildren) < common {
+		common = len(bChildren)
+	}
+
+	for i := 0; i < common; i++ {
+		index, _ := NewIntegerElement(int64(i))
+		changes = diff(childPath(path, index), aChildren[i], bChildren[i], changes)
+	}
+
+	for i := len(aChildren) - 1; i >= common; i-- {
+		index, _ := NewIntegerElement(int64(i))
+		changes = append(changes, Change{Type: RemovedChange, Path: childPath(path, index), Old: aChildren[i]})
+	}
+
+	for i := common; i < len(bChildren); i++ {
+		index, _ := NewIntegerElement(int64(i))
+		changes = append(changes, Change{Type: AddedChange, Path: childPath(path, index), New: bChildren[i]})
+	}
+
+	return changes
+}
+
+// containsElement is true if an equal element is in the collection.
+func containsElement(collection []Element, elem Element) bool {
+	for _, c := range collection {
+		if c.Equals(elem) {
+			return true
+		}
+	}
+	return false
+}
+
+// Patch applies the changes in order and returns the patched element, the original is not modified. Each change must
+// match the element it is applied to: removed and changed values must equal the Old value and added values must not
+// already be present, otherwise an error wrapping ErrPatchConflict is returned.
+func Patch(elem Element, changes []Change) (result Element, err error) {
+	result = elem
+	for i := 0; err == nil && i < len(changes); i++ {
+		if result, err = applyChange(result, changes[i]); err != nil {
+			err = &ElementError{Path: elementPath(changes[i].Path), Err: err}
+		}
+	}
+
+	return result, err
+}
+
+// elementPath converts the change path to the generic path form.
+func elementPath(path []Element) []interface{} {
+	generic := make([]interface{}, len(path))
+	for i, segment := range path {
+		generic[i] = segment
+	}
+	return generic
+}
+
+// applyChange applies a single change to the element.
+func applyChange(elem Element, change Change) (result Element, err error) {
+
+	if len(change.Path) == 0 {
+		if change.Type == ChangedChange && elem.Equals(change.Old) {
+			result = change.New
+		} else {
+			err = ErrPatchConflict
+		}
+	} else {
+		parentPath := elementPath(change.Path[:len(change.Path)-1])
+		last := change.Path[len(change.Path)-1]
+
+		result, err = UpdateIn(elem, func(parent Element) (updated Element, e error) {
+			if coll, is := parent.(CollectionElement); is {
+				updated, e = patchChild(coll, last, change)
+			} else {
+				e = ErrWrongCollection
+			}
+			return updated, e
+		}, parentPath...)
+	}
+
+	return result, err
+}
+
+// patchChild returns a copy of the collection with the change applied to the child at the segment.
+func patchChild(coll CollectionElement, segment Element, change Change) (result Element, err error) {
+
+	var children []Element
+	if children, err = collectionChildren(coll); err == nil {
+
+		switch coll.ElementType() {
+		case MapType:
+			found := -1
+			for i := 0; i < len(children); i += 2 {
+				if children[i].Equals(segment) {
+					found = i
+					break
+				}
+			}
+
+			switch {
+			case change.Type == AddedChange && found < 0:
+				children = append(children, segment, change.New)
+			case change.Type != AddedChange && found >= 0 && children[found+1].Equals(change.Old):
+				if change.Type == ChangedChange {
+					children[found+1] = change.New
+				} else {
+					children = append(children[:found], children[found+2:]...)
+				}
+			default:
+				err = ErrPatchConflict
+			}
+
+		case SetType:
+			found := -1
+			for i, member := range children {
+				if member.Equals(segment) {
+					found = i
+					break
+				}
+			}
+
+			switch {
+			case change.Type == AddedChange && found < 0:
+				children = append(children, change.New)
+			case change.Type == RemovedChange && found >= 0:
+				children = append(children[:found], children[found+1:]...)
+			default:
+				err = ErrPatchConflict
+			}
+
+		default:
+			index, ok := segmentIndex(segment)
+			switch {
+			case !ok:
+				err = ErrInvalidKey
+			case change.Type == AddedChange && index >= 0 && index <= len(children):
+				children = append(children[:index], append([]Element{change.New}, children[index:]...)...)
+			case change.Type != AddedChange && index >= 0 && index < len(children) && children[index].Equals(change.Old):
+				if change.Type == ChangedChange {
+					children[index] = change.New
+				} else {
+					children = append(children[:index], children[index+1:]...)
+				}
+			default:
+				err = ErrPatchConflict
+			}
+		}
+	}
+
+	if err == nil {
+		result, err = rebuildCollection(coll, children)
+	}
+
+	return result, err
+}
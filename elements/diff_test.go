@@ -0,0 +1,120 @@
+package elements
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Diff and Patch", func() {
+
+	parse := func(src string) Element {
+		elem, err := Parse([]byte(src))
+		Ω(err).Should(BeNil(), src)
+		return elem
+	}
+
+	descriptions := func(changes []Change) []string {
+		out := []string{}
+		for _, change := range changes {
+			out = append(out, change.String())
+		}
+		return out
+	}
+
+	It("should compare collections by value", func() {
+		Ω(parse(`{:a [1 2] :b #{:x :y}}`).Equals(parse(`{:b #{:y :x} :a [1 2]}`))).Should(BeTrue())
+		Ω(parse(`[1 2]`).Equals(parse(`(1 2)`))).Should(BeFalse())
+		Ω(parse(`[1 2]`).Equals(parse(`[2 1]`))).Should(BeFalse())
+		Ω(parse(`{:a 1}`).Equals(parse(`{:a 2}`))).Should(BeFalse())
+	})
+
+	It("should match map keys that are sets or maps whatever their order", func() {
+		for i := 0; i < 50; i++ {
+			for _, test := range [][]string{
+				{`{#{1 2 3 4 5} 1}`, `{#{5 4 3 2 1} 1}`, `#{3 1 2 5 4}`},
+				{`{{:a 1 :b 2 :c 3 :d 4} 1}`, `{{:d 4 :c 3 :b 2 :a 1} 1}`, `{:b 2 :a 1 :d 4 :c 3}`},
+				{`{[#{1 2} {:a #{3 4}}] 1}`, `{[#{2 1} {:a #{4 3}}] 1}`, `[#{1 2} {:a #{4 3}}]`},
+			} {
+				a, b := parse(test[0]), parse(test[1])
+				Ω(a.Equals(b)).Should(BeTrue(), test[0])
+				Ω(Diff(a, b)).Should(BeEmpty(), test[0])
+
+				value, err := a.(CollectionElement).Get(parse(test[2]))
+				Ω(err).Should(BeNil(), test[0])
+				Ω(value.Equals(parse(`1`))).Should(BeTrue())
+			}
+		}
+
+		Ω(Diff(parse(`{#{1 2} 1}`), parse(`{#{2 1} 2}`))).Should(HaveLen(1))
+	})
+
+	It("should report nothing for equal elements", func() {
+		Ω(Diff(parse(`{:a [1 {:b 2}]}`), parse(`{:a [1 {:b 2}]}`))).Should(BeEmpty())
+	})
+
+	It("should diff maps by key, sets by membership and vectors by index", func() {
+		a := parse(`{:db/ident :person/name :db/doc "old" :tags #{:a :b} :items [1 2 3] :gone true}`)
+		b := parse(`{:db/ident :person/name :db/doc "new" :tags #{:b :c} :items [1 5] :added nil}`)
+
+		Ω(descriptions(Diff(a, b))).Should(ConsistOf(
+			`changed [:db/doc] "old" -> "new"`,
+			`removed [:tags :a] :a`,
+			`added [:tags :c] :c`,
+			`changed [:items 1] 2 -> 5`,
+			`removed [:items 2] 3`,
+			`removed [:gone] true`,
+			`added [:added] nil`,
+		))
+	})
+
+	It("should report a replaced collection as a single change", func() {
+		changes := Diff(parse(`{:a [1]}`), parse(`{:a #{1}}`))
+		Ω(changes).Should(HaveLen(1))
+		Ω(changes[0].Type).Should(BeEquivalentTo(ChangedChange))
+		Ω(changes[0].String()).Should(BeEquivalentTo(`changed [:a] [1] -> #{1}`))
+	})
+
+	It("should patch the first element into the second", func() {
+		pairs := [][2]string{
+			{`{:a 1 :b [1 2 3] :c #{1 2}}`, `{:a 2 :b [1] :c #{2 3} :d {:e 1}}`},
+			{`[1 [2 3] {:x 1}]`, `[1 [2 3 4 5] {:x 2} :more]`},
+			{`(1 2 3)`, `()`},
+			{`:a`, `"b"`},
+		}
+
+		for _, pair := range pairs {
+			a, b := parse(pair[0]), parse(pair[1])
+			patched, err := Patch(a, Diff(a, b))
+			Ω(err).Should(BeNil(), pair[0])
+			Ω(patched.Equals(b)).Should(BeTrue(), pair[0])
+
+			// the original is left alone.
+			Ω(a.Equals(parse(pair[0]))).Should(BeTrue(), pair[0])
+		}
+	})
+
+	It("should keep the collection tags", func() {
+		a, b := parse(`#my/tag {:a 1}`), parse(`#my/tag {:a 2}`)
+		patched, err := Patch(a, Diff(a, b))
+		Ω(err).Should(BeNil())
+		Ω(patched.Tag()).Should(BeEquivalentTo("my/tag"))
+	})
+
+	It("should refuse changes that do not match", func() {
+		changes := Diff(parse(`{:a 1}`), parse(`{:a 2}`))
+
+		_, err := Patch(parse(`{:a 3}`), changes)
+		Ω(errors.Is(err, ErrPatchConflict)).Should(BeTrue())
+
+		_, err = Patch(parse(`{:b 1}`), changes)
+		Ω(errors.Is(err, ErrPatchConflict)).Should(BeTrue())
+
+		_, err = Patch(parse(`#{1}`), Diff(parse(`#{}`), parse(`#{1}`)))
+		Ω(errors.Is(err, ErrPatchConflict)).Should(BeTrue())
+
+		_, err = Patch(parse(`[1]`), Diff(parse(`[1 2]`), parse(`[1]`)))
+		Ω(errors.Is(err, ErrPatchConflict)).Should(BeTrue())
+	})
+})
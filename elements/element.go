@@ -1,6 +1,7 @@
 package elements
 
 import (
+	"math/big"
 	"reflect"
 	"time"
 
@@ -12,6 +13,9 @@ const (
 	// InvalidElement defines an invalid element was encountered.
 	ErrInvalidElement = Error("Invalid Element")
 
+	// ErrUnknownType defines the error for a value that no element type can hold.
+	ErrUnknownType = Error("Unknown type")
+
 	// TagPrefix defines the prefix for tags.
 	TagPrefix = "#"
 )
@@ -22,6 +26,12 @@ type Element interface {
 	// Serializer mixin
 	Serializer
 
+	// Appender mixin
+	Appender
+
+	// Accessor mixin
+	Accessor
+
 	// ElementType returns the current type of this element.
 	ElementType() ElementType
 
@@ -39,12 +49,27 @@ type Element interface {
 
 	// Equals checks if the input element is equal to this element.
 	Equals(e Element) (result bool)
+
+	// Span returns the region of source this element was parsed from. Constructed elements return the zero span.
+	Span() Span
+}
+
+// spanner is implemented by elements that can record where they were parsed from.
+type spanner interface {
+	setSpan(span Span)
 }
 
 // NewElement creates a new element from the inputs. I f the first parameter is a ElementType, then that will stereotype
 // the rest of the values. For more then 2 inputs, a collection will be assumed and if they set a non collection
-// ElementType is a non collection, then this will error.
+// ElementType is a non collection, then this will error. A single Element is returned as it is, and a Marshaler, Builder
+// or CollectionBuilder creates its own element. The factories of the default registry are used.
 func NewElement(value ...interface{}) (elem Element, err error) {
+	return defaultRegistry.NewElement(value...)
+}
+
+// NewElement creates a new element from the inputs like the package level NewElement, using the factories of this
+// registry.
+func (registry *Registry) NewElement(value ...interface{}) (elem Element, err error) {
 
 	var stereotype ElementType
 
@@ -52,8 +77,11 @@ func NewElement(value ...interface{}) (elem Element, err error) {
 	case len(value) == 0:
 		elem, err = NewNilElement()
 	case len(value) == 1:
-		if e, ok := value[0].(Element); ok {
-			elem = e
+		var is bool
+		if elem, is, err = customElement(value[0]); is && err != nil {
+			if _, isElemErr := err.(*ElementError); !isElemErr {
+				err = &ElementError{Value: value[0], Err: err}
+			}
 		}
 	case len(value) <= 2:
 		switch v := value[0].(type) {
@@ -86,13 +114,17 @@ func NewElement(value ...interface{}) (elem Element, err error) {
 					val = float64(v)
 				case float64:
 					stereotype = FloatType
+				case *big.Int:
+					stereotype = BigIntType
+				case *big.Float:
+					stereotype = BigDecType
 				case string:
 					if v == NilLiteral {
 						stereotype = NilType
 						val = nil
 					} else {
 						stereotype = StringType
-						if v[0] == ':' {
+						if len(v) > 0 && v[0] == ':' {
 							stereotype = KeywordType
 						}
 					}
@@ -103,10 +135,14 @@ func NewElement(value ...interface{}) (elem Element, err error) {
 				}
 			}
 
-			if factory, has := typeFactories[stereotype]; has {
+			if factory, has := registry.Factory(stereotype); has {
 				elem, err = factory(val)
 			} else {
-				err = AppendError(ErrInvalidElement, NewError("Unknown type", stereotype.Name()))
+				err = &ElementError{
+					Type:  stereotype,
+					Value: val,
+					Err:   NewError("%w: %w", ErrInvalidElement, ErrUnknownType),
+				}
 			}
 
 		default:
@@ -117,8 +153,8 @@ func NewElement(value ...interface{}) (elem Element, err error) {
 	return elem, err
 }
 
-// elemStringer defines the mechanism to stringify the element.
-type elemStringer func(interface{}) (string, error)
+// elemAppender defines the mechanism to append the serialized value of the element to a buffer.
+type elemAppender func(dst []byte, value interface{}) ([]byte, error)
 
 // elemEqualityChecker defines the mechanism testing equality
 type elemEqualityChecker func(left, right Element) bool
@@ -129,8 +165,8 @@ type baseElemImpl struct {
 	// elemType is the type this element houses.
 	elemType ElementType
 
-	// stringer is the mechanism to serialize this element.
-	stringer elemStringer
+	// appender is the mechanism to serialize this element.
+	appender elemAppender
 
 	// equality is the tester for equality
 	equality elemEqualityChecker
@@ -140,15 +176,24 @@ type baseElemImpl struct {
 
 	// value of this element.
 	value interface{}
+
+	// span of source this element was parsed from.
+	span Span
+
+	// frozen elements can not be changed.
+	frozen bool
+
+	// outer is the element that embeds this base, nil if the base is the element itself.
+	outer Element
 }
 
 // makeBaseElement creates the base element.
-func makeBaseElement(value interface{}, elementType ElementType, stringer elemStringer) (elem *baseElemImpl, err error) {
+func makeBaseElement(value interface{}, elementType ElementType, appender elemAppender) (elem *baseElemImpl, err error) {
 
-	if stringer != nil {
+	if appender != nil {
 		elem = &baseElemImpl{
 			elemType: elementType,
-			stringer: stringer,
+			appender: appender,
 			value:    value,
 			equality: func(left, right Element) (result bool) {
 				return reflect.DeepEqual(left.Value(), right.Value())
@@ -161,13 +206,28 @@ func makeBaseElement(value interface{}, elementType ElementType, stringer elemSt
 	return elem, err
 }
 
-// Equals checks if the input element is equal to this element.
+// Equals checks if the input element is equal to this element. An element read under a tag that is not built in, e.g.
+// #my/tag [1], equals the same element given the tag with SetTag.
 func (elem *baseElemImpl) Equals(e Element) (result bool) {
-	if elem.ElementType() == e.ElementType() {
-		if elem.Tag() == e.Tag() {
-			result = elem.equality(elem, e)
+	return equals(elem, elem.equality, e)
+}
+
+// equals compares the element with the other using its equality. Both must have the same type and tag, except that a
+// tagged element on either side is looked through when it holds an untagged element of the type of the other side.
+func equals(elem Element, equality elemEqualityChecker, e Element) (result bool) {
+	switch {
+	case elem.Tag() != e.Tag():
+	case elem.ElementType() == e.ElementType():
+		result = equality(elem, e)
+	case elem.ElementType() == TaggedType:
+		result = e.Equals(elem)
+	case e.ElementType() == TaggedType:
+		// the value of a tagged element is the element under the tag.
+		if inner, is := e.Value().(Element); is {
+			result = !inner.HasTag() && inner.ElementType() == elem.ElementType() && equality(elem, inner)
 		}
 	}
+
 	return result
 }
 
@@ -178,17 +238,24 @@ func (elem *baseElemImpl) ElementType() ElementType {
 
 // Serialize the element into a string or return the appropriate error.
 func (elem *baseElemImpl) Serialize() (composition string, err error) {
+	var out []byte
+	if out, err = elem.AppendEDN(nil); err == nil {
+		composition = string(out)
+	}
+	return composition, err
+}
+
+// AppendEDN appends the serialized element to dst and returns the extended buffer, or the appropriate error.
+func (elem *baseElemImpl) AppendEDN(dst []byte) (out []byte, err error) {
 
 	// If the tag exists then prefix the value with the tag.
+	out = dst
 	if elem.HasTag() {
-		composition = TagPrefix + elem.Tag() + " "
+		out = append(append(append(out, TagPrefix...), elem.tag...), ' ')
 	}
 
-	var comp string
-	if comp, err = elem.stringer(elem.Value()); err == nil {
-		composition += comp
-	}
-	return composition, err
+	out, err = elem.appender(out, elem.Value())
+	return out, err
 }
 
 // HasTag returns true if the element has a tag prefix
@@ -234,18 +301,33 @@ func (elem *baseElemImpl) Tag() string {
 // A tag may specify more than one format for the tagged element, e.g. both a string and a vector representation.
 // Tags themselves are not elements. It is an error to have a tag without a corresponding tagged element.
 
-// SetTag sets the tag to the incoming value. If the value is an empty string then the tag is unset.
+// SetTag sets the tag to the incoming value. If the value is an empty string then the tag is unset. ErrFrozen is returned
+// if the element is frozen.
 func (elem *baseElemImpl) SetTag(value string) (err error) {
 
 	// TODO: check if the first is # and remove
 	// TODO: make sure tag is not ws or ,
 	// TODO: Follow the rules above
 
-	elem.tag = value
+	if elem.frozen {
+		err = ErrFrozen
+	} else {
+		elem.tag = value
+	}
 
 	return err
 }
 
+// Span returns the region of source this element was parsed from. Constructed elements return the zero span.
+func (elem *baseElemImpl) Span() Span {
+	return elem.span
+}
+
+// setSpan records the region of source this element was parsed from.
+func (elem *baseElemImpl) setSpan(span Span) {
+	elem.span = span
+}
+
 // Value return the raw representation of this element.
 func (elem *baseElemImpl) Value() interface{} {
 	return elem.value
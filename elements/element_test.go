@@ -13,8 +13,8 @@ var _ = Describe("Elements in EDN", func() {
 
 			t := ElementType(99)
 
-			elem, err := makeBaseElement(nil, t, func(i interface{}) (string, error) {
-				return "", nil
+			elem, err := makeBaseElement(nil, t, func(dst []byte, i interface{}) ([]byte, error) {
+				return dst, nil
 			})
 			Ω(err).Should(BeNil())
 			Ω(elem).ShouldNot(BeNil())
@@ -35,16 +35,16 @@ var _ = Describe("Elements in EDN", func() {
 
 			value := "42"
 
-			elem, err := makeBaseElement(value, StringType, func(value interface{}) (out string, e error) {
-				out = strconv.Quote(value.(string))
+			elem, err := makeBaseElement(value, StringType, func(dst []byte, value interface{}) (out []byte, e error) {
+				out = strconv.AppendQuote(dst, value.(string))
 				return out, e
 			})
 			Ω(err).Should(BeNil())
 			Ω(elem).ShouldNot(BeNil())
 			Ω(elem.Value()).Should(BeEquivalentTo(value))
 
-			elem2, err := makeBaseElement(value, StringType, func(value interface{}) (out string, e error) {
-				out = strconv.Quote(value.(string))
+			elem2, err := makeBaseElement(value, StringType, func(dst []byte, value interface{}) (out []byte, e error) {
+				out = strconv.AppendQuote(dst, value.(string))
 				return out, e
 			})
 			Ω(err).Should(BeNil())
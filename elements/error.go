@@ -2,6 +2,7 @@ package elements
 
 import (
 	"fmt"
+	"strings"
 )
 
 // Error is the error type.
@@ -12,7 +13,8 @@ func (e Error) Error() string {
 	return string(e)
 }
 
-// FormatError is an error that creates a unique message from the state at the time of creation.
+// FormatError is an error that creates a unique message from the state at the time of creation. Any errors within the
+// items are exposed through Unwrap, and the message may use %w to include them.
 type FormatError struct {
 	message string
 	items   []interface{}
@@ -20,7 +22,17 @@ type FormatError struct {
 
 // Error returns the error message.
 func (e FormatError) Error() string {
-	return fmt.Sprintf(e.message, e.items...)
+	return fmt.Errorf(e.message, e.items...).Error()
+}
+
+// Unwrap returns the errors held within the items.
+func (e FormatError) Unwrap() (errs []error) {
+	for _, item := range e.items {
+		if err, is := item.(error); is {
+			errs = append(errs, err)
+		}
+	}
+	return errs
 }
 
 // CumulativeError defines a collection of errors.
@@ -57,6 +69,11 @@ func (cumErr *CumulativeError) ErrorList() []error {
 	return cumErr.items
 }
 
+// Unwrap returns the error collection so errors.Is and errors.As search every error within.
+func (cumErr *CumulativeError) Unwrap() []error {
+	return cumErr.items
+}
+
 // NewError creates a new error.
 func NewError(message string, contents ...interface{}) (err error) {
 
@@ -72,36 +89,132 @@ func NewError(message string, contents ...interface{}) (err error) {
 	return err
 }
 
-// AppendError will take the original error and append the new one.
-func AppendError(errors ...error) (err error) {
-	switch len(errors) {
+// AppendError combines the errors, ignoring any that are nil. No error yields nil, a single error is returned as is and
+// more then one yields a new CumulativeError. The inputs are never modified.
+func AppendError(errs ...error) (err error) {
 
-	// handle the simple cases where one or both are nil.
+	cumErr := &CumulativeError{}
+	cumErr.Append(errs...)
+
+	switch len(cumErr.items) {
 	case 0:
-		break
+		// nothing to report.
 	case 1:
-		err = errors[0]
-
+		err = cumErr.items[0]
 	default:
-		for i := 0; i < len(errors); i++ {
-			if errors[i] != nil {
-				switch v := errors[i].(type) {
-				case *CumulativeError:
-					v.Append(errors[i+1:]...)
-					err = v
-				default:
-					if len(errors)-i > 1 {
-						cumErr := &CumulativeError{}
-						cumErr.Append(errors[i:]...)
-						err = cumErr
-					} else {
-						err = errors[i]
-					}
-				}
-				break
+		err = cumErr
+	}
+
+	return err
+}
+
+// ElementError defines an error about a specific element, with the context needed to find and explain it. Use
+// errors.As to read the context, and errors.Is to test the underlying reason.
+type ElementError struct {
+
+	// Path holds the keys and indexes from the root to the element, it is empty if not known.
+	Path []interface{}
+
+	// Type of the element, or the type that was being created.
+	Type ElementType
+
+	// Value that caused the error.
+	Value interface{}
+
+	// Err is the underlying reason.
+	Err error
+}
+
+// Error returns the error message.
+func (e *ElementError) Error() string {
+	message := ""
+	if len(e.Path) > 0 {
+		message = "at " + formatPath(e.Path) + ": "
+	}
+
+	message += fmt.Sprint(e.Err)
+
+	if e.Type != UnknownType {
+		message += fmt.Sprintf(" (type %s, value %s)", e.Type, formatValue(e.Value))
+	} else if e.Value != nil {
+		message += fmt.Sprintf(" (value %s)", formatValue(e.Value))
+	}
+
+	return message
+}
+
+// Unwrap returns the underlying reason.
+func (e *ElementError) Unwrap() error {
+	return e.Err
+}
+
+// formatPath renders the path in EDN vector form.
+func formatPath(path []interface{}) string {
+	parts := make([]string, len(path))
+	for i, segment := range path {
+		parts[i] = formatValue(segment)
+	}
+
+	return VectorStartLiteral + strings.Join(parts, VectorSeparatorLiteral) + VectorEndLiteral
+}
+
+// formatValue renders elements in EDN and anything else with its Go syntax.
+func formatValue(value interface{}) (out string) {
+	if elem, is := value.(Element); is && elem != nil {
+		var err error
+		if out, err = elem.Serialize(); err != nil {
+			out = fmt.Sprintf("%T", elem)
+		}
+	} else {
+		out = fmt.Sprintf("%#v", value)
+	}
+
+	return out
+}
+
+// ParseError defines a failure to read EDN, where it happened and why.
+type ParseError struct {
+
+	// Position of the failure.
+	Position Position
+
+	// Expected describes what the reader was looking for.
+	Expected string
+
+	// Found describes what the reader found instead.
+	Found string
+
+	// Snippet holds the source line that contains the failure.
+	Snippet string
+
+	// Err is the underlying reason.
+	Err error
+}
+
+// Error returns the error message, followed by the source line with a marker under the failure.
+func (e *ParseError) Error() string {
+	message := fmt.Sprintf("%s: expected %s, found %s", e.Position, e.Expected, e.Found)
+	if e.Err != nil {
+		message += fmt.Sprintf(" (%s)", e.Err)
+	}
+
+	if len(e.Snippet) > 0 {
+		marker := []rune(e.Snippet)
+		for i := range marker {
+			if marker[i] != '\t' {
+				marker[i] = ' '
 			}
 		}
+		if col := e.Position.Column - 1; col >= 0 && col <= len(marker) {
+			marker = append(marker[:col], '^')
+		}
+		message += "\n\t" + e.Snippet + "\n\t" + string(marker)
 	}
 
-	return err
+	return message
+}
+
+// Unwrap returns the underlying reason.
+func (e *ParseError) Unwrap() error {
+	return e.Err
 }
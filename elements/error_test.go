@@ -1,6 +1,8 @@
 package elements_test
 
 import (
+	"errors"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
@@ -120,5 +122,72 @@ var _ = Describe("The Error constructs", func() {
 			Expect(v1.ErrorList()[0]).To(BeIdenticalTo(err1))
 			Expect(v1.ErrorList()[1]).To(BeIdenticalTo(err2))
 		})
+
+		It("should not modify the errors being appended", func() {
+			err1 := Error("first")
+			err2 := Error("second")
+			err3 := Error("third")
+
+			first := AppendError(err1, err2)
+			combined := AppendError(first, err3)
+
+			Expect(first.(*CumulativeError).ErrorList()).To(HaveLen(2))
+			Expect(combined.(*CumulativeError).ErrorList()).To(HaveLen(3))
+			Expect(combined).NotTo(BeIdenticalTo(first))
+		})
+
+		It("should expose every error to errors.Is and errors.As", func() {
+			elemErr := &ElementError{Type: IntegerType, Value: "abc", Err: ErrInvalidInput}
+			err := AppendError(ErrNoValue, elemErr)
+
+			Expect(errors.Is(err, ErrNoValue)).To(BeTrue())
+			Expect(errors.Is(err, ErrInvalidInput)).To(BeTrue())
+			Expect(errors.Is(err, ErrInvalidPair)).To(BeFalse())
+
+			var found *ElementError
+			Expect(errors.As(err, &found)).To(BeTrue())
+			Expect(found).To(BeIdenticalTo(elemErr))
+		})
+	})
+
+	Context("creating element errors", func() {
+		It("should describe the element, its path and the reason", func() {
+			key, err := NewKeywordElement("db/id")
+			Expect(err).To(BeNil())
+
+			elemErr := &ElementError{
+				Path:  []interface{}{key, 3},
+				Type:  IntegerType,
+				Value: "abc",
+				Err:   ErrInvalidInput,
+			}
+			Expect(elemErr.Error()).To(BeEquivalentTo(`at [:db/id 3]: Invalid input (type :db.type/long, value "abc")`))
+			Expect(errors.Is(elemErr, ErrInvalidInput)).To(BeTrue())
+		})
+
+		It("should be returned for values with no element type", func() {
+			_, err := NewElement(struct{}{})
+			Expect(errors.Is(err, ErrUnknownType)).To(BeTrue())
+			Expect(errors.Is(err, ErrInvalidElement)).To(BeTrue())
+
+			var elemErr *ElementError
+			Expect(errors.As(err, &elemErr)).To(BeTrue())
+			Expect(elemErr.Value).To(Equal(struct{}{}))
+		})
+
+		It("should unwrap errors held by formatted errors", func() {
+			err := NewError("reading %s: %w", "schema.edn", ErrInvalidSymbol)
+			Expect(err.Error()).To(BeEquivalentTo("reading schema.edn: Invalid Symbol"))
+			Expect(errors.Is(err, ErrInvalidSymbol)).To(BeTrue())
+		})
+
+		It("should unwrap parse errors", func() {
+			_, err := Parse([]byte(":/bad"))
+			Expect(errors.Is(err, ErrInvalidKeyword)).To(BeTrue())
+
+			var parseErr *ParseError
+			Expect(errors.As(err, &parseErr)).To(BeTrue())
+			Expect(parseErr.Position.Column).To(BeEquivalentTo(1))
+		})
 	})
 })
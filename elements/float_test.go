@@ -9,13 +9,14 @@ var _ = Describe("Float in EDN", func() {
 	Context("", func() {
 
 		It("should initialize without issue", func() {
-			delete(typeFactories, FloatType)
-			err := initFloat()
+			registry := NewRegistry()
+			registry.RemoveFactory(FloatType)
+			err := initFloat(registry)
 			Ω(err).Should(BeNil())
-			_, has := typeFactories[FloatType]
+			_, has := registry.Factory(FloatType)
 			Ω(has).Should(BeTrue())
 
-			err = initFloat()
+			err = initFloat(registry)
 			Ω(err).ShouldNot(BeNil())
 			Ω(err).Should(BeEquivalentTo(ErrInvalidFactory))
 		})
@@ -23,7 +24,10 @@ var _ = Describe("Float in EDN", func() {
 		It("should create elements from the factory", func() {
 			v := float64(1.234)
 
-			elem, err := typeFactories[FloatType](v)
+			factory, has := defaultRegistry.Factory(FloatType)
+			Ω(has).Should(BeTrue())
+
+			elem, err := factory(v)
 			Ω(err).Should(BeNil())
 			Ω(elem.ElementType()).Should(BeEquivalentTo(FloatType))
 			Ω(elem.Value()).Should(BeEquivalentTo(v))
@@ -32,7 +36,10 @@ var _ = Describe("Float in EDN", func() {
 		It("should not create elements from the factory if the input is not a the right type", func() {
 			v := "foo"
 
-			elem, err := typeFactories[FloatType](v)
+			factory, has := defaultRegistry.Factory(FloatType)
+			Ω(has).Should(BeTrue())
+
+			elem, err := factory(v)
 			Ω(err).ShouldNot(BeNil())
 			Ω(err).Should(BeEquivalentTo(ErrInvalidInput))
 			Ω(elem).Should(BeNil())
@@ -0,0 +1,157 @@
+package elements
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+
+	// PrettyWidth is the width SerializePretty keeps collections within before it breaks them over several lines.
+	PrettyWidth = 80
+)
+
+// SerializePretty writes the element like Serialize, except that map entries are sorted and collections wider than
+// PrettyWidth are broken over several lines, with their children lined up after the opening delimiter.
+func SerializePretty(elem Element) (composition string, err error) {
+	return pretty(elem, 0)
+}
+
+// String returns the element in EDN.
+func (elem *baseElemImpl) String() string {
+	text, err := elem.Serialize()
+	if err != nil {
+		text = "%!s(" + err.Error() + ")"
+	}
+
+	return text
+}
+
+// Format writes the element in EDN for %v and %s, in the pretty form of SerializePretty for %+v and as a quoted string
+// for %q.
+func (elem *baseElemImpl) Format(f fmt.State, verb rune) {
+	var text string
+	var err error
+	switch {
+	case verb == 'v' && f.Flag('+'):
+		text, err = SerializePretty(elem)
+	case verb == 'v' || verb == 's':
+		text, err = elem.Serialize()
+	case verb == 'q':
+		if text, err = elem.Serialize(); err == nil {
+			text = strconv.Quote(text)
+		}
+	default:
+		text = "%!" + string(verb) + "(" + elem.String() + ")"
+	}
+
+	if err != nil {
+		text = "%!" + string(verb) + "(" + err.Error() + ")"
+	}
+
+	_, _ = io.WriteString(f, text)
+}
+
+// MarshalText writes the element in EDN.
+func (elem *baseElemImpl) MarshalText() (text []byte, err error) {
+	var composition string
+	if composition, err = elem.Serialize(); err == nil {
+		text = []byte(composition)
+	}
+
+	return text, err
+}
+
+// MarshalText writes the held element in EDN, or nil if there is none.
+func (h Holder) MarshalText() (text []byte, err error) {
+	var elem Element
+	if elem, err = h.MarshalEDN(); err == nil {
+		var composition string
+		if composition, err = elem.Serialize(); err == nil {
+			text = []byte(composition)
+		}
+	}
+
+	return text, err
+}
+
+// UnmarshalText reads the EDN into the holder.
+func (h *Holder) UnmarshalText(text []byte) (err error) {
+	var elem Element
+	if elem, err = Parse(text); err == nil {
+		h.Element = elem
+	}
+
+	return err
+}
+
+// GobEncode writes the held element in EDN, so that holders can be sent with encoding/gob.
+func (h Holder) GobEncode() ([]byte, error) {
+	return h.MarshalText()
+}
+
+// GobDecode reads the EDN written by GobEncode into the holder.
+func (h *Holder) GobDecode(data []byte) error {
+	return h.UnmarshalText(data)
+}
+
+// pretty writes the element for SerializePretty, where indent is the column the element starts at.
+func pretty(elem Element, indent int) (composition string, err error) {
+
+	var prefix string
+	if elem.HasTag() {
+		prefix = TagPrefix + elem.Tag() + " "
+	}
+
+	switch {
+	case elem.ElementType() == TaggedType:
+		var comp string
+		if comp, err = pretty(elem.Value().(Element), indent+len(prefix)); err == nil {
+			composition = prefix + comp
+		}
+
+	case elem.ElementType().IsCollection():
+		coll := elem.Value().(*collectionElemImpl)
+		prefix += coll.startSymbol
+		childIndent := indent + len(prefix)
+
+		var entries []string
+		_, hasKey := coll.collection.(map[string]Pair)
+		err = coll.IterateChildren(func(key Element, child Element) (e error) {
+			var keyText, childText string
+			if hasKey {
+				if keyText, e = pretty(key, childIndent); e == nil {
+					keyText += coll.keyValueSeparatorSymbol
+				}
+			}
+
+			if e == nil {
+				if childText, e = pretty(child, childIndent+len(keyText)); e == nil {
+					entries = append(entries, keyText+childText)
+				}
+			}
+
+			return e
+		})
+
+		if err == nil {
+			if hasKey {
+				sort.Strings(entries)
+			}
+
+			composition = prefix + strings.Join(entries, coll.separatorSymbol) + coll.endSymbol
+			if strings.Contains(composition, "\n") || indent+len(composition) > PrettyWidth {
+				separator := strings.TrimRight(coll.separatorSymbol, " ") + "\n" + strings.Repeat(" ", childIndent)
+				composition = prefix + strings.Join(entries, separator) + coll.endSymbol
+			}
+		}
+
+	default:
+		composition, err = elem.Serialize()
+	}
+
+	return composition, err
+}
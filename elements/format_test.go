@@ -0,0 +1,83 @@
+package elements
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/xml"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Formatting", func() {
+
+	parse := func(src string) Element {
+		elem, err := Parse([]byte(src))
+		Ω(err).Should(BeNil(), src)
+		return elem
+	}
+
+	It("should print elements in EDN", func() {
+		elem := parse(`[:a "b" #myapp/money "1 USD" #{1}]`)
+		Ω(fmt.Sprint(elem)).Should(Equal(`[:a "b" #myapp/money "1 USD" #{1}]`))
+		Ω(fmt.Sprintf("%s", elem)).Should(Equal(`[:a "b" #myapp/money "1 USD" #{1}]`))
+		Ω(fmt.Sprintf("%q", parse(`"b"`))).Should(Equal(`"\"b\""`))
+		Ω(fmt.Sprintf("%d", parse(`:a`))).Should(Equal(`%!d(:a)`))
+		Ω(fmt.Sprint([]Element{parse(`:a`), parse(`a/b`)})).Should(Equal(`[:a a/b]`))
+		Ω(elem.(fmt.Stringer).String()).Should(Equal(`[:a "b" #myapp/money "1 USD" #{1}]`))
+	})
+
+	It("should print the pretty form", func() {
+		Ω(fmt.Sprintf("%+v", parse(`{:b 2 :a 1}`))).Should(Equal(`{:a 1, :b 2}`))
+
+		elem := parse(`{:person/name "Fred Mertz" :person/email "fred@example.com" ` +
+			`:person/history [#inst "2020-01-02T03:04:05Z" #myapp/money "12.50 EUR" (1 2 3) {:a 1}]}`)
+		Ω(fmt.Sprintf("%+v", elem)).Should(Equal(`{:person/email "fred@example.com",
+ :person/history [#inst 2020-01-02T03:04:05Z
+                  #myapp/money "12.50 EUR"
+                  (1 2 3)
+                  {:a 1}],
+ :person/name "Fred Mertz"}`))
+
+		text, err := SerializePretty(elem)
+		Ω(err).Should(BeNil())
+		Ω(parse(text).Equals(elem)).Should(BeTrue())
+	})
+
+	It("should marshal text", func() {
+		type doc struct {
+			Elem   Element `xml:"elem"`
+			Holder Holder  `xml:"holder"`
+		}
+
+		data, err := xml.Marshal(doc{Elem: parse(`[1 2]`), Holder: Holder{parse(`#{:a}`)}})
+		Ω(err).Should(BeNil())
+		Ω(string(data)).Should(Equal(`<doc><elem>[1 2]</elem><holder>#{:a}</holder></doc>`))
+
+		read := doc{}
+		Ω(xml.Unmarshal([]byte(`<doc><holder>{:a [1]}</holder></doc>`), &read)).Should(BeNil())
+		Ω(read.Holder.Equals(parse(`{:a [1]}`))).Should(BeTrue())
+
+		Ω(xml.Unmarshal([]byte(`<doc><holder>{:a</holder></doc>`), &read)).ShouldNot(BeNil())
+
+		data, err = Holder{}.MarshalText()
+		Ω(err).Should(BeNil())
+		Ω(string(data)).Should(Equal(`nil`))
+	})
+
+	It("should send holders with gob", func() {
+		type message struct {
+			ID      int
+			Payload Holder
+		}
+
+		buf := &bytes.Buffer{}
+		Ω(gob.NewEncoder(buf).Encode(message{ID: 1, Payload: Holder{parse(`#myapp/money "1 USD"`)}})).Should(BeNil())
+
+		read := message{}
+		Ω(gob.NewDecoder(buf).Decode(&read)).Should(BeNil())
+		Ω(read.ID).Should(Equal(1))
+		Ω(read.Payload.Equals(parse(`#myapp/money "1 USD"`))).Should(BeTrue())
+	})
+})
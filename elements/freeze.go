@@ -0,0 +1,126 @@
+package elements
+
+import (
+	"math/big"
+)
+
+const (
+
+	// ErrFrozen defines the error for changing an element that has been frozen.
+	ErrFrozen = Error("Element is frozen")
+)
+
+// Freeze makes the element and everything within it read only, and returns it. SetTag, Append and SetDirection on a
+// frozen element return ErrFrozen, so a frozen element can be shared freely, including between goroutines. Elements
+// not created by this package are left as they are. Use Clone to get a copy that can be changed.
+func Freeze(elem Element) Element {
+	switch v := elem.(type) {
+	case *baseElemImpl:
+		v.frozen = true
+
+	case *symbolElemImpl:
+		v.frozen = true
+
+	case *taggedElemImpl:
+		v.frozen = true
+		Freeze(v.symbol)
+		Freeze(v.Tagged())
+
+	case *collectionElemImpl:
+		v.frozen = true
+		_ = v.IterateChildren(func(key Element, child Element) error {
+			if _, isMap := v.collection.(map[string]Pair); isMap {
+				Freeze(key)
+			}
+			Freeze(child)
+			return nil
+		})
+	}
+
+	return elem
+}
+
+// IsFrozen is true if the element has been frozen.
+func IsFrozen(elem Element) (frozen bool) {
+	switch v := elem.(type) {
+	case *baseElemImpl:
+		frozen = v.frozen
+	case *symbolElemImpl:
+		frozen = v.frozen
+	case *taggedElemImpl:
+		frozen = v.frozen
+	case *collectionElemImpl:
+		frozen = v.frozen
+	}
+
+	return frozen
+}
+
+// Clone returns a deep copy of the element. The copy is never frozen, and changing it leaves the original unchanged.
+// Elements not created by this package are returned as they are.
+func Clone(elem Element) (clone Element) {
+	switch v := elem.(type) {
+	case *baseElemImpl:
+		base := v.copyBase()
+		switch value := base.value.(type) {
+		case *big.Int:
+			base.value = new(big.Int).Set(value)
+		case *big.Float:
+			base.value = new(big.Float).Copy(value)
+		}
+		clone = base
+
+	case *symbolElemImpl:
+		symbol := *v
+		symbol.baseElemImpl = v.copyBase()
+		symbol.value = &symbol
+		symbol.outer = &symbol
+		clone = &symbol
+
+	case *taggedElemImpl:
+		tagged := *v
+		tagged.baseElemImpl = v.copyBase()
+		tagged.symbol = Clone(v.symbol).(SymbolElement)
+		tagged.value = Clone(v.Tagged())
+		tagged.outer = &tagged
+		clone = &tagged
+
+	case *collectionElemImpl:
+		coll := *v
+		coll.baseElemImpl = v.copyBase()
+		coll.value = &coll
+		coll.outer = &coll
+
+		switch children := v.collection.(type) {
+		case []Element:
+			cloned := make([]Element, len(children))
+			for i, child := range children {
+				cloned[i] = Clone(child)
+			}
+			coll.collection = cloned
+
+		case map[string]Pair:
+			cloned := make(map[string]Pair, len(children))
+			for k, pair := range children {
+				cloned[k] = &pairImpl{
+					key:   Clone(pair.Key()),
+					value: Clone(pair.Value()),
+				}
+			}
+			coll.collection = cloned
+		}
+		clone = &coll
+
+	default:
+		clone = elem
+	}
+
+	return clone
+}
+
+// copyBase returns an unfrozen copy of the base element.
+func (elem *baseElemImpl) copyBase() *baseElemImpl {
+	base := *elem
+	base.frozen = false
+	return &base
+}
@@ -0,0 +1,106 @@
+package elements
+
+import (
+	"math/big"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Freezing and cloning", func() {
+
+	parse := func(src string) Element {
+		elem, err := Parse([]byte(src))
+		Ω(err).Should(BeNil(), src)
+		return elem
+	}
+
+	It("should refuse every change to a frozen element", func() {
+		elem := Freeze(parse(`{:a [1 :b] :c #myapp/t #{2}}`))
+		Ω(IsFrozen(elem)).Should(BeTrue())
+
+		coll := elem.(CollectionElement)
+		Ω(coll.Append(parse(`:d`), parse(`3`))).Should(Equal(ErrFrozen))
+		Ω(coll.SetTag("my/tag")).Should(Equal(ErrFrozen))
+
+		vec, err := coll.GetVector(":a")
+		Ω(err).Should(BeNil())
+		Ω(IsFrozen(vec)).Should(BeTrue())
+		Ω(vec.Append(parse(`2`))).Should(Equal(ErrFrozen))
+
+		kw, err := vec.GetKeyword(1)
+		Ω(err).Should(BeNil())
+		Ω(kw.SetDirection(ReverseDirection)).Should(Equal(ErrFrozen))
+
+		child, err := coll.Get(":c")
+		Ω(err).Should(BeNil())
+		tagged := child.(TaggedElement)
+		Ω(tagged.SetTag("myapp/u")).Should(Equal(ErrFrozen))
+		Ω(tagged.TagSymbol().SetTag("x")).Should(Equal(ErrFrozen))
+		Ω(tagged.Tagged().(CollectionElement).Append(parse(`4`))).Should(Equal(ErrFrozen))
+
+		Ω(elem.Equals(parse(`{:a [1 :b] :c #myapp/t #{2}}`))).Should(BeTrue())
+	})
+
+	It("should be safe to read from many goroutines", func() {
+		elem := Freeze(parse(`{:a [1 2 3] :b "x"}`))
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				Ω(elem.Serialize()).ShouldNot(BeEmpty())
+				Ω(elem.(CollectionElement).Append(parse(`:c`), parse(`1`))).Should(Equal(ErrFrozen))
+			}()
+		}
+		wg.Wait()
+	})
+
+	It("should clone deeply", func() {
+		original := Freeze(parse(`{:a [1 :x/b] :c #myapp/t #{2} :d 10N}`))
+		clone := Clone(original)
+		Ω(IsFrozen(clone)).Should(BeFalse())
+		Ω(clone.Equals(original)).Should(BeTrue())
+
+		coll := clone.(CollectionElement)
+		vec, err := coll.GetVector(":a")
+		Ω(err).Should(BeNil())
+		Ω(IsFrozen(vec)).Should(BeFalse())
+		Ω(vec.Append(parse(`2`))).Should(BeNil())
+
+		kw, err := vec.GetKeyword(1)
+		Ω(err).Should(BeNil())
+		Ω(kw.SetDirection(ReverseDirection)).Should(BeNil())
+
+		child, err := coll.Get(":c")
+		Ω(err).Should(BeNil())
+		Ω(child.(TaggedElement).Tagged().(CollectionElement).Append(parse(`3`))).Should(BeNil())
+
+		big10, err := coll.GetInt64(":d")
+		Ω(err).Should(BeNil())
+		Ω(big10).Should(BeEquivalentTo(10))
+		d, err := coll.Get(":d")
+		Ω(err).Should(BeNil())
+		d.Value().(*big.Int).SetInt64(11)
+
+		Ω(coll.Append(parse(`:e`), parse(`5`))).Should(BeNil())
+
+		Ω(vec.Serialize()).Should(BeEquivalentTo(`[1 :x/_b 2]`))
+		Ω(coll.Len()).Should(Equal(4))
+		child, err = coll.Get(":c")
+		Ω(err).Should(BeNil())
+		Ω(child.Equals(parse(`#myapp/t #{2 3}`))).Should(BeTrue())
+		Ω(d.Equals(parse(`11N`))).Should(BeTrue())
+		Ω(original.Equals(parse(`{:a [1 :x/b] :c #myapp/t #{2} :d 10N}`))).Should(BeTrue())
+	})
+
+	It("should leave elements it does not know alone", func() {
+		tenant := testTenant{parse(`"x"`)}
+		Ω(Clone(tenant)).Should(Equal(tenant))
+		Ω(Freeze(tenant)).Should(Equal(tenant))
+		Ω(IsFrozen(tenant)).Should(BeFalse())
+	})
+})
@@ -32,8 +32,10 @@ func NewGroup(elements ...Element) (elem CollectionElement, err error) {
 		}
 
 		var base *baseElemImpl
-		if base, err = makeBaseElement(coll, GroupingType, collectionSerialization(false)); err == nil {
+		if base, err = makeBaseElement(coll, GroupingType, collectionAppender(false)); err == nil {
 			coll.baseElemImpl = base
+			coll.outer = coll
+			coll.baseElemImpl.equality = collectionEquality
 			elem = coll
 			err = elem.Append(elements...)
 		}
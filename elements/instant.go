@@ -11,8 +11,8 @@ const (
 )
 
 // init will add the element factory to the collection of factories
-func initInstant() error {
-	return AddElementTypeFactory(InstantType, func(input interface{}) (elem Element, err error) {
+func initInstant(registry *Registry) error {
+	return registry.AddFactory(InstantType, func(input interface{}) (elem Element, err error) {
 		if v, ok := input.(time.Time); ok {
 			elem, err = NewInstantElement(v)
 		} else {
@@ -25,8 +25,8 @@ func initInstant() error {
 // NewInstantElement creates a new instant element or an error.
 func NewInstantElement(value time.Time) (elem Element, err error) {
 
-	if elem, err = makeBaseElement(value, InstantType, func(value interface{}) (out string, e error) {
-		out = value.(time.Time).Format(time.RFC3339)
+	if elem, err = makeBaseElement(value, InstantType, func(dst []byte, value interface{}) (out []byte, e error) {
+		out = value.(time.Time).AppendFormat(dst, time.RFC3339)
 		return out, e
 	}); err == nil {
 		elem.SetTag(InstantElementTag)
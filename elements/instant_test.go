@@ -11,13 +11,14 @@ var _ = Describe("Instant in EDN", func() {
 	Context("", func() {
 
 		It("should initialize without issue", func() {
-			delete(typeFactories, InstantType)
-			err := initInstant()
+			registry := NewRegistry()
+			registry.RemoveFactory(InstantType)
+			err := initInstant(registry)
 			Ω(err).Should(BeNil())
-			_, has := typeFactories[InstantType]
+			_, has := registry.Factory(InstantType)
 			Ω(has).Should(BeTrue())
 
-			err = initInstant()
+			err = initInstant(registry)
 			Ω(err).ShouldNot(BeNil())
 			Ω(err).Should(BeEquivalentTo(ErrInvalidFactory))
 		})
@@ -25,7 +26,10 @@ var _ = Describe("Instant in EDN", func() {
 		It("should create elements from the factory", func() {
 			v := time.Date(2017, 12, 28, 22, 20, 30, 450, time.UTC)
 
-			elem, err := typeFactories[InstantType](v)
+			factory, has := defaultRegistry.Factory(InstantType)
+			Ω(has).Should(BeTrue())
+
+			elem, err := factory(v)
 			Ω(err).Should(BeNil())
 			Ω(elem.ElementType()).Should(BeEquivalentTo(InstantType))
 			Ω(elem.Value()).Should(BeEquivalentTo(v))
@@ -34,7 +38,10 @@ var _ = Describe("Instant in EDN", func() {
 		It("should not create elements from the factory if the input is not a the right type", func() {
 			v := "foo"
 
-			elem, err := typeFactories[InstantType](v)
+			factory, has := defaultRegistry.Factory(InstantType)
+			Ω(has).Should(BeTrue())
+
+			elem, err := factory(v)
 			Ω(err).ShouldNot(BeNil())
 			Ω(err).Should(BeEquivalentTo(ErrInvalidInput))
 			Ω(elem).Should(BeNil())
@@ -5,8 +5,8 @@ import (
 )
 
 // init will add the element factory to the collection of factories
-func initInteger() error {
-	return AddElementTypeFactory(IntegerType, func(input interface{}) (elem Element, err error) {
+func initInteger(registry *Registry) error {
+	return registry.AddFactory(IntegerType, func(input interface{}) (elem Element, err error) {
 		if v, ok := input.(int64); ok {
 			elem, err = NewIntegerElement(v)
 		} else {
@@ -18,8 +18,8 @@ func initInteger() error {
 
 // NewIntegerElement creates a new integer element or an error.
 func NewIntegerElement(value int64) (elem Element, err error) {
-	elem, err = makeBaseElement(value, IntegerType, func(value interface{}) (out string, e error) {
-		out = strconv.FormatInt(value.(int64), 10)
+	elem, err = makeBaseElement(value, IntegerType, func(dst []byte, value interface{}) (out []byte, e error) {
+		out = strconv.AppendInt(dst, value.(int64), 10)
 		return out, e
 	})
 
@@ -9,13 +9,14 @@ var _ = Describe("Integer in EDN", func() {
 	Context("", func() {
 
 		It("should initialize without issue", func() {
-			delete(typeFactories, IntegerType)
-			err := initInteger()
+			registry := NewRegistry()
+			registry.RemoveFactory(IntegerType)
+			err := initInteger(registry)
 			Ω(err).Should(BeNil())
-			_, has := typeFactories[IntegerType]
+			_, has := registry.Factory(IntegerType)
 			Ω(has).Should(BeTrue())
 
-			err = initInteger()
+			err = initInteger(registry)
 			Ω(err).ShouldNot(BeNil())
 			Ω(err).Should(BeEquivalentTo(ErrInvalidFactory))
 		})
@@ -23,7 +24,10 @@ var _ = Describe("Integer in EDN", func() {
 		It("should create elements from the factory", func() {
 			v := int64(123)
 
-			elem, err := typeFactories[IntegerType](v)
+			factory, has := defaultRegistry.Factory(IntegerType)
+			Ω(has).Should(BeTrue())
+
+			elem, err := factory(v)
 			Ω(err).Should(BeNil())
 			Ω(elem.ElementType()).Should(BeEquivalentTo(IntegerType))
 			Ω(elem.Value()).Should(BeEquivalentTo(v))
@@ -32,7 +36,10 @@ var _ = Describe("Integer in EDN", func() {
 		It("should not create elements from the factory if the input is not a the right type", func() {
 			v := "foo"
 
-			elem, err := typeFactories[IntegerType](v)
+			factory, has := defaultRegistry.Factory(IntegerType)
+			Ω(has).Should(BeTrue())
+
+			elem, err := factory(v)
 			Ω(err).ShouldNot(BeNil())
 			Ω(err).Should(BeEquivalentTo(ErrInvalidInput))
 			Ω(elem).Should(BeNil())
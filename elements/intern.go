@@ -0,0 +1,120 @@
+package elements
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultInternLimit is the number of distinct keyword and symbol names kept in the intern table by default.
+const DefaultInternLimit = 1 << 16
+
+// symbolName is the immutable, interned part of a symbol or keyword. Elements with the same name share one instance,
+// so that the name is validated only once and equality is a pointer comparison. Everything that can change on an
+// element (tag, direction, span) stays on the element itself.
+type symbolName struct {
+	prefix   string
+	name     string
+	modifier string
+}
+
+// internTable holds the interned names keyed by their own value. It is a bounded cache: once it holds limit names, each
+// new name replaces one that has not been looked up since the clock hand last passed it, so names in use stay shared
+// while untrusted input can not grow the table without bound.
+type internTable struct {
+	sync.RWMutex
+	names map[symbolName]*internEntry
+	limit int
+
+	// clock holds the entries in the order the hand visits them, hand is the index of the next one to visit.
+	clock []*internEntry
+	hand  int
+}
+
+// internEntry is an interned name with the flag the clock hand clears before the name may be evicted.
+type internEntry struct {
+	name *symbolName
+	used uint32
+}
+
+// symbolNames is the intern table used by all keywords and symbols.
+var symbolNames = &internTable{
+	names: map[symbolName]*internEntry{},
+	limit: DefaultInternLimit,
+}
+
+// SetInternLimit sets the number of distinct keyword and symbol names that are interned and returns the previous
+// limit. Once the table is full, new names replace the names that have been used least recently, so untrusted input
+// can not grow the table without bound. Names that are replaced are still valid, equal names created later just no
+// longer share them. Lowering the limit below the current size clears the table. A limit of zero turns interning off.
+func SetInternLimit(limit int) (previous int) {
+	if limit < 0 {
+		limit = 0
+	}
+
+	symbolNames.Lock()
+	previous, symbolNames.limit = symbolNames.limit, limit
+	if len(symbolNames.names) > limit {
+		symbolNames.names = map[symbolName]*internEntry{}
+		symbolNames.clock, symbolNames.hand = nil, 0
+	}
+	symbolNames.Unlock()
+
+	return previous
+}
+
+// InternedCount returns the number of names currently in the intern table.
+func InternedCount() (count int) {
+	symbolNames.RLock()
+	count = len(symbolNames.names)
+	symbolNames.RUnlock()
+
+	return count
+}
+
+// lookup returns the interned name equal to the key, or nil if it is not interned. The name is marked as used, so that
+// it is kept over names that are not.
+func (table *internTable) lookup(key symbolName) (name *symbolName) {
+	table.RLock()
+	if entry := table.names[key]; entry != nil {
+		atomic.StoreUint32(&entry.used, 1)
+		name = entry.name
+	}
+	table.RUnlock()
+
+	return name
+}
+
+// store interns the name and returns the shared instance, evicting a name that has not been used recently if the table
+// is full. If another caller interned an equal name first, its instance is returned instead.
+func (table *internTable) store(key symbolName) (shared *symbolName) {
+	table.Lock()
+	if entry := table.names[key]; entry != nil {
+		shared = entry.name
+	} else {
+		shared = &key
+		if table.limit > 0 {
+			entry = &internEntry{name: shared}
+			if len(table.clock) < table.limit {
+				table.clock = append(table.clock, entry)
+			} else {
+				table.evict(entry)
+			}
+			table.names[key] = entry
+		}
+	}
+	table.Unlock()
+
+	return shared
+}
+
+// evict moves the clock hand to the first entry not used since the hand last passed it, clearing the flags on the way,
+// and replaces that entry. The table must be locked for writing.
+func (table *internTable) evict(entry *internEntry) {
+	for table.hand %= len(table.clock); atomic.SwapUint32(&table.clock[table.hand].used, 0) != 0; {
+		table.hand = (table.hand + 1) % len(table.clock)
+	}
+
+	delete(table.names, *table.clock[table.hand].name)
+	table.clock[table.hand] = entry
+	table.hand++
+}
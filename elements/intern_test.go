@@ -0,0 +1,151 @@
+package elements
+
+import (
+	"strconv"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Interning of keywords and symbols", func() {
+
+	It("should share the name of equal keywords", func() {
+		left, err := NewKeywordElement(":db/ident")
+		Ω(err).Should(BeNil())
+
+		right, err := NewKeywordElement("db", "ident")
+		Ω(err).Should(BeNil())
+
+		Ω(left.(*symbolElemImpl).symbolName).Should(BeIdenticalTo(right.(*symbolElemImpl).symbolName))
+		Ω(left.Equals(right)).Should(BeTrue())
+	})
+
+	It("should keep keywords and symbols with the same text apart", func() {
+		keyword, err := NewKeywordElement("db/ident")
+		Ω(err).Should(BeNil())
+
+		symbol, err := NewSymbolElement("db/ident")
+		Ω(err).Should(BeNil())
+
+		Ω(keyword.(*symbolElemImpl).symbolName).ShouldNot(BeIdenticalTo(symbol.(*symbolElemImpl).symbolName))
+		Ω(keyword.Modifier()).Should(BeEquivalentTo(KeywordPrefix))
+		Ω(symbol.Modifier()).Should(BeEquivalentTo(""))
+	})
+
+	It("should keep tags and directions per element", func() {
+		left, err := NewKeywordElement("person/friend")
+		Ω(err).Should(BeNil())
+
+		right, err := NewKeywordElement("person/friend")
+		Ω(err).Should(BeNil())
+
+		right.SetDirection(ReverseDirection)
+		Ω(right.SetTag("inst")).Should(BeNil())
+
+		Ω(left.Serialize()).Should(BeEquivalentTo(":person/friend"))
+		Ω(right.Serialize()).Should(BeEquivalentTo("#inst :person/_friend"))
+		Ω(left.Equals(right)).Should(BeFalse())
+	})
+
+	It("should not intern invalid names", func() {
+		before := InternedCount()
+
+		_, err := NewKeywordElement("1bad")
+		Ω(err).Should(BeEquivalentTo(ErrInvalidKeyword))
+
+		_, err = NewKeywordElement("1bad")
+		Ω(err).Should(BeEquivalentTo(ErrInvalidKeyword))
+		Ω(InternedCount()).Should(BeNumerically("==", before))
+	})
+
+	It("should stop growing once the limit is reached", func() {
+		previous := SetInternLimit(0)
+		defer SetInternLimit(previous)
+
+		Ω(SetInternLimit(2)).Should(BeZero())
+		Ω(InternedCount()).Should(BeZero())
+
+		for i := 0; i < 10; i++ {
+			_, err := NewKeywordElement("limit", "k"+strconv.Itoa(i))
+			Ω(err).Should(BeNil())
+		}
+		Ω(InternedCount()).Should(BeNumerically("==", 2))
+
+		// the latest names replaced the earlier ones, which are still valid and equal, just no longer shared.
+		left, err := NewKeywordElement("limit/k9")
+		Ω(err).Should(BeNil())
+
+		right, err := NewKeywordElement("limit/k9")
+		Ω(err).Should(BeNil())
+		Ω(left.(*symbolElemImpl).symbolName).Should(BeIdenticalTo(right.(*symbolElemImpl).symbolName))
+
+		left, err = NewKeywordElement("limit/k0")
+		Ω(err).Should(BeNil())
+		Ω(left.Equals(right)).Should(BeFalse())
+		Ω(InternedCount()).Should(BeNumerically("==", 2))
+	})
+
+	It("should keep the names in use when the table is full", func() {
+		previous := SetInternLimit(0)
+		defer SetInternLimit(previous)
+		SetInternLimit(2)
+
+		used, err := NewSymbolElement("full/used")
+		Ω(err).Should(BeNil())
+		_, err = NewSymbolElement("full/unused")
+		Ω(err).Should(BeNil())
+
+		again, err := NewSymbolElement("full/used")
+		Ω(err).Should(BeNil())
+		Ω(again.(*symbolElemImpl).symbolName).Should(BeIdenticalTo(used.(*symbolElemImpl).symbolName))
+
+		for i := 0; i < 3; i++ {
+			_, err = NewSymbolElement("full/new")
+			Ω(err).Should(BeNil())
+		}
+		Ω(InternedCount()).Should(BeNumerically("==", 2))
+
+		again, err = NewSymbolElement("full/used")
+		Ω(err).Should(BeNil())
+		Ω(again.(*symbolElemImpl).symbolName).Should(BeIdenticalTo(used.(*symbolElemImpl).symbolName))
+
+		unused, err := NewSymbolElement("full/unused")
+		Ω(err).Should(BeNil())
+		Ω(unused.Equals(again)).Should(BeFalse())
+		Ω(InternedCount()).Should(BeNumerically("==", 2))
+	})
+
+	It("should clear the table when the limit is lowered below its size", func() {
+		previous := SetInternLimit(DefaultInternLimit)
+		defer SetInternLimit(previous)
+
+		_, err := NewSymbolElement("clear/me")
+		Ω(err).Should(BeNil())
+		Ω(InternedCount()).Should(BeNumerically(">", 0))
+
+		Ω(SetInternLimit(-1)).Should(BeEquivalentTo(DefaultInternLimit))
+		Ω(InternedCount()).Should(BeZero())
+	})
+
+	It("should be safe to use concurrently", func() {
+		wg := sync.WaitGroup{}
+		results := make([]KeywordElement, 64)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				var err error
+				results[i], err = NewKeywordElement("concurrent", "name")
+				Ω(err).Should(BeNil())
+			}(i)
+		}
+		wg.Wait()
+
+		for _, elem := range results {
+			Ω(elem.(*symbolElemImpl).symbolName).Should(BeIdenticalTo(results[0].(*symbolElemImpl).symbolName))
+		}
+	})
+})
@@ -0,0 +1,549 @@
+package elements
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattrobenolt/gocql/uuid"
+)
+
+const (
+
+	// TypedJSON writes JSON that keeps every element distinguishable, so reading it back yields an equal element.
+	// Vectors, strings, integers, floats, booleans and nil use their natural JSON form. Everything else is written as
+	// an object with a single key naming the type, for example {"#keyword": "db/ident"} or {"#set": [1, 2]}. Maps are
+	// written as objects when every key is a string not starting with #, otherwise as {"#map": [[key, value], ...]}.
+	// Tags other than the built in #inst and #uuid are written as {"#tag": ["my/tag", value]}.
+	TypedJSON JSONMode = iota
+
+	// PlainJSON writes the JSON a person would expect and loses what JSON cannot hold: keywords and symbols become
+	// strings without the colon, characters, instants and UUIDs become strings, big numbers become numbers, lists and
+	// sets become arrays and tags are dropped. Map keys are written as strings. Reading plain JSON yields strings,
+	// numbers, vectors and maps with string keys.
+	PlainJSON
+
+	// ErrInvalidJSON defines the error for JSON that does not hold a valid element.
+	ErrInvalidJSON = Error("Invalid JSON")
+
+	// jsonTypePrefix marks the keys of the objects that describe a typed element.
+	jsonTypePrefix = "#"
+
+	// the names of the typed element objects.
+	jsonKeyword = jsonTypePrefix + "keyword"
+	jsonSymbol  = jsonTypePrefix + "symbol"
+	jsonChar    = jsonTypePrefix + "char"
+	jsonBigInt  = jsonTypePrefix + "bigint"
+	jsonBigDec  = jsonTypePrefix + "bigdec"
+	jsonInst    = jsonTypePrefix + InstantElementTag
+	jsonUUID    = jsonTypePrefix + UUIDElementTag
+	jsonList    = jsonTypePrefix + "list"
+	jsonSet     = jsonTypePrefix + "set"
+	jsonMap     = jsonTypePrefix + "map"
+	jsonTag     = jsonTypePrefix + "tag"
+)
+
+// JSONMode selects how elements are converted to and from JSON.
+type JSONMode int
+
+// builtinTags hold the tags that are part of the element type and need no #tag object.
+var builtinTags = map[ElementType]string{
+	InstantType: InstantElementTag,
+	UUIDType:    UUIDElementTag,
+}
+
+// ToJSON converts the element to JSON in the mode.
+func ToJSON(elem Element, mode JSONMode) (data []byte, err error) {
+
+	var value interface{}
+	if value, err = toJSONValue(nil, elem, mode); err == nil {
+		buf := &bytes.Buffer{}
+		enc := json.NewEncoder(buf)
+		enc.SetEscapeHTML(false)
+		if err = enc.Encode(value); err == nil {
+			data = bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+		}
+	}
+
+	return data, err
+}
+
+// FromJSON converts the JSON to an element in the mode. The data must hold exactly one JSON value.
+func FromJSON(data []byte, mode JSONMode) (elem Element, err error) {
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var value interface{}
+	if err = dec.Decode(&value); err == nil {
+		if _, e := dec.Token(); e != io.EOF {
+			err = ErrTrailingInput
+		} else {
+			elem, err = fromJSONValue(nil, value, mode)
+		}
+	}
+
+	return elem, err
+}
+
+// MarshalJSON writes the element as typed JSON.
+func (elem *baseElemImpl) MarshalJSON() ([]byte, error) {
+	return ToJSON(elem, TypedJSON)
+}
+
+// MarshalJSON writes the symbol or keyword as typed JSON.
+func (elem *symbolElemImpl) MarshalJSON() ([]byte, error) {
+	return ToJSON(elem, TypedJSON)
+}
+
+// MarshalJSON writes the collection as typed JSON.
+func (elem *collectionElemImpl) MarshalJSON() ([]byte, error) {
+	return ToJSON(elem, TypedJSON)
+}
+
+// Holder holds an element so that it can be decoded into, for example as a field of a struct read by encoding/json or
+// by Unmarshal.
+type Holder struct {
+	Element
+}
+
+// MarshalJSON writes the held element as typed JSON, or null if there is none.
+func (h Holder) MarshalJSON() (data []byte, err error) {
+	if h.Element == nil {
+		data = []byte("null")
+	} else {
+		data, err = ToJSON(h.Element, TypedJSON)
+	}
+
+	return data, err
+}
+
+// UnmarshalJSON reads the typed JSON into the holder.
+func (h *Holder) UnmarshalJSON(data []byte) (err error) {
+	var elem Element
+	if elem, err = FromJSON(data, TypedJSON); err == nil {
+		h.Element = elem
+	}
+
+	return err
+}
+
+// MarshalEDN returns the held element, or nil if there is none.
+func (h Holder) MarshalEDN() (elem Element, err error) {
+	if elem = h.Element; elem == nil {
+		elem, err = NewNilElement()
+	}
+
+	return elem, err
+}
+
+// UnmarshalEDN holds the element.
+func (h *Holder) UnmarshalEDN(elem Element) (err error) {
+	h.Element = elem
+	return err
+}
+
+// untaggedText returns the serialization of the element without its tag.
+func untaggedText(elem Element) (text string, err error) {
+	if text, err = elem.Serialize(); err == nil && elem.HasTag() {
+		text = strings.TrimPrefix(text, TagPrefix+elem.Tag()+" ")
+	}
+
+	return text, err
+}
+
+// jsonFloat formats the float as a JSON number that always reads back as a float.
+func jsonFloat(f float64) (number json.Number, err error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		err = &ElementError{Type: FloatType, Value: f, Err: ErrInvalidInput}
+	} else {
+		text := strconv.FormatFloat(f, 'g', -1, 64)
+		if !strings.ContainsAny(text, ".e") {
+			text += ".0"
+		}
+		number = json.Number(text)
+	}
+
+	return number, err
+}
+
+// toJSONValue converts the element to the values encoding/json writes.
+func toJSONValue(path []interface{}, elem Element, mode JSONMode) (value interface{}, err error) {
+
+	typed := mode == TypedJSON
+	wrap := func(name string, v interface{}) {
+		if typed {
+			v = map[string]interface{}{name: v}
+		}
+		value = v
+	}
+
+	switch elem.ElementType() {
+	case NilType:
+		value = nil
+
+	case BooleanType, StringType:
+		value = elem.Value()
+
+	case IntegerType:
+		value = json.Number(strconv.FormatInt(elem.Value().(int64), 10))
+
+	case FloatType:
+		value, err = jsonFloat(elem.Value().(float64))
+
+	case BigIntType:
+		if typed {
+			wrap(jsonBigInt, elem.Value().(*big.Int).String())
+		} else {
+			value = json.Number(elem.Value().(*big.Int).String())
+		}
+
+	case BigDecType:
+		if typed {
+			wrap(jsonBigDec, elem.Value().(*big.Float).Text('g', -1))
+		} else {
+			value = json.Number(elem.Value().(*big.Float).Text('g', -1))
+		}
+
+	case CharacterType:
+		wrap(jsonChar, string(elem.Value().(rune)))
+
+	case KeywordType:
+		var text string
+		if text, err = untaggedText(elem); err == nil {
+			wrap(jsonKeyword, strings.TrimPrefix(text, KeywordPrefix))
+		}
+
+	case SymbolType:
+		var text string
+		if text, err = untaggedText(elem); err == nil {
+			wrap(jsonSymbol, text)
+		}
+
+	case InstantType:
+		wrap(jsonInst, elem.Value().(time.Time).Format(time.RFC3339Nano))
+
+	case UUIDType:
+		wrap(jsonUUID, elem.Value().(uuid.UUID).String())
+
+	case GroupingType, VectorType, SetType:
+		var items []interface{}
+		if items, err = jsonItems(path, elem.(CollectionElement), mode); err == nil {
+			switch elem.ElementType() {
+			case GroupingType:
+				wrap(jsonList, items)
+			case SetType:
+				wrap(jsonSet, items)
+			default:
+				value = items
+			}
+		}
+
+	case MapType:
+		value, err = jsonMapValue(path, elem.(CollectionElement), mode)
+
+	case TaggedType:
+		value, err = toJSONValue(path, elem.(TaggedElement).Tagged(), mode)
+
+	default:
+		err = &ElementError{Path: path, Type: elem.ElementType(), Value: elem.Value(), Err: ErrUnknownType}
+	}
+
+	if err == nil && typed && elem.Tag() != builtinTags[elem.ElementType()] {
+		value = map[string]interface{}{jsonTag: []interface{}{elem.Tag(), value}}
+	}
+
+	return value, err
+}
+
+// jsonItems converts the children of a list, vector or set.
+func jsonItems(path []interface{}, coll CollectionElement, mode JSONMode) (items []interface{}, err error) {
+	items = []interface{}{}
+	err = coll.IterateChildren(func(key Element, child Element) (e error) {
+		var item interface{}
+		if item, e = toJSONValue(append(path[:len(path):len(path)], key), child, mode); e == nil {
+			items = append(items, item)
+		}
+		return e
+	})
+
+	return items, err
+}
+
+// jsonMapValue converts the map to an object, or to a #map of entries if the keys cannot be object keys.
+func jsonMapValue(path []interface{}, coll CollectionElement, mode JSONMode) (value interface{}, err error) {
+
+	object := map[string]interface{}{}
+	entries := [][]interface{}{}
+	asObject := true
+
+	err = coll.IterateChildren(func(key Element, child Element) (e error) {
+		var k, v interface{}
+		if v, e = toJSONValue(append(path[:len(path):len(path)], key), child, mode); e == nil {
+			var name string
+			switch {
+			case mode == PlainJSON:
+				if name, e = plainJSONKey(key); e == nil {
+					if _, has := object[name]; has {
+						e = &ElementError{Path: path, Value: name, Err: ErrDuplicateKey}
+					}
+				}
+			case key.ElementType() == StringType && !key.HasTag() && !strings.HasPrefix(key.Value().(string), jsonTypePrefix):
+				name = key.Value().(string)
+			default:
+				asObject = false
+			}
+
+			if e == nil {
+				object[name] = v
+				if k, e = toJSONValue(path, key, mode); e == nil {
+					entries = append(entries, []interface{}{k, v})
+				}
+			}
+		}
+		return e
+	})
+
+	if err == nil {
+		if asObject {
+			value = object
+		} else {
+			// keep the output stable for the same map.
+			sort.Slice(entries, func(i, j int) bool {
+				left, _ := json.Marshal(entries[i][0])
+				right, _ := json.Marshal(entries[j][0])
+				return string(left) < string(right)
+			})
+			value = map[string]interface{}{jsonMap: entries}
+		}
+	}
+
+	return value, err
+}
+
+// plainJSONKey converts a map key to the object key of plain JSON.
+func plainJSONKey(key Element) (name string, err error) {
+	switch key.ElementType() {
+	case StringType:
+		name = key.Value().(string)
+	case CharacterType:
+		name = string(key.Value().(rune))
+	case InstantType:
+		name = key.Value().(time.Time).Format(time.RFC3339Nano)
+	case UUIDType:
+		name = key.Value().(uuid.UUID).String()
+	case KeywordType:
+		if name, err = untaggedText(key); err == nil {
+			name = strings.TrimPrefix(name, KeywordPrefix)
+		}
+	default:
+		name, err = untaggedText(key)
+	}
+
+	return name, err
+}
+
+// fromJSONValue converts a value read by encoding/json to an element.
+func fromJSONValue(path []interface{}, value interface{}, mode JSONMode) (elem Element, err error) {
+
+	switch v := value.(type) {
+	case nil:
+		elem, err = NewNilElement()
+
+	case bool:
+		elem, err = NewBooleanElement(v)
+
+	case string:
+		elem, err = NewStringElement(v)
+
+	case json.Number:
+		elem, err = jsonNumber(v)
+
+	case []interface{}:
+		var items []Element
+		if items, err = fromJSONItems(path, v, mode); err == nil {
+			elem, err = NewVector(items...)
+		}
+
+	case map[string]interface{}:
+		if name, inner, is := typedJSONObject(v); is && mode == TypedJSON {
+			elem, err = fromTypedJSON(path, name, inner)
+		} else {
+			var coll CollectionElement
+			if coll, err = NewMap(); err == nil {
+				names := make([]string, 0, len(v))
+				for name := range v {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+
+				for i := 0; err == nil && i < len(names); i++ {
+					var key, child Element
+					if key, err = NewStringElement(names[i]); err == nil {
+						if child, err = fromJSONValue(append(path[:len(path):len(path)], names[i]), v[names[i]], mode); err == nil {
+							err = coll.Append(key, child)
+						}
+					}
+				}
+				elem = coll
+			}
+		}
+
+	default:
+		err = ErrInvalidJSON
+	}
+
+	if err != nil {
+		if _, is := err.(*ElementError); !is {
+			err = &ElementError{Path: path, Value: value, Err: err}
+		}
+		elem = nil
+	}
+
+	return elem, err
+}
+
+// jsonNumber converts the number to an integer, a big integer when it does not fit, or a float.
+func jsonNumber(number json.Number) (elem Element, err error) {
+	text := number.String()
+	if strings.ContainsAny(text, ".eE") {
+		var f float64
+		if f, err = number.Float64(); err == nil {
+			elem, err = NewFloatElement(f)
+		}
+	} else if i, e := number.Int64(); e == nil {
+		elem, err = NewIntegerElement(i)
+	} else if b, ok := new(big.Int).SetString(text, 10); ok {
+		elem, err = NewBigIntElement(b)
+	} else {
+		err = ErrInvalidNumber
+	}
+
+	return elem, err
+}
+
+// fromJSONItems converts the items of an array.
+func fromJSONItems(path []interface{}, values []interface{}, mode JSONMode) (items []Element, err error) {
+	items = make([]Element, len(values))
+	for i := 0; err == nil && i < len(values); i++ {
+		items[i], err = fromJSONValue(append(path[:len(path):len(path)], i), values[i], mode)
+	}
+
+	return items, err
+}
+
+// typedJSONObject is true if the object describes a typed element.
+func typedJSONObject(object map[string]interface{}) (name string, value interface{}, is bool) {
+	if len(object) == 1 {
+		for name, value = range object {
+			is = strings.HasPrefix(name, jsonTypePrefix)
+		}
+	}
+
+	return name, value, is
+}
+
+// fromTypedJSON converts the value of a typed element object.
+func fromTypedJSON(path []interface{}, name string, value interface{}) (elem Element, err error) {
+
+	text, isText := value.(string)
+	items, isItems := value.([]interface{})
+
+	switch {
+	case name == jsonKeyword && isText:
+		elem, err = NewKeywordElement(text)
+
+	case name == jsonSymbol && isText:
+		elem, err = NewSymbolElement(text)
+
+	case name == jsonChar && isText && len([]rune(text)) == 1:
+		elem, err = NewCharacterElement([]rune(text)[0])
+
+	case name == jsonBigInt && isText:
+		if b, ok := new(big.Int).SetString(text, 10); ok {
+			elem, err = NewBigIntElement(b)
+		} else {
+			err = ErrInvalidNumber
+		}
+
+	case name == jsonBigDec && isText:
+		var f *big.Float
+		if f, _, err = big.ParseFloat(text, 10, bigDecPrecision(text), big.ToNearestEven); err == nil {
+			elem, err = NewBigDecElement(f)
+		} else {
+			err = ErrInvalidNumber
+		}
+
+	case name == jsonInst && isText:
+		var t time.Time
+		if t, err = time.Parse(time.RFC3339Nano, text); err == nil {
+			elem, err = NewInstantElement(t)
+		} else {
+			err = ErrInvalidInput
+		}
+
+	case name == jsonUUID && isText:
+		var u uuid.UUID
+		if u, err = uuid.ParseUUID(text); err == nil {
+			elem, err = NewUUIDElement(u)
+		} else {
+			err = ErrInvalidInput
+		}
+
+	case (name == jsonList || name == jsonSet) && isItems:
+		var children []Element
+		if children, err = fromJSONItems(path, items, TypedJSON); err == nil {
+			if name == jsonList {
+				elem, err = NewGroup(children...)
+			} else {
+				elem, err = NewSet(children...)
+			}
+		}
+
+	case name == jsonMap && isItems:
+		var coll CollectionElement
+		if coll, err = NewMap(); err == nil {
+			for i := 0; err == nil && i < len(items); i++ {
+				if entry, is := items[i].([]interface{}); is && len(entry) == 2 {
+					var key, child Element
+					if key, err = fromJSONValue(path, entry[0], TypedJSON); err == nil {
+						if child, err = fromJSONValue(append(path[:len(path):len(path)], key), entry[1], TypedJSON); err == nil {
+							if _, e := coll.Get(key); e == nil {
+								err = ErrDuplicateKey
+							} else {
+								err = coll.Append(key, child)
+							}
+						}
+					}
+				} else {
+					err = ErrInvalidPair
+				}
+			}
+			elem = coll
+		}
+
+	case name == jsonTag && isItems && len(items) == 2:
+		if tag, is := items[0].(string); is {
+			var symbol SymbolElement
+			var value Element
+			if symbol, err = NewSymbolElement(tag); err != nil {
+				err = ErrInvalidTag
+			} else if value, err = fromJSONValue(path, items[1], TypedJSON); err == nil {
+				elem, err = NewTaggedElement(symbol, value)
+			}
+		} else {
+			err = ErrInvalidTag
+		}
+
+	default:
+		err = ErrInvalidJSON
+	}
+
+	return elem, err
+}
@@ -0,0 +1,113 @@
+package elements
+
+import (
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JSON in EDN", func() {
+
+	parse := func(src string) Element {
+		elem, err := Parse([]byte(src))
+		Ω(err).Should(BeNil(), src)
+		return elem
+	}
+
+	Context("in the typed mode", func() {
+
+		It("should round trip every element type", func() {
+			sources := []string{
+				`nil`, `true`, `42`, `2.0`, `-1.5e-7`, `"a \"string\" <b>"`, `\c`, `\newline`,
+				`:db/ident`, `:db/_ident`, `my/symbol`, `123456789012345678901234567890N`, `3.14159M`,
+				`#inst "1985-04-12T23:20:50.52Z"`, `#uuid "f81d4fae-7dec-11d0-a765-00a0c91e6bf6"`,
+				`[1 (2 3) #{4 :five}]`, `()`, `{}`, `{"plain" 1 "#hash" 2}`, `{:a 1 [2] 3 nil #{}}`,
+				`#my/tag {:a #other/tag [1]}`, `#my/tag "#keyword"`,
+			}
+
+			for _, src := range sources {
+				elem := parse(src)
+				data, err := ToJSON(elem, TypedJSON)
+				Ω(err).Should(BeNil(), src)
+
+				back, err := FromJSON(data, TypedJSON)
+				Ω(err).Should(BeNil(), string(data))
+				Ω(back.Equals(elem)).Should(BeTrue(), string(data))
+			}
+		})
+
+		It("should write the natural JSON form where it can", func() {
+			data, err := ToJSON(parse(`{"name" "Fred" "tags" #{:a} "age" 42 "height" 1.0 "kids" [] "list" ("x")}`), TypedJSON)
+			Ω(err).Should(BeNil())
+			Ω(string(data)).Should(BeEquivalentTo(`{"age":42,"height":1.0,"kids":[],"list":{"#list":["x"]},"name":"Fred","tags":{"#set":[{"#keyword":"a"}]}}`))
+
+			data, err = ToJSON(parse(`{:b 2 :a 1}`), TypedJSON)
+			Ω(err).Should(BeNil())
+			Ω(string(data)).Should(BeEquivalentTo(`{"#map":[[{"#keyword":"a"},1],[{"#keyword":"b"},2]]}`))
+
+			data, err = ToJSON(parse(`#db/id [:db.part/user -1]`), TypedJSON)
+			Ω(err).Should(BeNil())
+			Ω(string(data)).Should(BeEquivalentTo(`{"#tag":["db/id",[{"#keyword":"db.part/user"},-1]]}`))
+		})
+
+		It("should implement the encoding/json interfaces", func() {
+			var doc struct {
+				Schema Holder `json:"schema"`
+				Empty  Holder `json:"empty"`
+			}
+
+			doc.Schema = Holder{parse(`[{:db/ident :person/name :db/cardinality :db.cardinality/one}]`)}
+			data, err := json.Marshal(doc)
+			Ω(err).Should(BeNil())
+
+			data2, err := json.Marshal(map[string]Element{"schema": doc.Schema.Element})
+			Ω(err).Should(BeNil())
+			Ω(string(data)).Should(ContainSubstring(string(data2[1 : len(data2)-1])))
+
+			var back struct {
+				Schema Holder `json:"schema"`
+			}
+			Ω(json.Unmarshal(data, &back)).Should(BeNil())
+			Ω(back.Schema.Equals(doc.Schema.Element)).Should(BeTrue())
+		})
+
+		It("should refuse malformed typed objects", func() {
+			for _, src := range []string{
+				`{"#keyword": 1}`, `{"#unknown": "x"}`, `{"#char": "ab"}`, `{"#map": [[1]]}`,
+				`{"#map": [[1, 2], [1, 3]]}`, `{"#inst": "never"}`, `[1, {"#set": 2}]`, `1 2`, `{`,
+			} {
+				elem, err := FromJSON([]byte(src), TypedJSON)
+				Ω(err).ShouldNot(BeNil(), src)
+				Ω(elem).Should(BeNil(), src)
+			}
+
+			_, err := FromJSON([]byte(`[1, {"#set": 2}]`), TypedJSON)
+			var elemErr *ElementError
+			Ω(errors.As(err, &elemErr)).Should(BeTrue())
+			Ω(elemErr.Path).Should(Equal([]interface{}{1}))
+			Ω(errors.Is(err, ErrInvalidJSON)).Should(BeTrue())
+		})
+	})
+
+	Context("in the plain mode", func() {
+
+		It("should write what JSON can hold", func() {
+			data, err := ToJSON(parse(`#my/tag {:db/ident :person/name :tags #{"a"} :list (1 2N 3.5M) :at #inst "2020-01-02T03:04:05Z" \c \x}`), PlainJSON)
+			Ω(err).Should(BeNil())
+			Ω(string(data)).Should(BeEquivalentTo(`{"at":"2020-01-02T03:04:05Z","c":"x","db/ident":"person/name","list":[1,2,3.5],"tags":["a"]}`))
+		})
+
+		It("should refuse keys that collide", func() {
+			_, err := ToJSON(parse(`{:a 1 "a" 2}`), PlainJSON)
+			Ω(errors.Is(err, ErrDuplicateKey)).Should(BeTrue())
+		})
+
+		It("should read JSON as strings, numbers, vectors and maps", func() {
+			elem, err := FromJSON([]byte(`{"a": [1, 2.5, "x", null, true, 123456789012345678901234567890], "#set": [1]}`), PlainJSON)
+			Ω(err).Should(BeNil())
+			Ω(elem.Equals(parse(`{"a" [1 2.5 "x" nil true 123456789012345678901234567890N] "#set" [1]}`))).Should(BeTrue())
+		})
+	})
+})
@@ -1,6 +1,7 @@
 package elements
 
 import (
+	"errors"
 	"strings"
 )
 
@@ -23,8 +24,8 @@ const (
 type KeywordDirection string
 
 // init will add the element factory to the collection of factories
-func initKeyword() error {
-	return AddElementTypeFactory(KeywordType, func(input interface{}) (elem Element, err error) {
+func initKeyword(registry *Registry) error {
+	return registry.AddFactory(KeywordType, func(input interface{}) (elem Element, err error) {
 		if v, ok := input.(string); ok {
 			elem, err = NewKeywordElement(v)
 		} else {
@@ -41,12 +42,14 @@ func initKeyword() error {
 // legal keywords. A keyword cannot begin with ::
 
 // If the target platform supports some notion of interning, it is a further semantic of keywords that all instances of
-// the same keyword yield the identical object.
+// the same keyword yield the identical object. Elements can carry a tag and a direction, so each keyword is still its
+// own element, but all instances of the same keyword share one interned name (see SetInternLimit) and compare by
+// pointer.
 type KeywordElement interface {
 	SymbolElement
 
-	// SetDirection of the keyword
-	SetDirection(KeywordDirection)
+	// SetDirection of the keyword, or ErrFrozen if the keyword is frozen.
+	SetDirection(KeywordDirection) (err error)
 }
 
 // NewKeywordElement creates a new character element or an error.
@@ -69,25 +72,26 @@ func NewKeywordElement(parts ...string) (elem KeywordElement, err error) {
 	}
 
 	if err == nil {
-		var symbol SymbolElement
-		if symbol, err = NewSymbolElement(parts...); err == nil {
-
-			impl := symbol.(*symbolElemImpl)
-			impl.baseElemImpl.elemType = KeywordType
-			impl.modifier = KeywordPrefix
-
+		var impl *symbolElemImpl
+		if impl, err = newSymbolElement(KeywordType, KeywordPrefix, parts); err == nil {
 			elem = impl
 		}
 	}
 
-	if err == ErrInvalidSymbol {
+	if errors.Is(err, ErrInvalidSymbol) {
 		err = ErrInvalidKeyword
 	}
 
 	return elem, err
 }
 
-// SetDirection of the keyword
-func (elem *symbolElemImpl) SetDirection(direction KeywordDirection) {
-	elem.direction = direction
+// SetDirection of the keyword, or ErrFrozen if the keyword is frozen.
+func (elem *symbolElemImpl) SetDirection(direction KeywordDirection) (err error) {
+	if elem.frozen {
+		err = ErrFrozen
+	} else {
+		elem.direction = direction
+	}
+
+	return err
 }
@@ -9,13 +9,14 @@ var _ = Describe("Keyword in EDN", func() {
 	Context("", func() {
 
 		It("should initialize without issue", func() {
-			delete(typeFactories, KeywordType)
-			err := initKeyword()
+			registry := NewRegistry()
+			registry.RemoveFactory(KeywordType)
+			err := initKeyword(registry)
 			Ω(err).Should(BeNil())
-			_, has := typeFactories[KeywordType]
+			_, has := registry.Factory(KeywordType)
 			Ω(has).Should(BeTrue())
 
-			err = initKeyword()
+			err = initKeyword(registry)
 			Ω(err).ShouldNot(BeNil())
 			Ω(err).Should(BeEquivalentTo(ErrInvalidFactory))
 		})
@@ -23,7 +24,10 @@ var _ = Describe("Keyword in EDN", func() {
 		It("should create elements from the factory", func() {
 			v := "testKeyword"
 
-			elem, err := typeFactories[KeywordType](v)
+			factory, has := defaultRegistry.Factory(KeywordType)
+			Ω(has).Should(BeTrue())
+
+			elem, err := factory(v)
 			Ω(err).Should(BeNil())
 			Ω(elem.ElementType()).Should(BeEquivalentTo(KeywordType))
 
@@ -35,7 +39,10 @@ var _ = Describe("Keyword in EDN", func() {
 		It("should not create elements from the factory if the input is not a the right type", func() {
 			v := 123
 
-			elem, err := typeFactories[KeywordType](v)
+			factory, has := defaultRegistry.Factory(KeywordType)
+			Ω(has).Should(BeTrue())
+
+			elem, err := factory(v)
 			Ω(err).ShouldNot(BeNil())
 			Ω(err).Should(BeEquivalentTo(ErrInvalidInput))
 			Ω(elem).Should(BeNil())
@@ -0,0 +1,125 @@
+package elements
+
+import (
+	"fmt"
+)
+
+// LimitError defines the errors for input that a reader refuses because it passes one of its Limits. Every limit has
+// its own error, errors.As with a LimitError matches any of them.
+type LimitError string
+
+// Error returns the error message.
+func (e LimitError) Error() string {
+	return string(e)
+}
+
+const (
+
+	// ErrInputLimit defines the error for input larger than MaxInputSize.
+	ErrInputLimit = LimitError("Input too large")
+
+	// ErrDepthLimit defines the error for elements nested deeper than MaxDepth.
+	ErrDepthLimit = LimitError("Elements nested too deep")
+
+	// ErrCollectionLimit defines the error for a collection with more children than MaxCollectionSize.
+	ErrCollectionLimit = LimitError("Collection too large")
+
+	// ErrStringLimit defines the error for a string longer than MaxStringLength.
+	ErrStringLimit = LimitError("String too long")
+
+	// ErrTokenLimit defines the error for a token longer than MaxTokenLength.
+	ErrTokenLimit = LimitError("Token too long")
+
+	// ErrTagNotAllowed defines the error for a tag missing from AllowedTags.
+	ErrTagNotAllowed = LimitError("Tag not allowed")
+
+	// DefaultMaxInputSize is the default for MaxInputSize, 16 MiB.
+	DefaultMaxInputSize = 16 << 20
+
+	// DefaultMaxDepth is the default for MaxDepth.
+	DefaultMaxDepth = 512
+
+	// DefaultMaxCollectionSize is the default for MaxCollectionSize.
+	DefaultMaxCollectionSize = 1 << 20
+
+	// DefaultMaxStringLength is the default for MaxStringLength, 1 MiB.
+	DefaultMaxStringLength = 1 << 20
+
+	// DefaultMaxTokenLength is the default for MaxTokenLength.
+	DefaultMaxTokenLength = 4096
+)
+
+// Limits bound what a reader accepts, so that EDN from untrusted sources cannot use up memory, the stack or the CPU.
+// Each limit is checked before the input that passes it is held, and fails with its LimitError wrapped in a ParseError.
+// A zero field takes its default, a negative field removes the limit.
+type Limits struct {
+
+	// MaxInputSize is the number of bytes of source. A Decoder stops reading its input once the limit is passed.
+	MaxInputSize int
+
+	// MaxDepth is how deeply elements nest. Every collection, tag and discard adds a level, the elements at the top
+	// are at level 0.
+	MaxDepth int
+
+	// MaxCollectionSize is the number of children in a collection, where the keys and values of a map count apart.
+	MaxCollectionSize int
+
+	// MaxStringLength is the number of bytes in a string, after its escapes are applied.
+	MaxStringLength int
+
+	// MaxTokenLength is the number of bytes in a number, symbol, keyword, character or tag. Reading a big number takes
+	// time that grows with the square of its length.
+	MaxTokenLength int
+
+	// AllowedTags are the only tags read, if not nil. The built in #inst and #uuid are always allowed.
+	AllowedTags []string
+}
+
+// DefaultLimits returns the limits used when none are set.
+func DefaultLimits() Limits {
+	return Limits{
+		MaxInputSize:      DefaultMaxInputSize,
+		MaxDepth:          DefaultMaxDepth,
+		MaxCollectionSize: DefaultMaxCollectionSize,
+		MaxStringLength:   DefaultMaxStringLength,
+		MaxTokenLength:    DefaultMaxTokenLength,
+	}
+}
+
+// withDefaults returns the limits with the zero fields set to their defaults.
+func (limits Limits) withDefaults() Limits {
+	defaults := DefaultLimits()
+	for _, field := range []struct{ value, fallback *int }{
+		{&limits.MaxInputSize, &defaults.MaxInputSize},
+		{&limits.MaxDepth, &defaults.MaxDepth},
+		{&limits.MaxCollectionSize, &defaults.MaxCollectionSize},
+		{&limits.MaxStringLength, &defaults.MaxStringLength},
+		{&limits.MaxTokenLength, &defaults.MaxTokenLength},
+	} {
+		if *field.value == 0 {
+			*field.value = *field.fallback
+		}
+	}
+
+	return limits
+}
+
+// exceeds is true if the count passes the limit. Negative limits are never passed.
+func exceeds(count int, limit int) bool {
+	return limit >= 0 && count > limit
+}
+
+// allowsTag is true if the tag may be read.
+func (limits Limits) allowsTag(tag string) (allowed bool) {
+	allowed = limits.AllowedTags == nil || tag == InstantElementTag || tag == UUIDElementTag
+	for i := 0; !allowed && i < len(limits.AllowedTags); i++ {
+		allowed = limits.AllowedTags[i] == tag
+	}
+
+	return allowed
+}
+
+// failLimit builds the parse error for input at the offset that passes the limit.
+func (rd *reader) failLimit(offset int, what string, limit int, reason LimitError) error {
+	return rd.fail(offset, fmt.Sprintf("%s of at most %d", what, limit), "more", reason)
+}
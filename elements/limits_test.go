@@ -0,0 +1,167 @@
+package elements
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reader limits", func() {
+
+	decode := func(src string, limits Limits) (Element, error) {
+		dec := NewDecoder(strings.NewReader(src))
+		dec.SetLimits(limits)
+		return dec.Decode()
+	}
+
+	It("should fill the unset limits with the defaults", func() {
+		limits := Limits{MaxDepth: 3, MaxTokenLength: -1}.withDefaults()
+		Ω(limits.MaxDepth).Should(Equal(3))
+		Ω(limits.MaxTokenLength).Should(Equal(-1))
+		Ω(limits.MaxInputSize).Should(Equal(DefaultMaxInputSize))
+		Ω(limits.MaxCollectionSize).Should(Equal(DefaultMaxCollectionSize))
+		Ω(limits.MaxStringLength).Should(Equal(DefaultMaxStringLength))
+	})
+
+	It("should read input within the limits", func() {
+		elem, err := decode(`{:a [1 #my/tag "abc"]}`, Limits{
+			MaxInputSize:      22,
+			MaxDepth:          3,
+			MaxCollectionSize: 2,
+			MaxStringLength:   3,
+			MaxTokenLength:    7,
+			AllowedTags:       []string{"my/tag"},
+		})
+		Ω(err).Should(BeNil())
+		Ω(elem.Serialize()).Should(BeEquivalentTo(`{:a [1 #my/tag "abc"]}`))
+	})
+
+	It("should refuse input that passes a limit", func() {
+		failures := map[string]struct {
+			limits Limits
+			reason error
+			column int
+		}{
+			`[1 2 3]`:                {Limits{MaxInputSize: 6}, ErrInputLimit, 7},
+			`[[[1]]]`:                {Limits{MaxDepth: 2}, ErrDepthLimit, 4},
+			`#a #a #a 1`:             {Limits{MaxDepth: 2}, ErrDepthLimit, 10},
+			`[#_ #_ [] 1]`:           {Limits{MaxDepth: 1}, ErrDepthLimit, 7},
+			`{:a 1 :b 2}`:            {Limits{MaxCollectionSize: 3}, ErrCollectionLimit, 10},
+			`#{1 2 3}`:               {Limits{MaxCollectionSize: 2}, ErrCollectionLimit, 7},
+			`["abc" "a\tbc"]`:        {Limits{MaxStringLength: 3}, ErrStringLimit, 8},
+			`123456789012345678901N`: {Limits{MaxTokenLength: 20}, ErrTokenLimit, 1},
+			`\newline`:               {Limits{MaxTokenLength: 4}, ErrTokenLimit, 1},
+			`#:abcdef{:a 1}`:         {Limits{MaxTokenLength: 4}, ErrTokenLimit, 1},
+			`[#my/tag 1]`:            {Limits{AllowedTags: []string{}}, ErrTagNotAllowed, 2},
+			`#other/tag 1`:           {Limits{AllowedTags: []string{"my/tag"}}, ErrTagNotAllowed, 1},
+		}
+
+		for src, failure := range failures {
+			_, err := decode(src, failure.limits)
+			Ω(errors.Is(err, failure.reason)).Should(BeTrue(), src)
+
+			var limitErr LimitError
+			Ω(errors.As(err, &limitErr)).Should(BeTrue(), src)
+
+			var parseErr *ParseError
+			Ω(errors.As(err, &parseErr)).Should(BeTrue(), src)
+			Ω(parseErr.Position.Column).Should(Equal(failure.column), src)
+		}
+	})
+
+	It("should always allow the built in tags", func() {
+		elem, err := decode(`#inst "2020-01-02T03:04:05Z"`, Limits{AllowedTags: []string{}})
+		Ω(err).Should(BeNil())
+		Ω(elem.ElementType()).Should(BeEquivalentTo(InstantType))
+	})
+
+	It("should remove negative limits", func() {
+		src := "[" + strings.Repeat("[", 600) + strings.Repeat("]", 600) + "]"
+		_, err := Parse([]byte(src))
+		Ω(errors.Is(err, ErrDepthLimit)).Should(BeTrue())
+
+		_, err = decode(src, Limits{MaxDepth: -1})
+		Ω(err).Should(BeNil())
+	})
+
+	It("should stop reading the input at the limit", func() {
+		in := &countingReader{Reader: bytes.NewReader(make([]byte, 1<<20))}
+		dec := NewDecoder(in)
+		dec.SetLimits(Limits{MaxInputSize: 10})
+
+		_, err := dec.Decode()
+		Ω(errors.Is(err, ErrInputLimit)).Should(BeTrue())
+		Ω(in.count).Should(BeNumerically("<=", 11))
+
+		_, err = dec.Decode()
+		Ω(errors.Is(err, ErrInputLimit)).Should(BeTrue())
+	})
+
+	It("should read long lines and large maps in linear time", func() {
+		var src strings.Builder
+		src.WriteString("{")
+		for i := 0; i < 50000; i++ {
+			src.WriteString(":key/k" + strconv.Itoa(i) + " \"é\" ")
+		}
+		src.WriteString(":last \"é\"}")
+
+		elem, err := Parse([]byte(src.String()))
+		Ω(err).Should(BeNil())
+		Ω(elem.(CollectionElement).Len()).Should(Equal(50001))
+
+		last, err := elem.(CollectionElement).Get(":last")
+		Ω(err).Should(BeNil())
+		Ω(last.Span().Start.Column).Should(Equal(len([]rune(src.String())) - 3))
+	})
+
+	It("should find duplicate float, big decimal and collection keys in linear time", func() {
+		for _, key := range []func(i int) string{
+			func(i int) string { return strconv.Itoa(i) + ".5" },
+			func(i int) string { return strconv.Itoa(i) + ".5M" },
+			func(i int) string { return "[" + strconv.Itoa(i) + " 1.0]" },
+			func(i int) string { return "#{" + strconv.Itoa(i) + " {:a " + strconv.Itoa(i) + "}}" },
+		} {
+			var src strings.Builder
+			src.WriteString("{")
+			for i := 0; i < 20000; i++ {
+				src.WriteString(key(i) + " 1 ")
+			}
+
+			start := time.Now()
+			elem, err := Parse([]byte(src.String() + "}"))
+			Ω(err).Should(BeNil())
+			Ω(elem.(CollectionElement).Len()).Should(Equal(20000))
+			Ω(time.Since(start)).Should(BeNumerically("<", 5*time.Second), key(0))
+
+			_, err = Parse([]byte(src.String() + key(19999) + " 2}"))
+			Ω(errors.Is(err, ErrDuplicateKey)).Should(BeTrue(), key(0))
+		}
+	})
+
+	It("should group keys that are equal but written differently", func() {
+		for _, src := range []string{`{0.0 1 -0.0 2}`, `{0M 1 -0.0M 2}`, `{1.50M 1 1.5M 2}`, `{#{1 2} 1 #{2 1} 2}`,
+			`{{:a 1 :b 2} 1 {:b 2 :a 1} 2}`, `{[-0.0] 1 [0.0] 2}`, `{#t [1] 1 #t [1] 2}`} {
+			_, err := Parse([]byte(src))
+			Ω(errors.Is(err, ErrDuplicateKey)).Should(BeTrue(), src)
+		}
+	})
+})
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	io.Reader
+	count int
+}
+
+// Read counts the bytes.
+func (r *countingReader) Read(p []byte) (n int, err error) {
+	n, err = r.Reader.Read(p)
+	r.count += n
+	return n, err
+}
@@ -1,5 +1,13 @@
 package elements
 
+import (
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
 const (
 
 	// MapStartLiteral is the start of an EDN group element.
@@ -21,38 +29,17 @@ const (
 // NewMap creates a new vector
 func NewMap(pairs ...Pair) (elem CollectionElement, err error) {
 
-	coll := &collectionElemImpl{
-		startSymbol:             MapStartLiteral,
-		endSymbol:               MapEndLiteral,
-		separatorSymbol:         MapSeparatorLiteral,
-		keyValueSeparatorSymbol: MapKeyValueSeparatorLiteral,
-		collection:              map[string]Element{},
-	}
-
-	var base *baseElemImpl
-	if base, err = makeBaseElement(coll, MapType, collectionSerialization(true)); err == nil {
-		coll.baseElemImpl = base
+	var coll *collectionElemImpl
+	if coll, err = emptyMap(); err == nil {
 
 		// check for errors
-		keys := []Element{}
 		for _, pair := range pairs {
 			if pair == nil || pair.Key() == nil {
 				err = ErrInvalidPair
+			} else if coll.hasKey(pair.Key()) {
+				err = ErrDuplicateKey
 			} else {
-
-				key := pair.Key()
-				for _, k := range keys {
-
-					if key.Equals(k) {
-						err = ErrDuplicateKey
-						break
-					}
-				}
-
-				if err == nil {
-					keys = append(keys, key)
-					err = coll.Append(key, pair.Value())
-				}
+				err = coll.Append(pair.Key(), pair.Value())
 			}
 
 			if err != nil {
@@ -67,3 +54,108 @@ func NewMap(pairs ...Pair) (elem CollectionElement, err error) {
 
 	return elem, err
 }
+
+// emptyMap creates a map without any pairs.
+func emptyMap() (coll *collectionElemImpl, err error) {
+
+	coll = &collectionElemImpl{
+		startSymbol:             MapStartLiteral,
+		endSymbol:               MapEndLiteral,
+		separatorSymbol:         MapSeparatorLiteral,
+		keyValueSeparatorSymbol: MapKeyValueSeparatorLiteral,
+		collection:              map[string]Pair{},
+	}
+
+	var base *baseElemImpl
+	if base, err = makeBaseElement(coll, MapType, collectionAppender(true)); err == nil {
+		coll.baseElemImpl = base
+		coll.outer = coll
+		coll.baseElemImpl.equality = collectionEquality
+	} else {
+		coll = nil
+	}
+
+	return coll, err
+}
+
+// hasKey is true if the map holds a key equal to the key.
+func (coll *collectionElemImpl) hasKey(key Element) (has bool) {
+	if pairs, is := coll.collection.(map[string]Pair); is {
+		_, has = pairs[mapKey(key)]
+	}
+
+	return has
+}
+
+// equalityGroup returns the text that maps store their keys under and sets tell their members apart by. It holds the
+// type, tag and value of the element, so equal elements share a group and unequal ones, such as "a" and a, do not:
+// scalars are grouped by their value, with the zeros of floats and big decimals as one, and collections and tagged
+// elements by the groups of their children, sorted for sets and maps whose order does not matter.
+func equalityGroup(key Element) (group string) {
+
+	// a tagged element shares the group of the element that carries the tag itself, which it equals.
+	if tagged, is := key.(*taggedElemImpl); is && !tagged.Tagged().HasTag() {
+		key = tagged.Tagged()
+		group = string(key.ElementType()) + " " + tagged.Tag()
+	} else {
+		group = string(key.ElementType()) + " " + key.Tag()
+	}
+
+	switch v := key.(type) {
+	case *symbolElemImpl:
+		group += " " + v.Modifier() + v.Prefix() + SymbolSeparator + string(v.Direction()) + v.Name()
+
+	case *collectionElemImpl:
+		var children []string
+		switch c := v.collection.(type) {
+		case []Element:
+			for _, child := range c {
+				children = append(children, lengthPrefixed(equalityGroup(child)))
+			}
+		case map[string]Pair:
+			for _, pair := range c {
+				children = append(children, lengthPrefixed(equalityGroup(pair.Key())+" "+equalityGroup(pair.Value())))
+			}
+		}
+
+		if v.elemType == MapType || v.elemType == SetType {
+			sort.Strings(children)
+		}
+		group += " " + strings.Join(children, " ")
+
+	case *taggedElemImpl:
+		group += " " + equalityGroup(v.Tagged())
+
+	case *baseElemImpl:
+		switch v.elemType {
+		case StringType, IntegerType, CharacterType, BooleanType, NilType, UUIDType, BigIntType:
+			if text, err := v.appender(nil, v.value); err == nil {
+				group += " " + string(text)
+			}
+
+		case InstantType:
+			group += " " + v.value.(time.Time).Format(time.RFC3339Nano)
+
+		case FloatType:
+			if f := v.value.(float64); f == 0 {
+				group += " 0"
+			} else {
+				group += " " + strconv.FormatFloat(f, 'g', -1, 64)
+			}
+
+		case BigDecType:
+			if f := v.value.(*big.Float); f.Sign() == 0 {
+				group += " 0"
+			} else {
+				group += " " + f.Text('p', 0)
+			}
+		}
+	}
+
+	return group
+}
+
+// lengthPrefixed prefixes the text with its length, so that joined texts can not run into each other.
+func lengthPrefixed(text string) string {
+	return strconv.Itoa(len(text)) + ":" + text
+}
@@ -0,0 +1,569 @@
+package elements
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/mattrobenolt/gocql/uuid"
+)
+
+const (
+
+	// MarshalTag is the struct field tag that names the keyword of a field, e.g. `edn:"person/name"`. The name may be
+	// followed by ",omitempty" to leave out zero values, and a name of "-" leaves the field out.
+	MarshalTag = "edn"
+
+	// ErrInvalidTarget defines the error for unmarshalling into something that is not a non nil pointer.
+	ErrInvalidTarget = Error("Invalid unmarshal target")
+
+	// ErrTypeMismatch defines the error for an element that does not fit the Go value it is unmarshalled into.
+	ErrTypeMismatch = Error("Element does not fit the target")
+)
+
+// Marshaler is implemented by types that convert themselves into an element, e.g. to be written under their own tag.
+type Marshaler interface {
+
+	// MarshalEDN returns the element for this value.
+	MarshalEDN() (elem Element, err error)
+}
+
+// Unmarshaler is implemented by types that set themselves from an element. The element is passed as read, tag included.
+type Unmarshaler interface {
+
+	// UnmarshalEDN sets this value from the element.
+	UnmarshalEDN(elem Element) (err error)
+}
+
+// the reflected types with a conversion of their own.
+var (
+	unmarshalerInterface = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	timeType             = reflect.TypeOf(time.Time{})
+	uuidType             = reflect.TypeOf(uuid.UUID{})
+	bigIntType           = reflect.TypeOf(big.Int{})
+	bigFloatType         = reflect.TypeOf(big.Float{})
+)
+
+// Marshal writes the value as EDN, converted as with MarshalElement.
+func Marshal(value interface{}) (data []byte, err error) {
+	var elem Element
+	if elem, err = MarshalElement(value); err == nil {
+		var text string
+		if text, err = elem.Serialize(); err == nil {
+			data = []byte(text)
+		}
+	}
+
+	return data, err
+}
+
+// MarshalElement converts the value to an element. Elements are used as they are, and a Marshaler, Builder or
+// CollectionBuilder creates its own element. Otherwise booleans, numbers, strings, time.Time, uuid.UUID, big.Int and
+// big.Float become the matching scalar, slices and arrays become vectors, maps become maps and structs become maps
+// keyed by keywords, see MarshalTag. Nil pointers, slices, maps and interfaces become nil.
+func MarshalElement(value interface{}) (elem Element, err error) {
+	return marshalValue(nil, reflect.ValueOf(value))
+}
+
+// Unmarshal reads the EDN into the value, converted as with UnmarshalElement.
+func Unmarshal(data []byte, value interface{}) (err error) {
+	var elem Element
+	if elem, err = Parse(data); err == nil {
+		err = UnmarshalElement(elem, value)
+	}
+
+	return err
+}
+
+// UnmarshalElement sets the value, which must be a non nil pointer, from the element. An Unmarshaler sets itself, and
+// an Element or interface{} receives the element as it is. Otherwise the element must fit the value as described by
+// MarshalElement, tags are looked through, nil sets the zero value and map keys without a field are ignored. A nil
+// element returns ErrInvalidElement.
+func UnmarshalElement(elem Element, value interface{}) (err error) {
+	if target := reflect.ValueOf(value); target.Kind() != reflect.Ptr || target.IsNil() {
+		err = &ElementError{Value: value, Err: ErrInvalidTarget}
+	} else if elem == nil {
+		err = ErrInvalidElement
+	} else {
+		err = unmarshalValue(nil, elem, target.Elem())
+	}
+
+	return err
+}
+
+// customElement creates the element for values that convert themselves.
+func customElement(value interface{}) (elem Element, is bool, err error) {
+
+	switch v := value.(type) {
+	case Marshaler:
+		is = true
+		if !isNilPointer(v) {
+			elem, err = v.MarshalEDN()
+		}
+	case Element:
+		elem, is = v, true
+	case Builder:
+		is = true
+		if !isNilPointer(v) {
+			elem, err = v.Build()
+		}
+	case CollectionBuilder:
+		is = true
+		if !isNilPointer(v) {
+			elem, err = v.BuildCollection()
+		}
+	}
+
+	if is && err == nil && elem == nil {
+		if isNilPointer(value) {
+			elem, err = NewNilElement()
+		} else {
+			err = ErrInvalidElement
+		}
+	}
+
+	return elem, is, err
+}
+
+// isNilPointer is true for a nil pointer held in an interface.
+func isNilPointer(value interface{}) bool {
+	v := reflect.ValueOf(value)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+// marshalChildren replaces the children that are a Marshaler with their element. The input is returned unchanged if
+// there are none.
+func marshalChildren(children []Element) (marshalled []Element, err error) {
+	marshalled = children
+	copied := false
+	for i := 0; err == nil && i < len(children); i++ {
+		if m, is := children[i].(Marshaler); is {
+			var elem Element
+			if elem, err = m.MarshalEDN(); err == nil && elem == nil {
+				err = ErrInvalidElement
+			} else if err == nil {
+				if !copied {
+					marshalled, copied = append([]Element(nil), children...), true
+				}
+				marshalled[i] = elem
+			}
+		}
+	}
+
+	return marshalled, err
+}
+
+// marshalValue converts the reflected value to an element.
+func marshalValue(path []interface{}, v reflect.Value) (elem Element, err error) {
+
+	var is bool
+	if v.IsValid() && v.CanInterface() {
+		elem, is, err = customElement(v.Interface())
+		if !is && v.Kind() != reflect.Ptr && v.CanAddr() {
+			elem, is, err = customElement(v.Addr().Interface())
+		}
+	}
+
+	if !is {
+		switch {
+		case !v.IsValid():
+			elem, err = NewNilElement()
+
+		case v.Type() == timeType:
+			elem, err = NewInstantElement(v.Interface().(time.Time))
+
+		case v.Type() == uuidType:
+			elem, err = NewUUIDElement(v.Interface().(uuid.UUID))
+
+		case v.Type() == bigIntType:
+			value := v.Interface().(big.Int)
+			elem, err = NewBigIntElement(new(big.Int).Set(&value))
+
+		case v.Type() == bigFloatType:
+			value := v.Interface().(big.Float)
+			elem, err = NewBigDecElement(new(big.Float).Copy(&value))
+
+		default:
+			switch v.Kind() {
+			case reflect.Bool:
+				elem, err = NewBooleanElement(v.Bool())
+
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				elem, err = NewIntegerElement(v.Int())
+
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+				if u := v.Uint(); u > math.MaxInt64 {
+					elem, err = NewBigIntElement(new(big.Int).SetUint64(u))
+				} else {
+					elem, err = NewIntegerElement(int64(u))
+				}
+
+			case reflect.Float32, reflect.Float64:
+				elem, err = NewFloatElement(v.Float())
+
+			case reflect.String:
+				elem, err = NewStringElement(v.String())
+
+			case reflect.Ptr, reflect.Interface:
+				if v.IsNil() {
+					elem, err = NewNilElement()
+				} else {
+					elem, err = marshalValue(path, v.Elem())
+				}
+
+			case reflect.Slice, reflect.Array:
+				if v.Kind() == reflect.Slice && v.IsNil() {
+					elem, err = NewNilElement()
+				} else {
+					items := make([]Element, v.Len())
+					for i := 0; err == nil && i < len(items); i++ {
+						items[i], err = marshalValue(append(path[:len(path):len(path)], i), v.Index(i))
+					}
+					if err == nil {
+						elem, err = NewVector(items...)
+					}
+				}
+
+			case reflect.Map:
+				if v.IsNil() {
+					elem, err = NewNilElement()
+				} else {
+					elem, err = marshalMap(path, v)
+				}
+
+			case reflect.Struct:
+				elem, err = marshalStruct(path, v)
+
+			default:
+				err = ErrUnknownType
+			}
+		}
+	}
+
+	if err != nil {
+		if _, is := err.(*ElementError); !is {
+			var value interface{}
+			if v.IsValid() && v.CanInterface() {
+				value = v.Interface()
+			}
+			err = &ElementError{Path: path, Value: value, Err: err}
+		}
+		elem = nil
+	}
+
+	return elem, err
+}
+
+// marshalMap converts the entries of a map.
+func marshalMap(path []interface{}, v reflect.Value) (elem Element, err error) {
+	var coll CollectionElement
+	if coll, err = NewMap(); err == nil {
+		iter := v.MapRange()
+		for err == nil && iter.Next() {
+			var key, child Element
+			if key, err = marshalValue(path, iter.Key()); err == nil {
+				if child, err = marshalValue(append(path[:len(path):len(path)], key), iter.Value()); err == nil {
+					err = coll.Append(key, child)
+				}
+			}
+		}
+		elem = coll
+	}
+
+	return elem, err
+}
+
+// marshalStruct converts the fields of a struct to a map keyed by keywords.
+func marshalStruct(path []interface{}, v reflect.Value) (elem Element, err error) {
+	var coll CollectionElement
+	if coll, err = NewMap(); err == nil {
+		for _, field := range structFields(v.Type()) {
+			value := v.Field(field.index)
+			if field.omitEmpty && value.IsZero() {
+				continue
+			}
+
+			var key, child Element
+			if key, err = NewKeywordElement(KeywordPrefix + field.name); err == nil {
+				if child, err = marshalValue(append(path[:len(path):len(path)], key), value); err == nil {
+					err = coll.Append(key, child)
+				}
+			}
+
+			if err != nil {
+				break
+			}
+		}
+		elem = coll
+	}
+
+	return elem, err
+}
+
+// structField describes how a struct field is marshalled.
+type structField struct {
+
+	// index of the field.
+	index int
+
+	// name of the keyword for the field.
+	name string
+
+	// omitEmpty leaves out zero values.
+	omitEmpty bool
+}
+
+// structFields returns the exported fields of the struct type that are not left out by their tag.
+func structFields(structType reflect.Type) (fields []structField) {
+	for i := 0; i < structType.NumField(); i++ {
+		if f := structType.Field(i); f.PkgPath == "" {
+			field := structField{index: i, name: f.Name}
+
+			tag := strings.Split(f.Tag.Get(MarshalTag), ",")
+			if len(tag[0]) != 0 {
+				field.name = tag[0]
+			}
+			for _, option := range tag[1:] {
+				field.omitEmpty = field.omitEmpty || option == "omitempty"
+			}
+
+			if tag[0] != "-" {
+				fields = append(fields, field)
+			}
+		}
+	}
+
+	return fields
+}
+
+// unmarshalValue sets the reflected value, which must be settable, from the element.
+func unmarshalValue(path []interface{}, elem Element, v reflect.Value) (err error) {
+
+	switch {
+	case v.Kind() != reflect.Ptr && v.CanAddr() && v.Addr().Type().Implements(unmarshalerInterface):
+		err = v.Addr().Interface().(Unmarshaler).UnmarshalEDN(elem)
+
+	case v.Kind() == reflect.Interface && reflect.TypeOf(elem).AssignableTo(v.Type()):
+		v.Set(reflect.ValueOf(elem))
+
+	case elem.ElementType() == NilType:
+		v.Set(reflect.Zero(v.Type()))
+
+	case v.Kind() == reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		err = unmarshalValue(path, elem, v.Elem())
+
+	case elem.ElementType() == TaggedType:
+		err = unmarshalValue(path, elem.(TaggedElement).Tagged(), v)
+
+	default:
+		err = unmarshalScalar(elem, v)
+
+		if err == ErrTypeMismatch {
+			switch {
+			case elem.ElementType() == MapType && v.Kind() == reflect.Struct:
+				err = unmarshalStruct(path, elem.(CollectionElement), v)
+
+			case elem.ElementType() == MapType && v.Kind() == reflect.Map:
+				err = unmarshalMap(path, elem.(CollectionElement), v)
+
+			case elem.ElementType().IsCollection() && elem.ElementType() != MapType &&
+				(v.Kind() == reflect.Slice || (v.Kind() == reflect.Array && v.Type() != uuidType)):
+				err = unmarshalSequence(path, elem.(CollectionElement), v)
+			}
+		}
+	}
+
+	if err != nil {
+		if _, is := err.(*ElementError); !is {
+			err = &ElementError{Path: path, Type: elem.ElementType(), Value: elem, Err: err}
+		}
+	}
+
+	return err
+}
+
+// unmarshalScalar sets the value from a scalar element, or returns ErrTypeMismatch if it is not one the value can hold.
+func unmarshalScalar(elem Element, v reflect.Value) (err error) {
+
+	err = ErrTypeMismatch
+	value := elem.Value()
+
+	switch v.Type() {
+	case timeType:
+		if t, is := value.(time.Time); is {
+			v.Set(reflect.ValueOf(t))
+			err = nil
+		}
+
+	case uuidType:
+		if u, is := value.(uuid.UUID); is {
+			v.Set(reflect.ValueOf(u))
+			err = nil
+		}
+
+	case bigIntType:
+		switch n := value.(type) {
+		case *big.Int:
+			v.Set(reflect.ValueOf(*new(big.Int).Set(n)))
+			err = nil
+		case int64:
+			v.Set(reflect.ValueOf(*big.NewInt(n)))
+			err = nil
+		}
+
+	case bigFloatType:
+		switch n := value.(type) {
+		case *big.Float:
+			v.Set(reflect.ValueOf(*new(big.Float).Copy(n)))
+			err = nil
+		case float64:
+			v.Set(reflect.ValueOf(*big.NewFloat(n)))
+			err = nil
+		}
+
+	default:
+		switch v.Kind() {
+		case reflect.Bool:
+			if b, is := value.(bool); is {
+				v.SetBool(b)
+				err = nil
+			}
+
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			var i int64
+			var is bool
+			switch n := value.(type) {
+			case int64:
+				i, is = n, true
+			case rune:
+				i, is = int64(n), true
+			case *big.Int:
+				i, is = n.Int64(), n.IsInt64()
+			}
+			if is && !v.OverflowInt(i) {
+				v.SetInt(i)
+				err = nil
+			}
+
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			var u uint64
+			var is bool
+			switch n := value.(type) {
+			case int64:
+				u, is = uint64(n), n >= 0
+			case *big.Int:
+				u, is = n.Uint64(), n.IsUint64()
+			}
+			if is && !v.OverflowUint(u) {
+				v.SetUint(u)
+				err = nil
+			}
+
+		case reflect.Float32, reflect.Float64:
+			switch n := value.(type) {
+			case float64:
+				v.SetFloat(n)
+				err = nil
+			case int64:
+				v.SetFloat(float64(n))
+				err = nil
+			}
+
+		case reflect.String:
+			if elem.ElementType() == StringType {
+				v.SetString(value.(string))
+				err = nil
+			}
+		}
+	}
+
+	return err
+}
+
+// unmarshalSequence sets a slice or array from the children of a vector, list or set. Arrays take as many children as
+// they can hold and zero the rest.
+func unmarshalSequence(path []interface{}, coll CollectionElement, v reflect.Value) (err error) {
+
+	var children []Element
+	if children, err = collectionChildren(coll); err == nil {
+		if v.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(v.Type(), len(children), len(children)))
+		} else {
+			v.Set(reflect.Zero(v.Type()))
+		}
+
+		for i := 0; err == nil && i < len(children) && i < v.Len(); i++ {
+			err = unmarshalValue(append(path[:len(path):len(path)], i), children[i], v.Index(i))
+		}
+	}
+
+	return err
+}
+
+// unmarshalMap adds the entries of the map element to a Go map. Keyword keys are read into string keys by their name
+// with the namespace, e.g. :db/ident becomes "db/ident".
+func unmarshalMap(path []interface{}, coll CollectionElement, v reflect.Value) (err error) {
+	if v.IsNil() {
+		v.Set(reflect.MakeMapWithSize(v.Type(), coll.Len()))
+	}
+
+	return coll.IterateChildren(func(key Element, child Element) (e error) {
+		k := reflect.New(v.Type().Key()).Elem()
+		if key.ElementType() == KeywordType && k.Kind() == reflect.String {
+			var name string
+			if name, e = keywordName(key); e == nil {
+				k.SetString(name)
+			}
+		} else {
+			e = unmarshalValue(path, key, k)
+		}
+
+		if e == nil {
+			value := reflect.New(v.Type().Elem()).Elem()
+			if e = unmarshalValue(append(path[:len(path):len(path)], key), child, value); e == nil {
+				v.SetMapIndex(k, value)
+			}
+		}
+
+		return e
+	})
+}
+
+// unmarshalStruct sets the fields of a struct from the entries of a map element keyed by keywords or strings.
+func unmarshalStruct(path []interface{}, coll CollectionElement, v reflect.Value) (err error) {
+
+	fields := map[string]int{}
+	for _, field := range structFields(v.Type()) {
+		fields[field.name] = field.index
+	}
+
+	return coll.IterateChildren(func(key Element, child Element) (e error) {
+		var name string
+		switch key.ElementType() {
+		case KeywordType:
+			name, e = keywordName(key)
+		case StringType:
+			name = key.Value().(string)
+		}
+
+		if index, has := fields[name]; has && e == nil {
+			e = unmarshalValue(append(path[:len(path):len(path)], key), child, v.Field(index))
+		}
+
+		return e
+	})
+}
+
+// keywordName returns the name of the keyword with its namespace and without the colon, e.g. "db/ident" for :db/ident.
+func keywordName(key Element) (name string, err error) {
+	if name, err = untaggedText(key); err == nil {
+		name = strings.TrimPrefix(name, KeywordPrefix)
+	}
+
+	return name, err
+}
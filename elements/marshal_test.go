@@ -0,0 +1,224 @@
+package elements
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/mattrobenolt/gocql/uuid"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// testMoney writes itself as #myapp/money "12.50 EUR".
+type testMoney struct {
+	Amount   string
+	Currency string
+}
+
+// MarshalEDN writes the money under its tag.
+func (m testMoney) MarshalEDN() (elem Element, err error) {
+	var tag SymbolElement
+	var value Element
+	if tag, err = NewSymbolElement("myapp/money"); err == nil {
+		if value, err = NewStringElement(m.Amount + " " + m.Currency); err == nil {
+			elem, err = NewTaggedElement(tag, value)
+		}
+	}
+
+	return elem, err
+}
+
+// UnmarshalEDN reads the money from its tag.
+func (m *testMoney) UnmarshalEDN(elem Element) (err error) {
+	err = ErrInvalidInput
+	if tagged, is := elem.(TaggedElement); is && tagged.Tag() == "myapp/money" {
+		if text, is := tagged.Tagged().Value().(string); is {
+			if parts := strings.Fields(text); len(parts) == 2 {
+				m.Amount, m.Currency, err = parts[0], parts[1], nil
+			}
+		}
+	}
+
+	return err
+}
+
+// testTenant wraps an element and writes itself as a keyword.
+type testTenant struct {
+	Element
+}
+
+// MarshalEDN writes the tenant as a keyword.
+func (t testTenant) MarshalEDN() (Element, error) {
+	return NewKeywordElement("tenant", t.Value().(string))
+}
+
+// testBuilder builds a vector of its values.
+type testBuilder []int64
+
+// BuildCollection creates the vector.
+func (b testBuilder) BuildCollection() (elem CollectionElement, err error) {
+	if elem, err = NewVector(); err == nil {
+		for _, i := range b {
+			if err == nil {
+				var child Element
+				if child, err = NewIntegerElement(i); err == nil {
+					err = elem.Append(child)
+				}
+			}
+		}
+	}
+
+	return elem, err
+}
+
+// testAccount is marshalled as a map.
+type testAccount struct {
+	Name     string           `edn:"account/name"`
+	Balance  testMoney        `edn:"account/balance"`
+	Previous *testMoney       `edn:"account/previous"`
+	Opened   time.Time        `edn:"account/opened"`
+	ID       uuid.UUID        `edn:"account/id"`
+	Limit    *big.Int         `edn:"account/limit"`
+	Tags     []string         `edn:"account/tags"`
+	Scores   map[string]uint8 `edn:"account/scores"`
+	Note     string           `edn:"account/note,omitempty"`
+	Secret   string           `edn:"-"`
+	Extra    Element          `edn:"account/extra"`
+	Held     Holder           `edn:"account/held"`
+	Count    int
+	ignored  bool
+	Anything interface{} `edn:"account/any"`
+}
+
+var _ = Describe("Marshalling", func() {
+
+	parse := func(src string) Element {
+		elem, err := Parse([]byte(src))
+		Ω(err).Should(BeNil(), src)
+		return elem
+	}
+
+	It("should write and read a struct", func() {
+		id, err := uuid.ParseUUID("f81d4fae-7dec-11d0-a765-00a0c91e6bf6")
+		Ω(err).Should(BeNil())
+
+		account := testAccount{
+			Name:     "x",
+			Balance:  testMoney{Amount: "12.50", Currency: "EUR"},
+			Opened:   time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+			ID:       id,
+			Limit:    big.NewInt(100),
+			Tags:     []string{"a", "b"},
+			Scores:   map[string]uint8{"q1": 3},
+			Secret:   "s",
+			Extra:    parse(`:extra`),
+			Held:     Holder{parse(`#{1}`)},
+			Count:    7,
+			ignored:  true,
+			Anything: []int{1},
+		}
+
+		data, err := Marshal(account)
+		Ω(err).Should(BeNil())
+		Ω(parse(string(data)).Equals(parse(`{:account/name "x" :account/balance #myapp/money "12.50 EUR" ` +
+			`:account/previous nil :account/opened #inst "2020-01-02T03:04:05Z" ` +
+			`:account/id #uuid "f81d4fae-7dec-11d0-a765-00a0c91e6bf6" :account/limit 100N :account/tags ["a" "b"] ` +
+			`:account/scores {"q1" 3} :account/extra :extra :account/held #{1} :Count 7 :account/any [1]}`))).Should(BeTrue())
+
+		read := testAccount{}
+		Ω(Unmarshal(data, &read)).Should(BeNil())
+		Ω(read.Name).Should(Equal(account.Name))
+		Ω(read.Balance).Should(Equal(account.Balance))
+		Ω(read.Previous).Should(BeNil())
+		Ω(read.Opened.Equal(account.Opened)).Should(BeTrue())
+		Ω(read.ID).Should(Equal(id))
+		Ω(read.Limit.Cmp(account.Limit)).Should(BeZero())
+		Ω(read.Tags).Should(Equal(account.Tags))
+		Ω(read.Scores).Should(Equal(account.Scores))
+		Ω(read.Secret).Should(BeEmpty())
+		Ω(read.Extra.Equals(account.Extra)).Should(BeTrue())
+		Ω(read.Held.Equals(account.Held.Element)).Should(BeTrue())
+		Ω(read.Count).Should(Equal(7))
+		Ω(read.Anything.(Element).Equals(parse(`[1]`))).Should(BeTrue())
+	})
+
+	It("should read into pointers, arrays and plain values", func() {
+		var money *testMoney
+		Ω(Unmarshal([]byte(`#myapp/money "1 USD"`), &money)).Should(BeNil())
+		Ω(*money).Should(Equal(testMoney{Amount: "1", Currency: "USD"}))
+
+		var array [3]int16
+		Ω(Unmarshal([]byte(`(1 2)`), &array)).Should(BeNil())
+		Ω(array).Should(Equal([3]int16{1, 2, 0}))
+
+		var f float32
+		Ω(Unmarshal([]byte(`2`), &f)).Should(BeNil())
+		Ω(f).Should(BeEquivalentTo(2))
+
+		var r rune
+		Ω(Unmarshal([]byte(`\x`), &r)).Should(BeNil())
+		Ω(r).Should(Equal('x'))
+
+		s := "set"
+		Ω(Unmarshal([]byte(`#my/tag nil`), &s)).Should(BeNil())
+		Ω(s).Should(BeEmpty())
+
+		var keys map[int64]bool
+		Ω(Unmarshal([]byte(`{1 true 2 false}`), &keys)).Should(BeNil())
+		Ω(keys).Should(Equal(map[int64]bool{1: true, 2: false}))
+
+		var names map[string]int
+		Ω(Unmarshal([]byte(`{:a 1 :db/ident 2 "c" 3}`), &names)).Should(BeNil())
+		Ω(names).Should(Equal(map[string]int{"a": 1, "db/ident": 2, "c": 3}))
+	})
+
+	It("should honour marshalers and builders when creating elements", func() {
+		elem, err := NewElement(testMoney{Amount: "1", Currency: "USD"})
+		Ω(err).Should(BeNil())
+		Ω(elem.Serialize()).Should(BeEquivalentTo(`#myapp/money "1 USD"`))
+
+		elem, err = NewElement(testBuilder{1, 2})
+		Ω(err).Should(BeNil())
+		Ω(elem.Serialize()).Should(BeEquivalentTo(`[1 2]`))
+
+		elem, err = NewElement((*testMoney)(nil))
+		Ω(err).Should(BeNil())
+		Ω(elem.ElementType()).Should(BeEquivalentTo(NilType))
+
+		tenant := testTenant{parse(`"acme"`)}
+		coll, err := NewVector(tenant, Holder{parse(`1`)})
+		Ω(err).Should(BeNil())
+		Ω(coll.Serialize()).Should(BeEquivalentTo(`[:tenant/acme 1]`))
+
+		_, err = NewVector(Holder{})
+		Ω(err).Should(BeNil())
+	})
+
+	It("should report what does not fit", func() {
+		var small int8
+		err := Unmarshal([]byte(`[300]`), &[]int8{small})
+		Ω(errors.Is(err, ErrTypeMismatch)).Should(BeTrue())
+
+		var elemErr *ElementError
+		Ω(errors.As(err, &elemErr)).Should(BeTrue())
+		Ω(elemErr.Path).Should(Equal([]interface{}{0}))
+		Ω(elemErr.Type).Should(BeEquivalentTo(IntegerType))
+
+		err = Unmarshal([]byte(`{:account/balance "12.50 EUR"}`), &testAccount{})
+		Ω(errors.Is(err, ErrInvalidInput)).Should(BeTrue())
+		Ω(err.Error()).Should(HavePrefix(`at [:account/balance]: `))
+
+		Ω(errors.Is(Unmarshal([]byte(`1`), small), ErrInvalidTarget)).Should(BeTrue())
+		Ω(errors.Is(Unmarshal([]byte(`"a"`), &small), ErrTypeMismatch)).Should(BeTrue())
+		Ω(errors.Is(Unmarshal([]byte(`-1`), new(uint)), ErrTypeMismatch)).Should(BeTrue())
+		Ω(errors.Is(Unmarshal([]byte(`{1 2}`), &map[string]int{}), ErrTypeMismatch)).Should(BeTrue())
+		Ω(UnmarshalElement(nil, &small)).Should(Equal(ErrInvalidElement))
+
+		_, err = Marshal(map[string]interface{}{"a": make(chan int)})
+		Ω(errors.Is(err, ErrUnknownType)).Should(BeTrue())
+		Ω(errors.As(err, &elemErr)).Should(BeTrue())
+		Ω(elemErr.Path).Should(HaveLen(1))
+	})
+})
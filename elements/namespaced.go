@@ -0,0 +1,108 @@
+package elements
+
+const (
+
+	// NamespacedMapPrefix is the start of a namespace qualified map, e.g. #:person{:name "x"}. Keys without a prefix
+	// are read with the namespace of the map.
+	NamespacedMapPrefix = TagPrefix + KeywordPrefix
+
+	// NoNamespacePrefix marks a key in a namespace qualified map that has no prefix, e.g. :_/name.
+	NoNamespacePrefix = "_"
+)
+
+// SerializeNamespaced writes the element like Serialize, except that maps whose keys are all keywords in the same
+// namespace are written with the namespace qualified map syntax, e.g. {:person/name "x"} as #:person{:name "x"}. This
+// applies to maps at any depth.
+func SerializeNamespaced(elem Element) (composition string, err error) {
+	var out []byte
+	if out, err = appendNamespaced(nil, elem); err == nil {
+		composition = string(out)
+	}
+	return composition, err
+}
+
+// appendNamespaced appends the element like AppendEDN, with the namespace qualified map syntax of SerializeNamespaced.
+func appendNamespaced(dst []byte, elem Element) (out []byte, err error) {
+
+	out = dst
+	if coll, is := elem.(*collectionElemImpl); is {
+		if coll.HasTag() {
+			out = append(append(append(out, TagPrefix...), coll.Tag()...), ' ')
+		}
+
+		_, hasKey := coll.collection.(map[string]Pair)
+		keyAppender := appendNamespaced
+		if ns, has := mapNamespace(coll); has {
+			out = append(append(out, NamespacedMapPrefix...), ns...)
+			keyAppender = appendUnqualifiedKey
+		}
+
+		out, err = appendCollection(out, coll, hasKey, keyAppender, appendNamespaced)
+	} else if tagged, is := elem.(TaggedElement); is {
+		out = append(append(append(out, TagPrefix...), tagged.Tag()...), ' ')
+		out, err = appendNamespaced(out, tagged.Tagged())
+	} else {
+		out, err = elem.AppendEDN(out)
+	}
+
+	return out, err
+}
+
+// mapNamespace returns the namespace shared by every key of the map. Only untagged keywords share a namespace.
+func mapNamespace(coll *collectionElemImpl) (ns string, has bool) {
+	if pairs, is := coll.collection.(map[string]Pair); is && len(pairs) > 0 {
+		has = true
+		for _, pair := range pairs {
+			key, is := pair.Key().(SymbolElement)
+			switch {
+			case !has:
+			case !is || key.ElementType() != KeywordType || key.HasTag() || len(key.Prefix()) == 0:
+				has = false
+			case len(ns) == 0:
+				ns = key.Prefix()
+			case ns != key.Prefix():
+				has = false
+			}
+		}
+	}
+
+	return ns, has
+}
+
+// appendUnqualifiedKey appends a keyword key of a namespace qualified map without its prefix.
+func appendUnqualifiedKey(dst []byte, key Element) (out []byte, err error) {
+	sym := key.(SymbolElement)
+	out = append(append(append(dst, sym.Modifier()...), sym.Direction()...), sym.Name()...)
+	return out, err
+}
+
+// qualifyKey applies the namespace of a namespace qualified map to a key. Keywords and symbols without a prefix take the
+// namespace, the ones with the _ prefix lose it, and any other key is kept as it is.
+func qualifyKey(key Element, ns string) (qualified Element, err error) {
+
+	qualified = key
+	if sym, is := key.(SymbolElement); is && (len(sym.Prefix()) == 0 || sym.Prefix() == NoNamespacePrefix) {
+		parts := []string{ns, sym.Name()}
+		if len(sym.Prefix()) != 0 {
+			parts = parts[1:]
+		}
+
+		var elem SymbolElement
+		if sym.ElementType() == KeywordType {
+			elem, err = NewKeywordElement(parts...)
+		} else {
+			elem, err = NewSymbolElement(parts...)
+		}
+
+		if err == nil && sym.HasTag() {
+			err = elem.SetTag(sym.Tag())
+		}
+
+		if err == nil {
+			elem.(spanner).setSpan(key.Span())
+			qualified = elem
+		}
+	}
+
+	return qualified, err
+}
@@ -0,0 +1,122 @@
+package elements
+
+import (
+	"bytes"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Namespace qualified maps", func() {
+
+	Context("reading", func() {
+
+		It("should apply the namespace to the keys without a prefix", func() {
+			elem, err := Parse([]byte(`#:person{:name "x" :email "y"}`))
+			Ω(err).Should(BeNil())
+
+			expected, err := Parse([]byte(`{:person/name "x" :person/email "y"}`))
+			Ω(err).Should(BeNil())
+			Ω(elem.Equals(expected)).Should(BeTrue())
+		})
+
+		It("should keep prefixed keys, drop the _ prefix and leave other keys alone", func() {
+			elem, err := Parse([]byte(`#:person {:name 1 :db/id 2 :_/plain 3 name 4 "str" 5}`))
+			Ω(err).Should(BeNil())
+
+			expected, err := Parse([]byte(`{:person/name 1 :db/id 2 :plain 3 person/name 4 "str" 5}`))
+			Ω(err).Should(BeNil())
+			Ω(elem.Equals(expected)).Should(BeTrue())
+		})
+
+		It("should keep the span of the qualified keys", func() {
+			elem, err := Parse([]byte(`#:a{:b 1}`))
+			Ω(err).Should(BeNil())
+
+			err = elem.(CollectionElement).IterateChildren(func(key Element, _ Element) error {
+				Ω(key.Span().Start.Offset).Should(BeEquivalentTo(4))
+				Ω(key.Span().End.Offset).Should(BeEquivalentTo(6))
+				return nil
+			})
+			Ω(err).Should(BeNil())
+		})
+
+		It("should report keys that collide once qualified", func() {
+			_, err := Parse([]byte(`#:a{:b 1 :a/b 2}`))
+			Ω(errors.Is(err, ErrDuplicateKey)).Should(BeTrue())
+		})
+
+		It("should read #::{} maps in the current namespace", func() {
+			dec := NewDecoder(bytes.NewBufferString(`#::{:name "x"}`))
+			dec.SetNamespace("person")
+
+			elem, err := dec.Decode()
+			Ω(err).Should(BeNil())
+
+			expected, err := Parse([]byte(`{:person/name "x"}`))
+			Ω(err).Should(BeNil())
+			Ω(elem.Equals(expected)).Should(BeTrue())
+		})
+
+		It("should reject invalid namespaces", func() {
+			for _, src := range []string{`#::{:a 1}`, `#::alias{:a 1}`, `#:{:a 1}`, `#:1a{:a 1}`, `#:a/b{:a 1}`, `#:a [1]`, `#:a`} {
+				_, err := Parse([]byte(src))
+				Ω(errors.Is(err, ErrInvalidTag)).Should(BeTrue(), src)
+			}
+		})
+
+		It("should reject maps with a missing value", func() {
+			_, err := Parse([]byte(`#:a{:b}`))
+			Ω(errors.Is(err, ErrInvalidPair)).Should(BeTrue())
+		})
+	})
+
+	Context("writing", func() {
+
+		It("should write maps whose keys share a namespace with the namespace syntax", func() {
+			elem, err := Parse([]byte(`{:person/name "x"}`))
+			Ω(err).Should(BeNil())
+
+			Ω(SerializeNamespaced(elem)).Should(BeEquivalentTo(`#:person{:name "x"}`))
+			Ω(elem.Serialize()).Should(BeEquivalentTo(`{:person/name "x"}`))
+		})
+
+		It("should write nested maps and keep tags and directions", func() {
+			elem, err := Parse([]byte(`#my/tag [{:person/friend #{1}} {:a/b 1 :c/d 2} {"a" 1} {}]`))
+			Ω(err).Should(BeNil())
+
+			friends, err := GetIn(elem, 0)
+			Ω(err).Should(BeNil())
+			friends.(CollectionElement).IterateChildren(func(key Element, _ Element) error {
+				key.(KeywordElement).SetDirection(ReverseDirection)
+				return nil
+			})
+
+			out, err := SerializeNamespaced(elem)
+			Ω(err).Should(BeNil())
+			Ω(out).Should(HavePrefix(`#my/tag [#:person{:_friend #{1}} {`))
+			Ω(out).Should(HaveSuffix(`} {"a" 1} {}]`))
+		})
+
+		It("should not use the namespace syntax for tagged or unqualified keys", func() {
+			for _, src := range []string{`{:a 1}`, `{#t :a/b 1}`, `{a/b 1}`} {
+				elem, err := Parse([]byte(src))
+				Ω(err).Should(BeNil())
+				Ω(SerializeNamespaced(elem)).Should(BeEquivalentTo(src))
+			}
+		})
+
+		It("should read back what it writes", func() {
+			elem, err := Parse([]byte(`{:person/name "x" :person/email "y" :person/address {:address/city "z"}}`))
+			Ω(err).Should(BeNil())
+
+			out, err := SerializeNamespaced(elem)
+			Ω(err).Should(BeNil())
+
+			back, err := Parse([]byte(out))
+			Ω(err).Should(BeNil())
+			Ω(back.Equals(elem)).Should(BeTrue())
+		})
+	})
+})
@@ -6,8 +6,8 @@ const (
 )
 
 // initNil will add the element factory to the collection of factories
-func initNil() error {
-	return AddElementTypeFactory(NilType, func(input interface{}) (elem Element, err error) {
+func initNil(registry *Registry) error {
+	return registry.AddFactory(NilType, func(input interface{}) (elem Element, err error) {
 		if input == nil {
 			elem, err = NewNilElement()
 		} else {
@@ -19,8 +19,8 @@ func initNil() error {
 
 // NewNilElement returns the nil element or an error.
 func NewNilElement() (elem Element, err error) {
-	elem, err = makeBaseElement(nil, NilType, func(value interface{}) (string, error) {
-		return NilLiteral, nil
+	elem, err = makeBaseElement(nil, NilType, func(dst []byte, value interface{}) ([]byte, error) {
+		return append(dst, NilLiteral...), nil
 	})
 	return elem, err
 }
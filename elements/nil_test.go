@@ -9,13 +9,14 @@ var _ = Describe("Nil in EDN", func() {
 	Context("", func() {
 
 		It("should initialize without issue", func() {
-			delete(typeFactories, NilType)
-			err := initNil()
+			registry := NewRegistry()
+			registry.RemoveFactory(NilType)
+			err := initNil(registry)
 			Ω(err).Should(BeNil())
-			_, has := typeFactories[NilType]
+			_, has := registry.Factory(NilType)
 			Ω(has).Should(BeTrue())
 
-			err = initNil()
+			err = initNil(registry)
 			Ω(err).ShouldNot(BeNil())
 			Ω(err).Should(BeEquivalentTo(ErrInvalidFactory))
 		})
@@ -23,7 +24,10 @@ var _ = Describe("Nil in EDN", func() {
 		It("should create elements from the factory", func() {
 			var v interface{}
 
-			elem, err := typeFactories[NilType](v)
+			factory, has := defaultRegistry.Factory(NilType)
+			Ω(has).Should(BeTrue())
+
+			elem, err := factory(v)
 			Ω(err).Should(BeNil())
 			Ω(elem.ElementType()).Should(BeEquivalentTo(NilType))
 			Ω(elem.Value()).Should(BeNil())
@@ -32,7 +36,10 @@ var _ = Describe("Nil in EDN", func() {
 		It("should not create elements from the factory if the input is not a the right type", func() {
 			v := "foo"
 
-			elem, err := typeFactories[NilType](v)
+			factory, has := defaultRegistry.Factory(NilType)
+			Ω(has).Should(BeTrue())
+
+			elem, err := factory(v)
 			Ω(err).ShouldNot(BeNil())
 			Ω(err).Should(BeEquivalentTo(ErrInvalidInput))
 			Ω(elem).Should(BeNil())
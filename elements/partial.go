@@ -0,0 +1,311 @@
+package elements
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"unicode/utf8"
+)
+
+// ParseIn reads the element at the end of the path from the source, like GetIn on the result of Parse, but without
+// building the elements that are off the path. Maps and sequences along the path are read one child at a time, and
+// their other children are skipped over, so picking one key out of a large response costs little more than a scan of
+// the source. Only the delimiters of skipped elements are checked. Sets, namespace qualified maps and tags with a
+// handler are read in full before the rest of the path is followed in them.
+func ParseIn(src []byte, path ...interface{}) (elem Element, err error) {
+	return defaultRegistry.ParseIn(src, path...)
+}
+
+// DecodeIn reads the element at the end of the path from the next element of the input, skipping over the rest of it
+// like ParseIn. A PathError leaves the decoder at the element after, so the stream can still be read. When there are no
+// more elements io.EOF is returned.
+func (dec *Decoder) DecodeIn(path ...interface{}) (elem Element, err error) {
+	if err = dec.open(); err == nil {
+		elem, err = dec.rd.nextIn(path)
+	}
+
+	return elem, err
+}
+
+// nextIn skips the trivia and reads the element at the end of the path from the next element, moving past the rest of
+// it. io.EOF is returned if there is none.
+func (rd *reader) nextIn(path []interface{}) (elem Element, err error) {
+	depth := rd.depth
+
+	if err = rd.checkInput(); err == nil {
+		if err = rd.skipTrivia(); err == nil && rd.atEnd() {
+			err = io.EOF
+		}
+	}
+
+	// the closing delimiters of the collections entered along the path.
+	var closers []byte
+	for i := 0; err == nil && elem == nil; {
+		switch c := rd.src[rd.offset]; {
+		case i == len(path):
+			elem, err = rd.next()
+
+		case c == '(' || c == '[' || c == '{':
+			start := rd.offset
+			rd.offset++
+			closer := closerFor(c)
+			closers = append(closers, closer)
+
+			if c == '{' {
+				err = rd.seekKey(start, path[i])
+			} else {
+				err = rd.seekIndex(start, path[i], closer)
+			}
+			err = rd.enter(path, i, err)
+			i++
+
+		case c == '#' && rd.offset+1 < len(rd.src) && rd.src[rd.offset+1] != '{' && rd.src[rd.offset+1] != ':':
+			start := rd.offset
+			var tag string
+			if tag, err = rd.tag(); err == nil {
+				if _, has := rd.registry.TagHandler(tag); has || tag == InstantElementTag || tag == UUIDElementTag {
+					rd.offset = start
+					elem, err = rd.restIn(path, i)
+				} else {
+					err = rd.enter(path, i, nil)
+				}
+			}
+
+		default:
+			elem, err = rd.restIn(path, i)
+		}
+	}
+
+	if _, isPath := err.(*PathError); err == nil || isPath {
+		if e := rd.skip(0, closers); e != nil {
+			elem, err = nil, e
+		}
+	}
+	rd.depth = depth
+
+	return elem, err
+}
+
+// enter goes one level deeper along the path after the segment at the index has been looked up, turning a failed lookup
+// into a PathError.
+func (rd *reader) enter(path []interface{}, index int, lookupErr error) (err error) {
+	if _, isParse := lookupErr.(*ParseError); isParse {
+		err = lookupErr
+	} else if lookupErr != nil {
+		err = &PathError{Path: path, Index: index, Err: lookupErr}
+	} else if exceeds(rd.depth+1, rd.limits.MaxDepth) {
+		err = rd.failLimit(rd.offset, "nesting depth", rd.limits.MaxDepth, ErrDepthLimit)
+	} else {
+		rd.depth++
+	}
+
+	return err
+}
+
+// restIn reads the next element in full and follows the path from the segment at the index with GetIn.
+func (rd *reader) restIn(path []interface{}, index int) (elem Element, err error) {
+	var whole Element
+	if whole, err = rd.next(); err == nil {
+		if elem, err = GetIn(whole, path[index:]...); err != nil {
+			if pathErr, is := err.(*PathError); is {
+				err = &PathError{Path: path, Index: index + pathErr.Index, Err: pathErr.Err}
+			}
+		}
+	}
+
+	return elem, err
+}
+
+// seekIndex moves to the child of the sequence at the index of the segment, skipping the children before it. The
+// opening delimiter at start has already been read.
+func (rd *reader) seekIndex(start int, segment interface{}, closer byte) (err error) {
+	index, ok := segmentIndex(segment)
+	if !ok {
+		err = ErrInvalidKey
+	} else if index < 0 {
+		err = ErrIndexOutOfRange
+	}
+
+	for i := 0; err == nil && i <= index; i++ {
+		if err = rd.skipChildTrivia(start, closer); err == nil {
+			if rd.src[rd.offset] == closer {
+				err = ErrIndexOutOfRange
+			} else if i < index {
+				err = rd.skip(1, nil)
+			}
+		}
+	}
+
+	return err
+}
+
+// seekKey moves to the value of the map under the key of the segment, skipping the other entries. The opening
+// delimiter at start has already been read.
+func (rd *reader) seekKey(start int, segment interface{}) (err error) {
+	var wanted Element
+	wanted, err = segmentElement(segment)
+
+	for found := false; err == nil && !found; {
+		if err = rd.skipChildTrivia(start, '}'); err == nil && rd.src[rd.offset] == '}' {
+			err = ErrNoValue
+		}
+
+		var key Element
+		if err == nil {
+			if key, err = rd.descend(); err == nil {
+				err = rd.skipChildTrivia(start, '}')
+			}
+		}
+
+		switch {
+		case err != nil:
+		case rd.src[rd.offset] == '}':
+			err = rd.fail(rd.offset, "value for the key", strconv.Quote(MapEndLiteral), ErrInvalidPair)
+		case key.Equals(wanted):
+			found = true
+		default:
+			err = rd.skip(1, nil)
+		}
+	}
+
+	return err
+}
+
+// skipChildTrivia moves past the trivia to the next child of the collection that opened at start, or to its closing
+// delimiter, and fails at the end of the source or on a delimiter that does not close it.
+func (rd *reader) skipChildTrivia(start int, closer byte) (err error) {
+	if err = rd.skipTrivia(); err == nil {
+		switch {
+		case rd.atEnd():
+			err = rd.fail(start, fmt.Sprintf("%q to close", closer), endOfInput, ErrUnexpectedEnd)
+		case rd.src[rd.offset] != closer && isClose(rd.src[rd.offset]):
+			err = rd.fail(rd.offset, strconv.Quote(string(closer)), strconv.Quote(string(rd.src[rd.offset])), ErrUnexpectedDelimiter)
+		}
+	}
+
+	return err
+}
+
+// skip moves past count elements without building them, and then past the ends of the open collections, whose closing
+// delimiters are given outermost first. Only the delimiters of what is skipped are checked.
+func (rd *reader) skip(count int, open []byte) (err error) {
+	closers := append([]byte(nil), open...)
+
+	for err == nil && (count > 0 || len(closers) > 0) {
+		if rd.atEnd() {
+			expected := "element"
+			if len(closers) > 0 {
+				expected = fmt.Sprintf("%q to close", closers[len(closers)-1])
+			}
+			err = rd.fail(rd.offset, expected, endOfInput, ErrUnexpectedEnd)
+			break
+		}
+
+		complete := false
+		switch c := rd.src[rd.offset]; {
+		case isWhitespace(c):
+			rd.offset++
+
+		case c == ';':
+			for !rd.atEnd() && rd.src[rd.offset] != '\n' {
+				rd.offset++
+			}
+
+		case c == '#' && rd.offset+1 < len(rd.src) && rd.src[rd.offset+1] == '_':
+			rd.offset += 2
+			if len(closers) == 0 {
+				count++
+			}
+
+		case c == '#' && rd.offset+1 < len(rd.src) && rd.src[rd.offset+1] == '{':
+			rd.offset += len(SetStartLiteral)
+			closers = append(closers, '}')
+
+		case c == '(' || c == '[' || c == '{':
+			rd.offset++
+			closers = append(closers, closerFor(c))
+
+		case isClose(c):
+			if len(closers) == 0 {
+				err = rd.fail(rd.offset, "element", strconv.Quote(string(c)), ErrUnexpectedDelimiter)
+			} else if closer := closers[len(closers)-1]; closer != c {
+				err = rd.fail(rd.offset, strconv.Quote(string(closer)), strconv.Quote(string(c)), ErrUnexpectedDelimiter)
+			} else {
+				rd.offset++
+				closers = closers[:len(closers)-1]
+				complete = true
+			}
+
+		case c == '"':
+			err = rd.skipString()
+			complete = true
+
+		case c == '\\':
+			rd.offset++
+			if !rd.atEnd() {
+				_, size := utf8.DecodeRune(rd.src[rd.offset:])
+				rd.offset += size
+			}
+			rd.skipToken()
+			complete = true
+
+		case c == '#':
+			// tags and namespaces belong to the element that follows them.
+			rd.offset++
+			rd.skipToken()
+
+		default:
+			rd.skipToken()
+			complete = true
+		}
+
+		if complete && len(closers) == 0 {
+			count--
+		}
+	}
+
+	return err
+}
+
+// closerFor returns the closing delimiter of the collection that the byte opens.
+func closerFor(opener byte) (closer byte) {
+	switch opener {
+	case '(':
+		closer = ')'
+	case '[':
+		closer = ']'
+	default:
+		closer = '}'
+	}
+
+	return closer
+}
+
+// skipToken moves past the rest of the token.
+func (rd *reader) skipToken() {
+	for !rd.atEnd() && !isTerminator(rd.src[rd.offset]) {
+		rd.offset++
+	}
+}
+
+// skipString moves past the string literal that starts at the offset.
+func (rd *reader) skipString() (err error) {
+	start := rd.offset
+	rd.offset++
+
+	for closed := false; err == nil && !closed; {
+		switch {
+		case rd.offset >= len(rd.src):
+			err = rd.fail(start, "closing quote", endOfInput, ErrUnexpectedEnd)
+		case rd.src[rd.offset] == '\\' && rd.offset+1 < len(rd.src):
+			rd.offset += 2
+		case rd.src[rd.offset] == '"':
+			rd.offset++
+			closed = true
+		default:
+			rd.offset++
+		}
+	}
+
+	return err
+}
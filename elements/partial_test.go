@@ -0,0 +1,147 @@
+package elements
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reading part of EDN", func() {
+
+	report := `{:db-before {:basis-t 1000 :log [#datom [1 2 3] "skipped \" ]"]}
+	            :tx-data [#datom [13194139534313 50 #inst "2020-01-02T03:04:05Z"] ; a comment ]
+	                      #_ [ignored] #datom [17 10 "x"]]
+	            :tempids {"a" 17 "b" 18}}`
+
+	It("should pick the element at the end of the path", func() {
+		elem, err := ParseIn([]byte(report), ":tempids")
+		Ω(err).Should(BeNil())
+		Ω(elem.Equals(mustParse(`{"a" 17 "b" 18}`))).Should(BeTrue())
+
+		elem, err = ParseIn([]byte(report), ":tx-data", 1, 2)
+		Ω(err).Should(BeNil())
+		Ω(elem.Value()).Should(Equal("x"))
+		Ω(elem.Span().Start.Line).Should(Equal(3))
+
+		elem, err = ParseIn([]byte(report), ":tempids", "b")
+		Ω(err).Should(BeNil())
+		Ω(elem.Value()).Should(BeEquivalentTo(18))
+
+		elem, err = ParseIn([]byte(report))
+		Ω(err).Should(BeNil())
+		Ω(elem.ElementType()).Should(BeEquivalentTo(MapType))
+	})
+
+	It("should follow the path through sets, tags and characters", func() {
+		elem, err := ParseIn([]byte(`[\] (1 #_ 2 3) #{:a :b} #my/tag {:x [4]}]`), 1, 1)
+		Ω(err).Should(BeNil())
+		Ω(elem.Value()).Should(BeEquivalentTo(3))
+
+		elem, err = ParseIn([]byte(`[\] (1 #_ 2 3) #{:a :b} #my/tag {:x [4]}]`), 2, ":b")
+		Ω(err).Should(BeNil())
+		Ω(elem.Equals(mustParse(`:b`))).Should(BeTrue())
+
+		elem, err = ParseIn([]byte(`[\] (1 #_ 2 3) #{:a :b} #my/tag {:x [4]}]`), 3, ":x", 0)
+		Ω(err).Should(BeNil())
+		Ω(elem.Value()).Should(BeEquivalentTo(4))
+	})
+
+	It("should not build what it skips", func() {
+		handled := 0
+		registry := NewRegistry()
+		Ω(registry.AddTagHandler("count", func(value Element) (Element, error) {
+			handled++
+			return value, nil
+		})).Should(BeNil())
+
+		elem, err := registry.ParseIn([]byte(`[#count 1 1.2.3 #count {:x #count 2}]`), 2, ":x")
+		Ω(err).Should(BeNil())
+		Ω(elem.Value()).Should(BeEquivalentTo(2))
+		Ω(handled).Should(Equal(2))
+	})
+
+	It("should report where the path fails", func() {
+		failures := map[string]struct {
+			path   []interface{}
+			reason error
+			index  int
+		}{
+			`{:a 1}`:          {[]interface{}{":b"}, ErrNoValue, 0},
+			`{:a [1]}`:        {[]interface{}{":a", 1}, ErrIndexOutOfRange, 1},
+			`{:a [1 2]}`:      {[]interface{}{":a", 0, 0}, ErrWrongCollection, 2},
+			`[1]`:             {[]interface{}{":a"}, ErrInvalidKey, 0},
+			`#{1}`:            {[]interface{}{2}, ErrNoValue, 0},
+			`#my/tag [1 [2]]`: {[]interface{}{1, 0, 0}, ErrWrongCollection, 2},
+		}
+
+		for src, failure := range failures {
+			_, err := ParseIn([]byte(src), failure.path...)
+			Ω(errors.Is(err, failure.reason)).Should(BeTrue(), src)
+
+			var pathErr *PathError
+			Ω(errors.As(err, &pathErr)).Should(BeTrue(), src)
+			Ω(pathErr.Index).Should(Equal(failure.index), src)
+		}
+	})
+
+	It("should report malformed input", func() {
+		_, err := ParseIn([]byte(`[{:a [1} 2]`), 1)
+		Ω(errors.Is(err, ErrUnexpectedDelimiter)).Should(BeTrue())
+
+		_, err = ParseIn([]byte(`[1 "2]`), 0)
+		Ω(errors.Is(err, ErrUnexpectedEnd)).Should(BeTrue())
+
+		_, err = ParseIn([]byte(`{:a 1 :b}`), ":c")
+		Ω(errors.Is(err, ErrInvalidPair)).Should(BeTrue())
+
+		_, err = ParseIn([]byte(`{:a 1} 2`), ":a")
+		Ω(errors.Is(err, ErrTrailingInput)).Should(BeTrue())
+
+		_, err = ParseIn([]byte(` `), ":a")
+		Ω(errors.Is(err, ErrUnexpectedEnd)).Should(BeTrue())
+	})
+
+	It("should take an empty string as a string key", func() {
+		elem, err := ParseIn([]byte(`{:a 1 "" 2}`), "")
+		Ω(err).Should(BeNil())
+		Ω(elem.Value()).Should(BeEquivalentTo(2))
+
+		_, err = ParseIn([]byte(`{:a 1}`), "")
+		Ω(errors.Is(err, ErrNoValue)).Should(BeTrue())
+
+		dec := NewDecoder(strings.NewReader(`{"" [3]}`))
+		elem, err = dec.DecodeIn("", 0)
+		Ω(err).Should(BeNil())
+		Ω(elem.Value()).Should(BeEquivalentTo(3))
+	})
+
+	It("should decode the path from each element of a stream", func() {
+		dec := NewDecoder(strings.NewReader(`{:a 1} {:b [2]} {:a 3}`))
+
+		elem, err := dec.DecodeIn(":a")
+		Ω(err).Should(BeNil())
+		Ω(elem.Value()).Should(BeEquivalentTo(1))
+
+		_, err = dec.DecodeIn(":a")
+		Ω(errors.Is(err, ErrNoValue)).Should(BeTrue())
+
+		elem, err = dec.DecodeIn(":a")
+		Ω(err).Should(BeNil())
+		Ω(elem.Value()).Should(BeEquivalentTo(3))
+
+		_, err = dec.DecodeIn(":a")
+		Ω(err).Should(Equal(io.EOF))
+	})
+})
+
+// mustParse reads the element or panics.
+func mustParse(src string) Element {
+	elem, err := Parse([]byte(src))
+	if err != nil {
+		panic(err)
+	}
+	return elem
+}
@@ -0,0 +1,263 @@
+package elements
+
+import (
+	"fmt"
+)
+
+const (
+
+	// ErrWrongCollection defines the error for a path segment applied to an element that cannot hold it, such as a
+	// scalar or a set.
+	ErrWrongCollection = Error("Wrong collection type")
+
+	// ErrIndexOutOfRange defines the error for an index past the end of a sequence.
+	ErrIndexOutOfRange = Error("Index out of range")
+
+	// ErrInvalidKey defines the error for a path segment that cannot be used as a key of the collection, such as a
+	// keyword used on a vector.
+	ErrInvalidKey = Error("Invalid key for the collection")
+)
+
+// PathError defines the failure to follow a path, it records the segment that failed and why.
+type PathError struct {
+
+	// Path being followed.
+	Path []interface{}
+
+	// Index of the segment that failed.
+	Index int
+
+	// Err is the reason, one of ErrNoValue, ErrWrongCollection, ErrIndexOutOfRange, ErrInvalidKey or an error from the
+	// update function.
+	Err error
+}
+
+// Error returns the error message.
+func (e *PathError) Error() string {
+	return fmt.Sprintf("path %s failed at segment %d (%s): %s", formatPath(e.Path), e.Index, formatValue(e.Segment()), e.Err)
+}
+
+// Unwrap returns the reason.
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// Segment returns the path segment that failed.
+func (e *PathError) Segment() (segment interface{}) {
+	if e.Index >= 0 && e.Index < len(e.Path) {
+		segment = e.Path[e.Index]
+	}
+	return segment
+}
+
+// UpdateFunc computes the new value from the current value at a path. The current value is nil if there is none.
+type UpdateFunc func(current Element) (updated Element, err error)
+
+// GetIn follows the path through maps, vectors, lists and sets and returns the element at the end. Segments can be
+// elements or native values: integers index sequences, strings starting with : are keywords, other strings are
+// strings. Sets are looked up by membership.
+func GetIn(elem Element, path ...interface{}) (value Element, err error) {
+	value = elem
+	for i := 0; err == nil && i < len(path); i++ {
+		if value, err = lookup(value, path[i]); err != nil {
+			err = &PathError{Path: path, Index: i, Err: err}
+			value = nil
+		}
+	}
+
+	return value, err
+}
+
+// AssocIn returns a copy of the element with the value set at the end of the path. Only the collections along the
+// path are copied, everything else is shared with the original. Missing map entries along the path are created as
+// maps, and an index equal to the length of a sequence appends to it. The value may be an element or a native value.
+func AssocIn(elem Element, value interface{}, path ...interface{}) (result Element, err error) {
+	return UpdateIn(elem, func(Element) (Element, error) {
+		return NewElement(value)
+	}, path...)
+}
+
+// UpdateIn returns a copy of the element with the value at the end of the path replaced by the result of the function.
+// Copies are made as with AssocIn.
+func UpdateIn(elem Element, fn UpdateFunc, path ...interface{}) (result Element, err error) {
+	return updateIn(elem, fn, path, 0)
+}
+
+// updateIn applies the update at the segment index and rebuilds the collections on the way back up.
+func updateIn(elem Element, fn UpdateFunc, path []interface{}, index int) (result Element, err error) {
+	if index == len(path) {
+		if result, err = fn(elem); err == nil && result == nil {
+			err = ErrInvalidElement
+		}
+		if err != nil {
+			err = &PathError{Path: path, Index: len(path) - 1, Err: err}
+		}
+	} else {
+
+		// missing entries along the path become maps.
+		if elem == nil {
+			elem, err = NewMap()
+		}
+
+		var current Element
+		if err == nil {
+			if current, err = lookup(elem, path[index]); err == ErrNoValue || err == ErrIndexOutOfRange {
+				err = nil
+			}
+		}
+
+		var updated Element
+		if err == nil {
+			if updated, err = updateIn(current, fn, path, index+1); err == nil {
+				if result, err = replaceChild(elem, path[index], updated); err != nil {
+					err = &PathError{Path: path, Index: index, Err: err}
+				}
+			}
+		} else {
+			err = &PathError{Path: path, Index: index, Err: err}
+		}
+	}
+
+	return result, err
+}
+
+// segmentIndex converts the segment to a sequence index.
+func segmentIndex(segment interface{}) (index int, ok bool) {
+	ok = true
+	switch v := segment.(type) {
+	case int:
+		index = v
+	case int32:
+		index = int(v)
+	case int64:
+		index = int(v)
+	case Element:
+		if v.ElementType() == IntegerType {
+			index = int(v.Value().(int64))
+		} else {
+			ok = false
+		}
+	default:
+		ok = false
+	}
+
+	return index, ok
+}
+
+// segmentElement converts the segment to an element.
+func segmentElement(segment interface{}) (key Element, err error) {
+	switch v := segment.(type) {
+	case int:
+		key, err = NewIntegerElement(int64(v))
+	default:
+		key, err = NewElement(v)
+	}
+
+	if err != nil {
+		err = ErrInvalidKey
+	}
+
+	return key, err
+}
+
+// lookup finds the child of the element for a single segment. Tags are looked through.
+func lookup(elem Element, segment interface{}) (value Element, err error) {
+
+	if tagged, is := elem.(TaggedElement); is {
+		value, err = lookup(tagged.Tagged(), segment)
+	} else if coll, is := elem.(CollectionElement); is {
+		switch coll.ElementType() {
+		case MapType:
+			var key Element
+			if key, err = segmentElement(segment); err == nil {
+				value, err = coll.Get(key)
+			}
+
+		case SetType:
+			var key Element
+			if key, err = segmentElement(segment); err == nil {
+				err = ErrNoValue
+				coll.IterateChildren(func(_ Element, member Element) (e error) {
+					if member.Equals(key) {
+						value, err = member, nil
+						e = StopWalk
+					}
+					return e
+				})
+			}
+
+		default:
+			if index, ok := segmentIndex(segment); !ok {
+				err = ErrInvalidKey
+			} else if index < 0 || index >= coll.Len() {
+				err = ErrIndexOutOfRange
+			} else {
+				value, err = coll.Get(index)
+			}
+		}
+	} else {
+		err = ErrWrongCollection
+	}
+
+	return value, err
+}
+
+// replaceChild returns a copy of the collection with the child for the segment set to the value. A tagged collection is
+// copied with its tag.
+func replaceChild(elem Element, segment interface{}, value Element) (result Element, err error) {
+
+	if tagged, is := elem.(TaggedElement); is {
+		var inner Element
+		if inner, err = replaceChild(tagged.Tagged(), segment, value); err == nil {
+			result, err = NewTaggedElement(tagged.TagSymbol(), inner)
+		}
+	} else {
+		result, err = replaceCollectionChild(elem, segment, value)
+	}
+
+	return result, err
+}
+
+// replaceCollectionChild returns a copy of the collection with the child for the segment set to the value.
+func replaceCollectionChild(elem Element, segment interface{}, value Element) (result Element, err error) {
+
+	var children []Element
+	coll, is := elem.(CollectionElement)
+	if !is || coll.ElementType() == SetType {
+		err = ErrWrongCollection
+	} else if children, err = collectionChildren(coll); err == nil {
+		if coll.ElementType() == MapType {
+			var key Element
+			if key, err = segmentElement(segment); err == nil {
+				found := false
+				for i := 0; i < len(children); i += 2 {
+					if children[i].Equals(key) {
+						children[i+1] = value
+						found = true
+						break
+					}
+				}
+
+				if !found {
+					children = append(children, key, value)
+				}
+			}
+		} else {
+			if index, ok := segmentIndex(segment); !ok {
+				err = ErrInvalidKey
+			} else if index < 0 || index > len(children) {
+				err = ErrIndexOutOfRange
+			} else if index == len(children) {
+				children = append(children, value)
+			} else {
+				children[index] = value
+			}
+		}
+	}
+
+	if err == nil {
+		result, err = rebuildCollection(coll, children)
+	}
+
+	return result, err
+}
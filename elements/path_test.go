@@ -0,0 +1,183 @@
+package elements
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Paths through element trees", func() {
+
+	parse := func(src string) Element {
+		elem, err := Parse([]byte(src))
+		Ω(err).Should(BeNil())
+		return elem
+	}
+
+	serialize := func(elem Element) string {
+		str, err := elem.Serialize()
+		Ω(err).Should(BeNil())
+		return str
+	}
+
+	report := parse(`{:tx-data [[1 :a] [2 :b] [3 :c] {:db/id 17}] :tempids {"tmp" 42 1 "one"} :tags #{:x :y} :log (:first)}`)
+
+	Context("with GetIn", func() {
+		It("should follow native and element segments", func() {
+			value, err := GetIn(report, ":tx-data", 3, ":db/id")
+			Ω(err).Should(BeNil())
+			Ω(value.Value()).Should(BeEquivalentTo(17))
+
+			key, err := NewKeywordElement("tx-data")
+			Ω(err).Should(BeNil())
+			index, err := NewIntegerElement(1)
+			Ω(err).Should(BeNil())
+
+			value, err = GetIn(report, key, index, 1)
+			Ω(err).Should(BeNil())
+			Ω(serialize(value)).Should(BeEquivalentTo(":b"))
+
+			value, err = GetIn(report, ":tempids", "tmp")
+			Ω(err).Should(BeNil())
+			Ω(value.Value()).Should(BeEquivalentTo(42))
+
+			value, err = GetIn(report, ":tempids", 1)
+			Ω(err).Should(BeNil())
+			Ω(value.Value()).Should(BeEquivalentTo("one"))
+
+			value, err = GetIn(report, ":tags", ":y")
+			Ω(err).Should(BeNil())
+			Ω(serialize(value)).Should(BeEquivalentTo(":y"))
+
+			value, err = GetIn(report, ":log", 0)
+			Ω(err).Should(BeNil())
+			Ω(serialize(value)).Should(BeEquivalentTo(":first"))
+
+			value, err = GetIn(report)
+			Ω(err).Should(BeNil())
+			Ω(value).Should(BeIdenticalTo(report))
+		})
+
+		It("should report the failing segment and why", func() {
+			failures := []struct {
+				path  []interface{}
+				index int
+				err   error
+			}{
+				{[]interface{}{":tx-data", 3, ":missing"}, 2, ErrNoValue},
+				{[]interface{}{":tx-data", 9}, 1, ErrIndexOutOfRange},
+				{[]interface{}{":tx-data", ":first"}, 1, ErrInvalidKey},
+				{[]interface{}{":tx-data", 0, 0, 1}, 3, ErrWrongCollection},
+				{[]interface{}{":tags", ":z"}, 1, ErrNoValue},
+			}
+
+			for _, failure := range failures {
+				value, err := GetIn(report, failure.path...)
+				Ω(value).Should(BeNil())
+				Ω(errors.Is(err, failure.err)).Should(BeTrue(), err.Error())
+
+				var pathErr *PathError
+				Ω(errors.As(err, &pathErr)).Should(BeTrue())
+				Ω(pathErr.Index).Should(BeEquivalentTo(failure.index))
+				Ω(pathErr.Segment()).Should(Equal(failure.path[failure.index]))
+			}
+
+			_, err := GetIn(report, ":tx-data", 3, ":missing")
+			Ω(err.Error()).Should(BeEquivalentTo(`path [":tx-data" 3 ":missing"] failed at segment 2 (":missing"): No value found`))
+		})
+	})
+
+	It("should take an empty string as a string key", func() {
+		value, err := GetIn(parse(`{"" 1 :a 2}`), "")
+		Ω(err).Should(BeNil())
+		Ω(value.Value()).Should(BeEquivalentTo(1))
+
+		_, err = GetIn(parse(`{:a 1}`), "")
+		Ω(errors.Is(err, ErrNoValue)).Should(BeTrue())
+
+		_, err = GetIn(parse(`[1]`), "")
+		Ω(err).ShouldNot(BeNil())
+
+		result, err := AssocIn(parse(`{}`), int64(1), "")
+		Ω(err).Should(BeNil())
+		Ω(serialize(result)).Should(BeEquivalentTo(`{"" 1}`))
+
+		elem, err := NewElement("")
+		Ω(err).Should(BeNil())
+		Ω(elem.ElementType()).Should(BeEquivalentTo(StringType))
+	})
+
+	Context("with AssocIn and UpdateIn", func() {
+		It("should set values without changing the original", func() {
+			result, err := AssocIn(report, int64(99), ":tx-data", 3, ":db/id")
+			Ω(err).Should(BeNil())
+
+			value, err := GetIn(result, ":tx-data", 3, ":db/id")
+			Ω(err).Should(BeNil())
+			Ω(value.Value()).Should(BeEquivalentTo(99))
+
+			value, err = GetIn(report, ":tx-data", 3, ":db/id")
+			Ω(err).Should(BeNil())
+			Ω(value.Value()).Should(BeEquivalentTo(17))
+
+			// the siblings are shared.
+			original, _ := GetIn(report, ":tempids")
+			copied, _ := GetIn(result, ":tempids")
+			Ω(copied).Should(BeIdenticalTo(original))
+		})
+
+		It("should create missing maps and append to sequences", func() {
+			result, err := AssocIn(parse(`{}`), "deep", ":a", ":b")
+			Ω(err).Should(BeNil())
+			Ω(serialize(result)).Should(BeEquivalentTo(`{:a {:b "deep"}}`))
+
+			result, err = AssocIn(parse(`[1 2]`), int64(3), 2)
+			Ω(err).Should(BeNil())
+			Ω(serialize(result)).Should(BeEquivalentTo(`[1 2 3]`))
+
+			result, err = AssocIn(parse(`#my/tag (1 2)`), int64(3), 0)
+			Ω(err).Should(BeNil())
+			Ω(serialize(result)).Should(BeEquivalentTo(`#my/tag (3 2)`))
+		})
+
+		It("should update the current value", func() {
+			result, err := UpdateIn(report, func(current Element) (Element, error) {
+				return NewIntegerElement(current.Value().(int64) + 1)
+			}, ":tempids", "tmp")
+			Ω(err).Should(BeNil())
+
+			value, err := GetIn(result, ":tempids", "tmp")
+			Ω(err).Should(BeNil())
+			Ω(value.Value()).Should(BeEquivalentTo(43))
+
+			result, err = UpdateIn(parse(`{}`), func(current Element) (Element, error) {
+				Ω(current).Should(BeNil())
+				return NewStringElement("new")
+			}, ":a")
+			Ω(err).Should(BeNil())
+			Ω(serialize(result)).Should(BeEquivalentTo(`{:a "new"}`))
+		})
+
+		It("should report the failing segment", func() {
+			_, err := AssocIn(report, int64(1), ":tempids", "tmp", 0)
+			Ω(errors.Is(err, ErrWrongCollection)).Should(BeTrue())
+
+			_, err = AssocIn(report, int64(1), ":tx-data", 9)
+			Ω(errors.Is(err, ErrIndexOutOfRange)).Should(BeTrue())
+
+			_, err = AssocIn(report, int64(1), ":tags", ":z")
+			Ω(errors.Is(err, ErrWrongCollection)).Should(BeTrue())
+
+			var pathErr *PathError
+			Ω(errors.As(err, &pathErr)).Should(BeTrue())
+			Ω(pathErr.Index).Should(BeEquivalentTo(1))
+
+			failure := Error("failure")
+			_, err = UpdateIn(report, func(Element) (Element, error) {
+				return nil, failure
+			}, ":log", 0)
+			Ω(errors.Is(err, failure)).Should(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,145 @@
+package elements
+
+import (
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// Position defines a location within EDN source.
+type Position struct {
+
+	// Offset is the 0 based byte offset.
+	Offset int
+
+	// Line is the 1 based line number.
+	Line int
+
+	// Column is the 1 based column, counted in runes.
+	Column int
+}
+
+// IsValid is true if the position came from parsed source. The zero position is not valid.
+func (pos Position) IsValid() bool {
+	return pos.Line > 0
+}
+
+// String returns the position as line:column.
+func (pos Position) String() string {
+	return fmt.Sprintf("%d:%d", pos.Line, pos.Column)
+}
+
+// Span defines the region of source an element was read from. End is exclusive.
+type Span struct {
+
+	// Start of the element, including any tag.
+	Start Position
+
+	// End is the position just after the element.
+	End Position
+}
+
+// IsValid is true if the span came from parsed source. Elements that were constructed rather than parsed have the zero
+// span.
+func (span Span) IsValid() bool {
+	return span.Start.IsValid()
+}
+
+// String returns the span as line:column-line:column.
+func (span Span) String() string {
+	return span.Start.String() + "-" + span.End.String()
+}
+
+const (
+
+	// checkpointSpacing is the number of bytes between the rune counts a lineIndex keeps, so that columns are counted
+	// from the nearest checkpoint instead of from the start of a possibly very long line.
+	checkpointSpacing = 256
+
+	// maxSnippetLength is the longest line kept as the snippet of a parse error.
+	maxSnippetLength = 1024
+)
+
+// lineIndex maps byte offsets into line and column positions.
+type lineIndex struct {
+	src    []byte
+	starts []int
+
+	// checkpoints hold rune starts about checkpointSpacing bytes apart, and runes the number of runes before each.
+	checkpoints []int
+	runes       []int
+}
+
+// newLineIndex creates the index for the source.
+func newLineIndex(src []byte) *lineIndex {
+	index := &lineIndex{
+		src:         src,
+		starts:      []int{0},
+		checkpoints: []int{0},
+		runes:       []int{0},
+	}
+
+	count := 0
+	for i := 0; i < len(src); count++ {
+		if i >= len(index.checkpoints)*checkpointSpacing {
+			index.checkpoints = append(index.checkpoints, i)
+			index.runes = append(index.runes, count)
+		}
+
+		size := 1
+		if c := src[i]; c >= utf8.RuneSelf {
+			_, size = utf8.DecodeRune(src[i:])
+		} else if c == '\n' {
+			index.starts = append(index.starts, i+1)
+		}
+		i += size
+	}
+
+	return index
+}
+
+// position returns the position of the offset.
+func (index *lineIndex) position(offset int) Position {
+	line := sort.Search(len(index.starts), func(i int) bool {
+		return index.starts[i] > offset
+	}) - 1
+
+	return Position{
+		Offset: offset,
+		Line:   line + 1,
+		Column: index.runeCount(offset) - index.runeCount(index.starts[line]) + 1,
+	}
+}
+
+// runeCount returns the number of runes before the offset, counted from the nearest checkpoint.
+func (index *lineIndex) runeCount(offset int) int {
+	i := offset / checkpointSpacing
+	if i >= len(index.checkpoints) {
+		i = len(index.checkpoints) - 1
+	}
+	if index.checkpoints[i] > offset {
+		i--
+	}
+
+	return index.runes[i] + utf8.RuneCount(index.src[index.checkpoints[i]:offset])
+}
+
+// line returns the text of the line that holds the offset, without the line end. Lines longer than maxSnippetLength
+// are left out.
+func (index *lineIndex) line(offset int) (text string) {
+	pos := index.position(offset)
+	start := index.starts[pos.Line-1]
+	end := len(index.src)
+	if pos.Line < len(index.starts) {
+		end = index.starts[pos.Line] - 1
+	}
+	if end > start && index.src[end-1] == '\r' {
+		end--
+	}
+
+	if end-start <= maxSnippetLength {
+		text = string(index.src[start:end])
+	}
+
+	return text
+}
@@ -0,0 +1,775 @@
+package elements
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mattrobenolt/gocql/uuid"
+)
+
+const (
+
+	// ErrUnexpectedEnd defines the error for input that stops in the middle of an element.
+	ErrUnexpectedEnd = Error("Unexpected end of input")
+
+	// ErrUnexpectedDelimiter defines the error for a closing delimiter that does not close anything.
+	ErrUnexpectedDelimiter = Error("Unexpected delimiter")
+
+	// ErrInvalidNumber defines the error for a malformed number.
+	ErrInvalidNumber = Error("Invalid number")
+
+	// ErrInvalidString defines the error for a malformed string.
+	ErrInvalidString = Error("Invalid string")
+
+	// ErrInvalidCharacter defines the error for a malformed character.
+	ErrInvalidCharacter = Error("Invalid character")
+
+	// ErrInvalidTag defines the error for a malformed tag or a tagged value that the tag does not accept.
+	ErrInvalidTag = Error("Invalid tag")
+
+	// ErrTrailingInput defines the error for input left over after the element.
+	ErrTrailingInput = Error("Unexpected input after the element")
+
+	// endOfInput describes the end of the source in errors.
+	endOfInput = "end of input"
+)
+
+// namedCharacters map the character names to their runes.
+var namedCharacters = map[string]rune{
+	"newline": '\n',
+	"return":  '\r',
+	"space":   ' ',
+	"tab":     '\t',
+}
+
+// Parse reads exactly one element from the source. Every element produced knows the span of source it came from. The
+// tag handlers of the default registry are used, and the DefaultLimits bound what is read. Use a Decoder with its own
+// Limits to read larger input.
+func Parse(src []byte) (elem Element, err error) {
+	return defaultRegistry.Parse(src)
+}
+
+// Decoder reads a stream of elements.
+type Decoder struct {
+
+	// in is the source of the EDN.
+	in io.Reader
+
+	// rd is the reader over the source, created on first use.
+	rd *reader
+
+	// registry holds the tag handlers.
+	registry *Registry
+
+	// namespace is the current namespace, used to read #::{} maps.
+	namespace string
+
+	// limits bound what is read.
+	limits Limits
+}
+
+// NewDecoder creates a decoder that reads from the input, using the tag handlers of the default registry.
+func NewDecoder(in io.Reader) *Decoder {
+	return defaultRegistry.NewDecoder(in)
+}
+
+// Decode the next element from the input. When there are no more elements io.EOF is returned. The input is read in
+// full on the first call, up to the MaxInputSize of the limits.
+func (dec *Decoder) Decode() (elem Element, err error) {
+	if err = dec.open(); err == nil {
+		elem, err = dec.rd.next()
+	}
+
+	return elem, err
+}
+
+// open reads the input and creates the reader over it, on first use.
+func (dec *Decoder) open() (err error) {
+	if dec.rd == nil {
+		limits := dec.limits.withDefaults()
+		in := dec.in
+		if limits.MaxInputSize >= 0 {
+			in = io.LimitReader(in, int64(limits.MaxInputSize)+1)
+		}
+
+		var src []byte
+		if src, err = io.ReadAll(in); err == nil {
+			dec.rd = newReader(src, limits)
+			dec.rd.registry = dec.registry
+			dec.rd.namespace = dec.namespace
+		}
+	}
+
+	return err
+}
+
+// SetNamespace sets the current namespace. The keys of #::{} maps are read in this namespace, without one those maps
+// are an error.
+func (dec *Decoder) SetNamespace(namespace string) {
+	dec.namespace = namespace
+	if dec.rd != nil {
+		dec.rd.namespace = namespace
+	}
+}
+
+// SetLimits sets the limits on what is read, in place of DefaultLimits. The input size is only checked when the input
+// is read, on the first call to Decode.
+func (dec *Decoder) SetLimits(limits Limits) {
+	dec.limits = limits
+	if dec.rd != nil {
+		input := dec.rd.limits.MaxInputSize
+		dec.rd.limits = limits.withDefaults()
+		dec.rd.limits.MaxInputSize = input
+	}
+}
+
+// reader holds the state while reading elements.
+type reader struct {
+	src       []byte
+	offset    int
+	lines     *lineIndex
+	namespace string
+	registry  *Registry
+	limits    Limits
+	depth     int
+}
+
+// newReader creates a reader over the source. Source past the input size limit is never looked at, the reader only
+// keeps the first byte beyond it to fail on.
+func newReader(src []byte, limits Limits) *reader {
+	if exceeds(len(src), limits.MaxInputSize) {
+		src = src[:limits.MaxInputSize+1]
+	}
+
+	return &reader{
+		src:      src,
+		lines:    newLineIndex(src),
+		registry: defaultRegistry,
+		limits:   limits,
+	}
+}
+
+// atEnd is true when all of the source has been read.
+func (rd *reader) atEnd() bool {
+	return rd.offset >= len(rd.src)
+}
+
+// fail builds the parse error at the offset.
+func (rd *reader) fail(offset int, expected string, found string, reason error) error {
+	return &ParseError{
+		Position: rd.lines.position(offset),
+		Expected: expected,
+		Found:    found,
+		Snippet:  rd.lines.line(offset),
+		Err:      reason,
+	}
+}
+
+// describe the source at the offset for an error.
+func (rd *reader) describe(offset int) (found string) {
+	if offset >= len(rd.src) {
+		found = endOfInput
+	} else {
+		end := offset + 1
+		for end < len(rd.src) && !isTerminator(rd.src[end]) {
+			end++
+		}
+		found = strconv.Quote(string(rd.src[offset:end]))
+	}
+	return found
+}
+
+// span builds the span from the offset to the current offset.
+func (rd *reader) span(start int) Span {
+	return Span{
+		Start: rd.lines.position(start),
+		End:   rd.lines.position(rd.offset),
+	}
+}
+
+// isWhitespace is true for bytes that separate elements. Commas are whitespace in EDN.
+func isWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == ','
+}
+
+// isTerminator is true for bytes that end a token.
+func isTerminator(c byte) bool {
+	return isWhitespace(c) || strings.IndexByte(`()[]{}";`, c) >= 0
+}
+
+// isClose is true for the closing delimiters.
+func isClose(c byte) bool {
+	return c == ')' || c == ']' || c == '}'
+}
+
+// skipTrivia moves past whitespace, comments and discarded elements.
+func (rd *reader) skipTrivia() (err error) {
+	for done := false; err == nil && !done && !rd.atEnd(); {
+		switch c := rd.src[rd.offset]; {
+		case isWhitespace(c):
+			rd.offset++
+		case c == ';':
+			for !rd.atEnd() && rd.src[rd.offset] != '\n' {
+				rd.offset++
+			}
+		case c == '#' && rd.offset+1 < len(rd.src) && rd.src[rd.offset+1] == '_':
+			start := rd.offset
+			rd.offset += 2
+			if _, err = rd.descend(); err == io.EOF {
+				err = rd.fail(start, "element to discard", endOfInput, ErrUnexpectedEnd)
+			}
+		default:
+			done = true
+		}
+	}
+
+	return err
+}
+
+// next skips the trivia and reads the next element, io.EOF is returned if there is none.
+func (rd *reader) next() (elem Element, err error) {
+	if err = rd.checkInput(); err == nil {
+		err = rd.skipTrivia()
+	}
+
+	if err == nil {
+		if rd.atEnd() {
+			err = io.EOF
+		} else {
+			start := rd.offset
+			if elem, err = rd.element(); err == nil {
+				if spanned, is := elem.(spanner); is {
+					spanned.setSpan(rd.span(start))
+				}
+			}
+		}
+	}
+
+	return elem, err
+}
+
+// checkInput fails if the source is larger than the input size limit.
+func (rd *reader) checkInput() (err error) {
+	if exceeds(len(rd.src), rd.limits.MaxInputSize) {
+		err = rd.failLimit(rd.limits.MaxInputSize, "input size", rd.limits.MaxInputSize, ErrInputLimit)
+	}
+
+	return err
+}
+
+// descend reads the next element one level deeper than the current one.
+func (rd *reader) descend() (elem Element, err error) {
+	if exceeds(rd.depth+1, rd.limits.MaxDepth) {
+		err = rd.failLimit(rd.offset, "nesting depth", rd.limits.MaxDepth, ErrDepthLimit)
+	} else {
+		rd.depth++
+		elem, err = rd.next()
+		rd.depth--
+	}
+
+	return elem, err
+}
+
+// element reads the element that starts at the current offset.
+func (rd *reader) element() (elem Element, err error) {
+	switch c := rd.src[rd.offset]; {
+	case c == '(':
+		elem, err = rd.collection(GroupingType, ')')
+	case c == '[':
+		elem, err = rd.collection(VectorType, ']')
+	case c == '{':
+		elem, err = rd.collection(MapType, '}')
+	case c == '"':
+		elem, err = rd.str()
+	case c == '\\':
+		elem, err = rd.character()
+	case c == '#':
+		elem, err = rd.dispatch()
+	case isClose(c):
+		err = rd.fail(rd.offset, "element", strconv.Quote(string(c)), ErrUnexpectedDelimiter)
+	default:
+		elem, err = rd.token()
+	}
+
+	return elem, err
+}
+
+// collection reads the children up to the closing delimiter and builds the collection.
+func (rd *reader) collection(elemType ElementType, closer byte) (elem Element, err error) {
+	start := rd.offset
+	if elemType == SetType {
+		rd.offset += len(SetStartLiteral)
+	} else {
+		rd.offset++
+	}
+
+	var children []Element
+	if children, err = rd.items(start, closer); err == nil {
+		switch elemType {
+		case GroupingType:
+			elem, err = NewGroup(children...)
+		case VectorType:
+			elem, err = NewVector(children...)
+		case SetType:
+			elem, err = rd.set(children)
+		case MapType:
+			elem, err = rd.mapping(children)
+		}
+	}
+
+	return elem, err
+}
+
+// items reads the children up to the closing delimiter. The opening delimiter at start has already been read.
+func (rd *reader) items(start int, closer byte) (children []Element, err error) {
+	children = []Element{}
+	for closed := false; err == nil && !closed; {
+		if err = rd.skipTrivia(); err == nil {
+			switch {
+			case rd.atEnd():
+				err = rd.fail(start, fmt.Sprintf("%q to close", closer), endOfInput, ErrUnexpectedEnd)
+			case rd.src[rd.offset] == closer:
+				rd.offset++
+				closed = true
+			case isClose(rd.src[rd.offset]):
+				err = rd.fail(rd.offset, strconv.Quote(string(closer)), strconv.Quote(string(rd.src[rd.offset])), ErrUnexpectedDelimiter)
+			case exceeds(len(children)+1, rd.limits.MaxCollectionSize):
+				err = rd.failLimit(rd.offset, "collection size", rd.limits.MaxCollectionSize, ErrCollectionLimit)
+			default:
+				var child Element
+				if child, err = rd.descend(); err == nil {
+					children = append(children, child)
+				}
+			}
+		}
+	}
+
+	return children, err
+}
+
+// namespacedMap reads a namespace qualified map, #:ns{} or #::{} for the current namespace, and applies the namespace
+// to its keys.
+func (rd *reader) namespacedMap() (elem Element, err error) {
+	start := rd.offset
+	rd.offset += len(NamespacedMapPrefix)
+
+	auto := !rd.atEnd() && rd.src[rd.offset] == KeywordPrefix[0]
+	if auto {
+		rd.offset++
+	}
+
+	nsStart := rd.offset
+	var ns string
+	if err = rd.scanToken(start); err == nil {
+		ns = string(rd.src[nsStart:rd.offset])
+
+		switch {
+		case auto && len(ns) == 0 && len(rd.namespace) != 0:
+			ns = rd.namespace
+		case auto, !validSymbol(ns):
+			err = rd.fail(start, "namespace", rd.describe(start), ErrInvalidTag)
+		}
+	}
+
+	if err == nil {
+		if err = rd.skipTrivia(); err == nil && (rd.atEnd() || rd.src[rd.offset] != '{') {
+			err = rd.fail(rd.offset, strconv.Quote(MapStartLiteral), rd.describe(rd.offset), ErrInvalidTag)
+		}
+	}
+
+	if err == nil {
+		rd.offset++
+
+		var children []Element
+		if children, err = rd.items(start, '}'); err == nil {
+			for i := 0; err == nil && i < len(children); i += 2 {
+				if children[i], err = qualifyKey(children[i], ns); err != nil {
+					err = rd.fail(children[i].Span().Start.Offset, "key", rd.describe(children[i].Span().Start.Offset), err)
+				}
+			}
+		}
+
+		if err == nil {
+			elem, err = rd.mapping(children)
+		}
+	}
+
+	return elem, err
+}
+
+// set builds a set from the members, failing at the first member equal to an earlier one.
+func (rd *reader) set(children []Element) (elem Element, err error) {
+	seen := make(map[string]bool, len(children))
+	for i := 0; err == nil && i < len(children); i++ {
+		if group := equalityGroup(children[i]); seen[group] {
+			member, _ := children[i].Serialize()
+			err = rd.fail(children[i].Span().Start.Offset, "unique member", strconv.Quote(member), ErrDuplicateMember)
+		} else {
+			seen[group] = true
+		}
+	}
+
+	if err == nil {
+		elem, err = NewSet(children...)
+	}
+
+	return elem, err
+}
+
+// mapping builds a map from the alternating keys and values.
+func (rd *reader) mapping(children []Element) (elem Element, err error) {
+	if len(children)%2 != 0 {
+		err = rd.fail(rd.offset-1, "value for the key", strconv.Quote(MapEndLiteral), ErrInvalidPair)
+	} else {
+		var coll *collectionElemImpl
+		if coll, err = emptyMap(); err == nil {
+			for i := 0; err == nil && i < len(children); i += 2 {
+				if coll.hasKey(children[i]) {
+					key, _ := children[i].Serialize()
+					err = rd.fail(children[i].Span().Start.Offset, "unique key", strconv.Quote(key), ErrDuplicateKey)
+				} else {
+					err = coll.Append(children[i], children[i+1])
+				}
+			}
+		}
+
+		if err == nil {
+			elem = coll
+		}
+	}
+
+	return elem, err
+}
+
+// str reads a string literal.
+func (rd *reader) str() (elem Element, err error) {
+	var value string
+	if value, err = rd.unquote(); err == nil {
+		elem, err = NewStringElement(value)
+	}
+
+	return elem, err
+}
+
+// unquote reads a string literal and returns the value with the escapes applied.
+func (rd *reader) unquote() (value string, err error) {
+	start := rd.offset
+	rd.offset++
+
+	var builder strings.Builder
+	for closed := false; err == nil && !closed; {
+		if exceeds(builder.Len(), rd.limits.MaxStringLength) {
+			err = rd.failLimit(start, "string length", rd.limits.MaxStringLength, ErrStringLimit)
+		} else if rd.atEnd() {
+			err = rd.fail(start, "closing quote", endOfInput, ErrUnexpectedEnd)
+		} else {
+			switch c := rd.src[rd.offset]; c {
+			case '"':
+				rd.offset++
+				closed = true
+			case '\\':
+				if rd.offset+1 >= len(rd.src) {
+					err = rd.fail(start, "closing quote", endOfInput, ErrUnexpectedEnd)
+				} else {
+					escape := rd.src[rd.offset+1]
+					rd.offset += 2
+					switch escape {
+					case 't':
+						builder.WriteByte('\t')
+					case 'r':
+						builder.WriteByte('\r')
+					case 'n':
+						builder.WriteByte('\n')
+					case 'b':
+						builder.WriteByte('\b')
+					case 'f':
+						builder.WriteByte('\f')
+					case '\\', '"':
+						builder.WriteByte(escape)
+					case 'u':
+						var r rune
+						if r, err = rd.unicode(rd.offset - 2); err == nil {
+							builder.WriteRune(r)
+						}
+					default:
+						err = rd.fail(rd.offset-2, "escape sequence", strconv.Quote(`\`+string(escape)), ErrInvalidString)
+					}
+				}
+			default:
+				builder.WriteByte(c)
+				rd.offset++
+			}
+		}
+	}
+
+	return builder.String(), err
+}
+
+// unicode reads the 4 hex digits of a \u escape that started at the offset.
+func (rd *reader) unicode(start int) (r rune, err error) {
+	if rd.offset+4 <= len(rd.src) {
+		var value uint64
+		if value, err = strconv.ParseUint(string(rd.src[rd.offset:rd.offset+4]), 16, 32); err == nil {
+			r = rune(value)
+			rd.offset += 4
+		}
+	} else {
+		err = ErrUnexpectedEnd
+	}
+
+	if err != nil {
+		err = rd.fail(start, "4 hex digits", rd.describe(start), ErrInvalidCharacter)
+	}
+
+	return r, err
+}
+
+// character reads a character literal.
+func (rd *reader) character() (elem Element, err error) {
+	start := rd.offset
+	rd.offset++
+
+	if rd.atEnd() {
+		err = rd.fail(start, "character", endOfInput, ErrUnexpectedEnd)
+	} else {
+		_, size := utf8.DecodeRune(rd.src[rd.offset:])
+		rd.offset += size
+		err = rd.scanToken(start)
+	}
+
+	if err == nil {
+		var r rune
+		name := string(rd.src[start+1 : rd.offset])
+		if named, has := namedCharacters[name]; has {
+			r = named
+		} else if utf8.RuneCountInString(name) == 1 {
+			r, _ = utf8.DecodeRuneInString(name)
+		} else if len(name) == 5 && name[0] == 'u' {
+			var value uint64
+			if value, err = strconv.ParseUint(name[1:], 16, 32); err == nil {
+				r = rune(value)
+			}
+		} else {
+			err = ErrInvalidCharacter
+		}
+
+		if err == nil {
+			elem, err = NewCharacterElement(r)
+		} else {
+			err = rd.fail(start, "character", strconv.Quote(CharacterPrefix+name), ErrInvalidCharacter)
+		}
+	}
+
+	return elem, err
+}
+
+// dispatch reads the elements that start with #: sets, namespace qualified maps and tagged elements. Discards are handled as trivia.
+func (rd *reader) dispatch() (elem Element, err error) {
+	if rd.offset+1 < len(rd.src) && rd.src[rd.offset+1] == '{' {
+		elem, err = rd.collection(SetType, '}')
+	} else if rd.offset+1 < len(rd.src) && rd.src[rd.offset+1] == KeywordPrefix[0] {
+		elem, err = rd.namespacedMap()
+	} else {
+		var tag string
+		if tag, err = rd.tag(); err == nil {
+			elem, err = rd.tagged(tag)
+		}
+	}
+
+	return elem, err
+}
+
+// tag reads the tag that starts at the offset and moves to the element that follows it.
+func (rd *reader) tag() (tag string, err error) {
+	start := rd.offset
+	rd.offset++
+
+	if err = rd.scanToken(start); err == nil {
+		tag = string(rd.src[start+1 : rd.offset])
+		if _, e := NewSymbolElement(tag); e != nil || tag[0] < 'A' || (tag[0] > 'Z' && tag[0] < 'a') || tag[0] > 'z' {
+			err = rd.fail(start, "tag", rd.describe(start), ErrInvalidTag)
+		} else if !rd.limits.allowsTag(tag) {
+			err = rd.fail(start, "allowed tag", strconv.Quote(tag), ErrTagNotAllowed)
+		} else if err = rd.skipTrivia(); err == nil {
+			if rd.atEnd() || isClose(rd.src[rd.offset]) {
+				err = rd.fail(start, "element for the tag", rd.describe(rd.offset), ErrUnexpectedEnd)
+			}
+		}
+	}
+
+	return tag, err
+}
+
+// tagged reads the element that follows a tag. The built in tags are converted into their elements. Their values are
+// usually strings, but the bare form written by this package is accepted too. Tags with a handler in the registry are
+// converted by the handler, any other tag is kept as a tagged element.
+func (rd *reader) tagged(tag string) (elem Element, err error) {
+	start := rd.offset
+
+	switch tag {
+	case InstantElementTag, UUIDElementTag:
+		var text string
+		if rd.src[rd.offset] == '"' {
+			text, err = rd.unquote()
+		} else if err = rd.scanToken(start); err == nil {
+			text = string(rd.src[start:rd.offset])
+		}
+
+		if err == nil {
+			if tag == InstantElementTag {
+				var t time.Time
+				if t, err = time.Parse(time.RFC3339Nano, text); err == nil {
+					elem, err = NewInstantElement(t)
+				}
+			} else {
+				var u uuid.UUID
+				if u, err = uuid.ParseUUID(text); err == nil {
+					elem, err = NewUUIDElement(u)
+				}
+			}
+
+			if err != nil {
+				err = rd.fail(start, tag+" value", strconv.Quote(text), ErrInvalidTag)
+			}
+		}
+
+	default:
+		var symbol SymbolElement
+		var value Element
+		if symbol, err = NewSymbolElement(tag); err == nil {
+			if value, err = rd.descend(); err == nil {
+				if handler, has := rd.registry.TagHandler(tag); has {
+					if elem, err = handler(value); err == nil && elem == nil {
+						err = ErrInvalidElement
+					}
+					if err != nil {
+						err = rd.fail(start, tag+" value", rd.describe(start), err)
+					}
+				} else {
+					elem, err = NewTaggedElement(symbol, value)
+				}
+			}
+		}
+	}
+
+	return elem, err
+}
+
+// token reads nil, booleans, numbers, keywords and symbols.
+func (rd *reader) token() (elem Element, err error) {
+	start := rd.offset
+	if err = rd.scanToken(start); err == nil {
+		text := string(rd.src[start:rd.offset])
+
+		switch {
+		case text == NilLiteral:
+			elem, err = NewNilElement()
+
+		case text == "true" || text == "false":
+			elem, err = NewBooleanElement(text == "true")
+
+		case isNumeric(text):
+			if elem, err = parseNumber(text); err != nil {
+				err = rd.fail(start, "number", strconv.Quote(text), err)
+			}
+
+		case strings.HasPrefix(text, KeywordPrefix):
+			if elem, err = NewKeywordElement(text); err != nil {
+				err = rd.fail(start, "keyword", strconv.Quote(text), err)
+			}
+
+		default:
+			if elem, err = NewSymbolElement(text); err != nil {
+				err = rd.fail(start, "symbol", strconv.Quote(text), err)
+			}
+		}
+	}
+
+	return elem, err
+}
+
+// scanToken moves past the rest of the token that started at the offset.
+func (rd *reader) scanToken(start int) (err error) {
+	for !rd.atEnd() && !isTerminator(rd.src[rd.offset]) {
+		rd.offset++
+	}
+
+	if exceeds(rd.offset-start, rd.limits.MaxTokenLength) {
+		err = rd.failLimit(start, "token length", rd.limits.MaxTokenLength, ErrTokenLimit)
+	}
+
+	return err
+}
+
+// isNumeric is true if the token must be a number: it starts with a digit, or a sign followed by a digit.
+func isNumeric(text string) bool {
+	if len(text) > 1 && (text[0] == '+' || text[0] == '-') {
+		text = text[1:]
+	}
+	return len(text) > 0 && text[0] >= '0' && text[0] <= '9'
+}
+
+// parseNumber builds the integer, float, big integer or big decimal element for the token.
+func parseNumber(text string) (elem Element, err error) {
+	suffix := ""
+	if strings.HasSuffix(text, BigIntSuffix) || strings.HasSuffix(text, BigDecSuffix) {
+		suffix = text[len(text)-1:]
+		text = text[:len(text)-1]
+	}
+
+	switch {
+	case strings.Trim(text, "0123456789+-.eE") != "":
+		err = ErrInvalidNumber
+
+	case suffix == BigDecSuffix:
+		var f *big.Float
+		if f, _, err = big.ParseFloat(text, 10, bigDecPrecision(text), big.ToNearestEven); err == nil {
+			elem, err = NewBigDecElement(f)
+		} else {
+			err = ErrInvalidNumber
+		}
+
+	case strings.ContainsAny(text, ".eE"):
+		var f float64
+		if suffix != "" {
+			err = ErrInvalidNumber
+		} else if f, err = strconv.ParseFloat(text, 64); err == nil {
+			elem, err = NewFloatElement(f)
+		} else {
+			err = ErrInvalidNumber
+		}
+
+	case suffix == BigIntSuffix:
+		if i, ok := new(big.Int).SetString(strings.TrimPrefix(text, "+"), 10); ok {
+			elem, err = NewBigIntElement(i)
+		} else {
+			err = ErrInvalidNumber
+		}
+
+	default:
+		var i int64
+		if i, err = strconv.ParseInt(text, 10, 64); err == nil {
+			elem, err = NewIntegerElement(i)
+		} else {
+			err = ErrInvalidNumber
+		}
+	}
+
+	return elem, err
+}
+
+// bigDecPrecision returns enough bits of mantissa to hold every digit of the decimal text.
+func bigDecPrecision(text string) uint {
+	digits := uint(len(strings.Trim(strings.SplitN(strings.ToLower(text), "e", 2)[0], "+-.")))
+	if digits < 16 {
+		digits = 16
+	}
+
+	// log2(10) bits per decimal digit, rounded up.
+	return digits*3322/1000 + 1
+}
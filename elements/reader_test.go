@@ -0,0 +1,262 @@
+package elements
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/big"
+	"time"
+
+	"github.com/mattrobenolt/gocql/uuid"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reading EDN", func() {
+	Context("with well formed input", func() {
+
+		It("should read the scalar elements", func() {
+			inst, err := time.Parse(time.RFC3339Nano, "1985-04-12T23:20:50.52Z")
+			Ω(err).Should(BeNil())
+
+			u, err := uuid.ParseUUID("f81d4fae-7dec-11d0-a765-00a0c91e6bf6")
+			Ω(err).Should(BeNil())
+
+			scalars := map[string]struct {
+				elemType ElementType
+				value    interface{}
+			}{
+				"nil":                             {NilType, nil},
+				"true":                            {BooleanType, true},
+				"false":                           {BooleanType, false},
+				"42":                              {IntegerType, int64(42)},
+				"-42":                             {IntegerType, int64(-42)},
+				"+7":                              {IntegerType, int64(7)},
+				"3.25":                            {FloatType, 3.25},
+				"-1.5e3":                          {FloatType, -1500.0},
+				`"a \"quoted\"\n\tstring é"`:      {StringType, "a \"quoted\"\n\tstring é"},
+				`\c`:                              {CharacterType, 'c'},
+				`\newline`:                        {CharacterType, '\n'},
+				`\é`:                              {CharacterType, 'é'},
+				`\(`:                              {CharacterType, '('},
+				`#inst "1985-04-12T23:20:50.52Z"`: {InstantType, inst},
+				`#uuid "f81d4fae-7dec-11d0-a765-00a0c91e6bf6"`: {UUIDType, u},
+				`#uuid f81d4fae-7dec-11d0-a765-00a0c91e6bf6`:   {UUIDType, u},
+			}
+
+			for src, expected := range scalars {
+				elem, err := Parse([]byte(src))
+				Ω(err).Should(BeNil(), src)
+				Ω(elem.ElementType()).Should(BeEquivalentTo(expected.elemType), src)
+				if expected.value == nil {
+					Ω(elem.Value()).Should(BeNil(), src)
+				} else {
+					Ω(elem.Value()).Should(BeEquivalentTo(expected.value), src)
+				}
+			}
+		})
+
+		It("should read big integers and big decimals", func() {
+			elem, err := Parse([]byte("-123456789012345678901234567890N"))
+			Ω(err).Should(BeNil())
+			Ω(elem.ElementType()).Should(BeEquivalentTo(BigIntType))
+			Ω(elem.Value().(*big.Int).String()).Should(BeEquivalentTo("-123456789012345678901234567890"))
+
+			elem, err = Parse([]byte("1.10M"))
+			Ω(err).Should(BeNil())
+			Ω(elem.ElementType()).Should(BeEquivalentTo(BigDecType))
+			Ω(elem.Value().(*big.Float).Text('g', -1)).Should(BeEquivalentTo("1.1"))
+
+			elem, err = Parse([]byte("7M"))
+			Ω(err).Should(BeNil())
+			Ω(elem.ElementType()).Should(BeEquivalentTo(BigDecType))
+
+			for _, src := range []string{"1.5N", "1e3N", "12NM", "1-2M"} {
+				_, err = Parse([]byte(src))
+				Ω(errors.Is(err, ErrInvalidNumber)).Should(BeTrue(), src)
+			}
+		})
+
+		It("should read symbols and keywords", func() {
+			elem, err := Parse([]byte(":db.install/_attribute"))
+			Ω(err).Should(BeNil())
+			Ω(elem.ElementType()).Should(BeEquivalentTo(KeywordType))
+			Ω(elem.(SymbolElement).Prefix()).Should(BeEquivalentTo("db.install"))
+			Ω(elem.(SymbolElement).Name()).Should(BeEquivalentTo("_attribute"))
+
+			elem, err = Parse([]byte("my/foo"))
+			Ω(err).Should(BeNil())
+			Ω(elem.ElementType()).Should(BeEquivalentTo(SymbolType))
+			Ω(elem.(SymbolElement).Prefix()).Should(BeEquivalentTo("my"))
+		})
+
+		It("should read collections and keep map keys intact", func() {
+			elem, err := Parse([]byte(`{1 "one", :two [2 2.0] "three" #{3}, nil (4)}`))
+			Ω(err).Should(BeNil())
+			Ω(elem.ElementType()).Should(BeEquivalentTo(MapType))
+
+			coll := elem.(CollectionElement)
+			Ω(coll.Len()).Should(BeEquivalentTo(4))
+
+			keyTypes := []ElementType{}
+			err = coll.IterateChildren(func(key Element, value Element) error {
+				keyTypes = append(keyTypes, key.ElementType())
+				return nil
+			})
+			Ω(err).Should(BeNil())
+			Ω(keyTypes).Should(ConsistOf(IntegerType, KeywordType, StringType, NilType))
+
+			var value Element
+			value, err = coll.Get(":two")
+			Ω(err).Should(BeNil())
+			Ω(value.ElementType()).Should(BeEquivalentTo(VectorType))
+			Ω(value.(CollectionElement).Len()).Should(BeEquivalentTo(2))
+		})
+
+		It("should keep map keys of different types apart", func() {
+			for src, values := range map[string][]string{
+				`{"a" 1 a 2}`:             {`"a"`, `1`, `a`, `2`},
+				`{1 :a "1" :b}`:           {`1`, `:a`, `"1"`, `:b`},
+				`{:a/b 1 :a/_b 2}`:        {`:a/b`, `1`, `:a/_b`, `2`},
+				`{1 :int 1N :big 1.0 :f}`: {`1`, `:int`, `1N`, `:big`, `1.0`, `:f`},
+			} {
+				elem, err := Parse([]byte(src))
+				Ω(err).Should(BeNil(), src)
+
+				coll := elem.(CollectionElement)
+				Ω(coll.Len()).Should(BeEquivalentTo(len(values)/2), src)
+				for i := 0; i < len(values); i += 2 {
+					key, err := Parse([]byte(values[i]))
+					Ω(err).Should(BeNil())
+
+					value, err := coll.Get(key)
+					Ω(err).Should(BeNil(), src)
+					Ω(value.Serialize()).Should(Equal(values[i+1]), src)
+				}
+			}
+
+			elem, err := Parse([]byte(`{"a" 1 a 2}`))
+			Ω(err).Should(BeNil())
+			value, err := elem.(CollectionElement).Get("a")
+			Ω(err).Should(BeNil())
+			Ω(value.Serialize()).Should(Equal("1"))
+		})
+
+		It("should skip comments, commas and discarded elements", func() {
+			elem, err := Parse([]byte("; leading\n[1, #_ 2 #_ #_ 3 4 5 ; trailing\n]"))
+			Ω(err).Should(BeNil())
+
+			var str string
+			str, err = elem.Serialize()
+			Ω(err).Should(BeNil())
+			Ω(str).Should(BeEquivalentTo("[1 5]"))
+		})
+
+		It("should keep custom tags", func() {
+			elem, err := Parse([]byte("#db/id [:db.part/db]"))
+			Ω(err).Should(BeNil())
+			Ω(elem.Tag()).Should(BeEquivalentTo("db/id"))
+
+			var str string
+			str, err = elem.Serialize()
+			Ω(err).Should(BeNil())
+			Ω(str).Should(BeEquivalentTo("#db/id [:db.part/db]"))
+		})
+
+		It("should report the span of every element", func() {
+			elem, err := Parse([]byte("[:a\n  #db/id [:b]\n  \"é\" x]"))
+			Ω(err).Should(BeNil())
+			Ω(elem.Span()).Should(Equal(Span{
+				Start: Position{Offset: 0, Line: 1, Column: 1},
+				End:   Position{Offset: 27, Line: 3, Column: 9},
+			}))
+
+			children := []Element{}
+			elem.(CollectionElement).IterateChildren(func(_ Element, child Element) error {
+				children = append(children, child)
+				return nil
+			})
+
+			Ω(children[0].Span().Start).Should(Equal(Position{Offset: 1, Line: 1, Column: 2}))
+			Ω(children[1].Span().Start).Should(Equal(Position{Offset: 6, Line: 2, Column: 3}))
+			Ω(children[1].Span().End).Should(Equal(Position{Offset: 17, Line: 2, Column: 14}))
+			Ω(children[2].Span().Start).Should(Equal(Position{Offset: 20, Line: 3, Column: 3}))
+			Ω(children[3].Span().Start).Should(Equal(Position{Offset: 25, Line: 3, Column: 7}))
+
+			constructed, err := NewIntegerElement(1)
+			Ω(err).Should(BeNil())
+			Ω(constructed.Span().IsValid()).Should(BeFalse())
+		})
+
+		It("should decode a stream of elements", func() {
+			dec := NewDecoder(bytes.NewBufferString("1 :two\n\"three\" ; done\n"))
+
+			types := []ElementType{}
+			for {
+				elem, err := dec.Decode()
+				if err == io.EOF {
+					break
+				}
+				Ω(err).Should(BeNil())
+				types = append(types, elem.ElementType())
+			}
+
+			Ω(types).Should(Equal([]ElementType{IntegerType, KeywordType, StringType}))
+		})
+	})
+
+	Context("with malformed input", func() {
+
+		It("should point at the culprit", func() {
+			src := "[{:db/ident :person/name}\n {:db/ident :/bad}]"
+			_, err := Parse([]byte(src))
+			Ω(err).Should(BeAssignableToTypeOf(&ParseError{}))
+
+			parseErr := err.(*ParseError)
+			Ω(parseErr.Position).Should(Equal(Position{Offset: 38, Line: 2, Column: 13}))
+			Ω(parseErr.Expected).Should(BeEquivalentTo("keyword"))
+			Ω(parseErr.Found).Should(BeEquivalentTo(`":/bad"`))
+			Ω(parseErr.Snippet).Should(BeEquivalentTo(" {:db/ident :/bad}]"))
+			Ω(parseErr.Err).Should(BeIdenticalTo(ErrInvalidKeyword))
+			Ω(parseErr.Error()).Should(BeEquivalentTo("2:13: expected keyword, found \":/bad\" (Invalid keyword)\n\t {:db/ident :/bad}]\n\t            ^"))
+		})
+
+		It("should report the reason for each failure", func() {
+			malformed := map[string]struct {
+				err    error
+				line   int
+				column int
+			}{
+				"":                     {ErrUnexpectedEnd, 1, 1},
+				"[1 2":                 {ErrUnexpectedEnd, 1, 1},
+				"{:a 1]":               {ErrUnexpectedDelimiter, 1, 6},
+				"\n  )":                {ErrUnexpectedDelimiter, 2, 3},
+				"\"open":               {ErrUnexpectedEnd, 1, 1},
+				`"bad \q escape"`:      {ErrInvalidString, 1, 6},
+				`\bogus`:               {ErrInvalidCharacter, 1, 1},
+				"12abc":                {ErrInvalidNumber, 1, 1},
+				"99999999999999999999": {ErrInvalidNumber, 1, 1},
+				"{:a 1 :b}":            {ErrInvalidPair, 1, 9},
+				"{:a 1 :a 2}":          {ErrDuplicateKey, 1, 7},
+				"#{1 \"1\" 1}":         {ErrDuplicateMember, 1, 9},
+				"#1tag x":              {ErrInvalidTag, 1, 1},
+				"#inst \"never\"":      {ErrInvalidTag, 1, 7},
+				"#tag":                 {ErrUnexpectedEnd, 1, 1},
+				"[#_]":                 {ErrUnexpectedDelimiter, 1, 4},
+				"1 2":                  {ErrTrailingInput, 1, 3},
+				"bad/worse/wrong":      {ErrInvalidSymbol, 1, 1},
+			}
+
+			for src, expected := range malformed {
+				elem, err := Parse([]byte(src))
+				Ω(elem).Should(BeNil(), src)
+				Ω(err).Should(BeAssignableToTypeOf(&ParseError{}), src)
+
+				parseErr := err.(*ParseError)
+				Ω(parseErr.Err).Should(BeIdenticalTo(expected.err), src)
+				Ω(parseErr.Position.Line).Should(BeEquivalentTo(expected.line), src)
+				Ω(parseErr.Position.Column).Should(BeEquivalentTo(expected.column), src)
+			}
+		})
+	})
+})
@@ -0,0 +1,198 @@
+package elements
+
+import (
+	"io"
+	"sync"
+)
+
+// TagHandler converts the element read after a tag into the element the tag stands for, e.g. #myapp/money "12.50 EUR"
+// into a map of amount and currency.
+type TagHandler func(value Element) (elem Element, err error)
+
+// Registry holds the element factories used by NewElement and the tag handlers used by the reader. A registry is safe
+// for concurrent use, and factories and handlers can be added, replaced and removed at any time. The package level
+// functions use the default registry.
+type Registry struct {
+
+	// lock guards the maps.
+	lock sync.RWMutex
+
+	// factories hold the element factories by type.
+	factories map[ElementType]ElementTypeFactory
+
+	// tags hold the tag handlers by tag.
+	tags map[string]TagHandler
+}
+
+// defaultRegistry is the registry used by the package level functions.
+var defaultRegistry = NewRegistry()
+
+// DefaultRegistry returns the registry used by the package level functions.
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
+// NewRegistry creates a registry with the built in factories and no tag handlers.
+func NewRegistry() (registry *Registry) {
+	registry = &Registry{
+		factories: map[ElementType]ElementTypeFactory{},
+		tags:      map[string]TagHandler{},
+	}
+
+	// the built in factories can not clash in a new registry.
+	for _, def := range typeDefinitions {
+		if def.initializer != nil {
+			_ = def.initializer(registry)
+		}
+	}
+
+	return registry
+}
+
+// AddFactory adds the factory for the type. ErrInvalidFactory is returned if the type already has one.
+func (registry *Registry) AddFactory(elemType ElementType, factory ElementTypeFactory) (err error) {
+	registry.lock.Lock()
+	if _, has := registry.factories[elemType]; has || factory == nil {
+		err = ErrInvalidFactory
+	} else {
+		registry.factories[elemType] = factory
+	}
+	registry.lock.Unlock()
+
+	return err
+}
+
+// SetFactory adds or replaces the factory for the type, and returns the factory it replaced, if any.
+func (registry *Registry) SetFactory(elemType ElementType, factory ElementTypeFactory) (previous ElementTypeFactory) {
+	registry.lock.Lock()
+	previous = registry.factories[elemType]
+	if factory == nil {
+		delete(registry.factories, elemType)
+	} else {
+		registry.factories[elemType] = factory
+	}
+	registry.lock.Unlock()
+
+	return previous
+}
+
+// RemoveFactory removes the factory for the type, and returns it if there was one.
+func (registry *Registry) RemoveFactory(elemType ElementType) (previous ElementTypeFactory) {
+	return registry.SetFactory(elemType, nil)
+}
+
+// Factory returns the factory for the type.
+func (registry *Registry) Factory(elemType ElementType) (factory ElementTypeFactory, has bool) {
+	registry.lock.RLock()
+	factory, has = registry.factories[elemType]
+	registry.lock.RUnlock()
+
+	return factory, has
+}
+
+// AddTagHandler adds the handler for the tag. ErrInvalidTag is returned if the tag is not a valid tag, is built in or
+// already has a handler.
+func (registry *Registry) AddTagHandler(tag string, handler TagHandler) (err error) {
+	if err = checkHandlerTag(tag); err == nil {
+		registry.lock.Lock()
+		if _, has := registry.tags[tag]; has || handler == nil {
+			err = ErrInvalidTag
+		} else {
+			registry.tags[tag] = handler
+		}
+		registry.lock.Unlock()
+	}
+
+	return err
+}
+
+// SetTagHandler adds or replaces the handler for the tag, and returns the handler it replaced, if any.
+func (registry *Registry) SetTagHandler(tag string, handler TagHandler) (previous TagHandler, err error) {
+	if err = checkHandlerTag(tag); err == nil {
+		registry.lock.Lock()
+		previous = registry.tags[tag]
+		if handler == nil {
+			delete(registry.tags, tag)
+		} else {
+			registry.tags[tag] = handler
+		}
+		registry.lock.Unlock()
+	}
+
+	return previous, err
+}
+
+// RemoveTagHandler removes the handler for the tag, and returns it if there was one.
+func (registry *Registry) RemoveTagHandler(tag string) (previous TagHandler) {
+	registry.lock.Lock()
+	previous = registry.tags[tag]
+	delete(registry.tags, tag)
+	registry.lock.Unlock()
+
+	return previous
+}
+
+// TagHandler returns the handler for the tag.
+func (registry *Registry) TagHandler(tag string) (handler TagHandler, has bool) {
+	registry.lock.RLock()
+	handler, has = registry.tags[tag]
+	registry.lock.RUnlock()
+
+	return handler, has
+}
+
+// Parse reads exactly one element from the source, using the tag handlers of this registry and the DefaultLimits.
+func (registry *Registry) Parse(src []byte) (elem Element, err error) {
+	rd := newReader(src, DefaultLimits())
+	rd.registry = registry
+
+	if elem, err = rd.next(); err == nil {
+		if err = rd.skipTrivia(); err == nil && !rd.atEnd() {
+			err = rd.fail(rd.offset, "end of input", rd.describe(rd.offset), ErrTrailingInput)
+			elem = nil
+		}
+	} else if err == io.EOF {
+		err = rd.fail(rd.offset, "element", endOfInput, ErrUnexpectedEnd)
+	}
+
+	return elem, err
+}
+
+// ParseIn reads the element at the end of the path from the source like the package level ParseIn, using the tag
+// handlers of this registry and the DefaultLimits.
+func (registry *Registry) ParseIn(src []byte, path ...interface{}) (elem Element, err error) {
+	rd := newReader(src, DefaultLimits())
+	rd.registry = registry
+
+	if elem, err = rd.nextIn(path); err == io.EOF {
+		err = rd.fail(rd.offset, "element", endOfInput, ErrUnexpectedEnd)
+	} else if _, isPath := err.(*PathError); err == nil || isPath {
+		if e := rd.skipTrivia(); e != nil {
+			elem, err = nil, e
+		} else if !rd.atEnd() {
+			elem, err = nil, rd.fail(rd.offset, "end of input", rd.describe(rd.offset), ErrTrailingInput)
+		}
+	}
+
+	return elem, err
+}
+
+// NewDecoder creates a decoder that reads from the input, using the tag handlers of this registry.
+func (registry *Registry) NewDecoder(in io.Reader) *Decoder {
+	return &Decoder{
+		in:       in,
+		registry: registry,
+	}
+}
+
+// checkHandlerTag checks that a handler can be registered for the tag.
+func checkHandlerTag(tag string) (err error) {
+	symbol, e := NewSymbolElement(tag)
+	if e != nil || tag == InstantElementTag || tag == UUIDElementTag {
+		err = ErrInvalidTag
+	} else if _, e = tagText(symbol); e != nil {
+		err = ErrInvalidTag
+	}
+
+	return err
+}
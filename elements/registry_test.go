@@ -0,0 +1,170 @@
+package elements
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Registries", func() {
+
+	upper := func(input interface{}) (Element, error) {
+		return NewStringElement(strings.ToUpper(input.(string)))
+	}
+
+	Context("with factories", func() {
+
+		It("should keep the factories of each registry apart", func() {
+			registry := NewRegistry()
+			Ω(registry.SetFactory(StringType, upper)).ShouldNot(BeNil())
+
+			elem, err := registry.NewElement("abc")
+			Ω(err).Should(BeNil())
+			Ω(elem.Value()).Should(BeEquivalentTo("ABC"))
+
+			elem, err = NewElement("abc")
+			Ω(err).Should(BeNil())
+			Ω(elem.Value()).Should(BeEquivalentTo("abc"))
+		})
+
+		It("should only add factories for types without one", func() {
+			registry := NewRegistry()
+			Ω(registry.AddFactory(StringType, upper)).Should(BeEquivalentTo(ErrInvalidFactory))
+			Ω(registry.AddFactory(URIType, nil)).Should(BeEquivalentTo(ErrInvalidFactory))
+			Ω(registry.AddFactory(URIType, upper)).Should(BeNil())
+
+			_, has := registry.Factory(URIType)
+			Ω(has).Should(BeTrue())
+		})
+
+		It("should unregister factories", func() {
+			registry := NewRegistry()
+			Ω(registry.RemoveFactory(StringType)).ShouldNot(BeNil())
+			Ω(registry.RemoveFactory(StringType)).Should(BeNil())
+
+			_, err := registry.NewElement("abc")
+			Ω(errors.Is(err, ErrUnknownType)).Should(BeTrue())
+		})
+
+		It("should be safe to use concurrently", func() {
+			registry := NewRegistry()
+			wg := sync.WaitGroup{}
+			for i := 0; i < 16; i++ {
+				wg.Add(2)
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+					_, err := registry.NewElement("abc")
+					Ω(err).Should(BeNil())
+				}()
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+					registry.SetFactory(StringType, upper)
+				}()
+			}
+			wg.Wait()
+		})
+	})
+
+	Context("with tag handlers", func() {
+
+		money := func(value Element) (Element, error) {
+			var elem Element
+			parts := strings.Fields(value.Value().(string))
+			coll, err := NewMap()
+			if err == nil {
+				var amount, currency Element
+				if amount, err = NewKeywordElement(":amount"); err == nil {
+					if currency, err = NewKeywordElement(":currency"); err == nil {
+						err = coll.Append(amount, mustElement(parts[0]), currency, mustElement(parts[1]))
+						elem = coll
+					}
+				}
+			}
+			return elem, err
+		}
+
+		It("should convert the tagged values while reading", func() {
+			registry := NewRegistry()
+			Ω(registry.AddTagHandler("myapp/money", money)).Should(BeNil())
+
+			elem, err := registry.Parse([]byte(`[#myapp/money "12.50 EUR" #other 1]`))
+			Ω(err).Should(BeNil())
+			Ω(elem.Serialize()).Should(Or(
+				BeEquivalentTo(`[{:amount "12.50", :currency "EUR"} #other 1]`),
+				BeEquivalentTo(`[{:currency "EUR", :amount "12.50"} #other 1]`)))
+
+			dec := registry.NewDecoder(bytes.NewBufferString(`#myapp/money "1 USD"`))
+			elem, err = dec.Decode()
+			Ω(err).Should(BeNil())
+			Ω(elem.ElementType()).Should(BeEquivalentTo(MapType))
+
+			// the default registry does not know the tag.
+			elem, err = Parse([]byte(`#myapp/money "1 USD"`))
+			Ω(err).Should(BeNil())
+			Ω(elem.ElementType()).Should(BeEquivalentTo(TaggedType))
+		})
+
+		It("should report handler errors at the tagged value", func() {
+			registry := NewRegistry()
+			Ω(registry.AddTagHandler("fail", func(Element) (Element, error) {
+				return nil, ErrInvalidInput
+			})).Should(BeNil())
+			Ω(registry.AddTagHandler("empty", func(Element) (Element, error) {
+				return nil, nil
+			})).Should(BeNil())
+
+			_, err := registry.Parse([]byte(`[1 #fail 2]`))
+			Ω(errors.Is(err, ErrInvalidInput)).Should(BeTrue())
+
+			var parseErr *ParseError
+			Ω(errors.As(err, &parseErr)).Should(BeTrue())
+			Ω(parseErr.Position.Offset).Should(BeEquivalentTo(9))
+
+			_, err = registry.Parse([]byte(`#empty 2`))
+			Ω(errors.Is(err, ErrInvalidElement)).Should(BeTrue())
+		})
+
+		It("should add, replace and remove handlers", func() {
+			registry := NewRegistry()
+			Ω(registry.AddTagHandler("myapp/money", money)).Should(BeNil())
+			Ω(registry.AddTagHandler("myapp/money", money)).Should(BeEquivalentTo(ErrInvalidTag))
+
+			previous, err := registry.SetTagHandler("myapp/money", nil)
+			Ω(err).Should(BeNil())
+			Ω(previous).ShouldNot(BeNil())
+
+			_, has := registry.TagHandler("myapp/money")
+			Ω(has).Should(BeFalse())
+
+			_, err = registry.SetTagHandler("myapp/money", money)
+			Ω(err).Should(BeNil())
+			Ω(registry.RemoveTagHandler("myapp/money")).ShouldNot(BeNil())
+			Ω(registry.RemoveTagHandler("myapp/money")).Should(BeNil())
+		})
+
+		It("should not accept handlers for invalid or built in tags", func() {
+			registry := NewRegistry()
+			for _, tag := range []string{"", "1a", "_a", ":a", "a b", InstantElementTag, UUIDElementTag} {
+				Ω(registry.AddTagHandler(tag, money)).Should(BeEquivalentTo(ErrInvalidTag), tag)
+
+				_, err := registry.SetTagHandler(tag, money)
+				Ω(err).Should(BeEquivalentTo(ErrInvalidTag), tag)
+			}
+		})
+	})
+})
+
+// mustElement creates the element for the value or panics.
+func mustElement(value interface{}) Element {
+	elem, err := NewElement(value)
+	if err != nil {
+		panic(err)
+	}
+	return elem
+}
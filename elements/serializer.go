@@ -6,3 +6,13 @@ type Serializer interface {
 	// Serialize the element into a string or return the appropriate error.
 	Serialize() (composition string, err error)
 }
+
+// Appender defines the interface for writing the entity as edn straight into a buffer, without the allocations of
+// Serialize.
+type Appender interface {
+
+	// AppendEDN appends the serialized element to dst and returns the extended buffer, or the appropriate error. The
+	// output is the same as Serialize. Nothing is allocated unless dst has to grow, apart from what math/big needs to
+	// format big numbers.
+	AppendEDN(dst []byte) (out []byte, err error)
+}
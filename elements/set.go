@@ -10,9 +10,12 @@ const (
 
 	// GroupingSeparatorLiteral is the separator between item in a collection
 	SetSeparatorLiteral = " "
+
+	// ErrDuplicateMember defines the error for a set member that is already in the set.
+	ErrDuplicateMember = Error("Duplicate set member found")
 )
 
-// NewSet creates a new vector
+// NewSet creates a new vector, or returns ErrDuplicateMember if two of the elements are equal.
 func NewSet(elements ...Element) (elem CollectionElement, err error) {
 
 	// check for errors
@@ -32,10 +35,13 @@ func NewSet(elements ...Element) (elem CollectionElement, err error) {
 		}
 
 		var base *baseElemImpl
-		if base, err = makeBaseElement(coll, SetType, collectionSerialization(false)); err == nil {
+		if base, err = makeBaseElement(coll, SetType, collectionAppender(false)); err == nil {
 			coll.baseElemImpl = base
-			elem = coll
-			err = elem.Append(elements...)
+			coll.outer = coll
+			coll.baseElemImpl.equality = collectionEquality
+			if err = coll.Append(elements...); err == nil {
+				elem = coll
+			}
 		}
 	}
 
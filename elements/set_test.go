@@ -44,6 +44,24 @@ var _ = Describe("Set in EDN", func() {
 			Ω(group.Len()).Should(BeEquivalentTo(1))
 		})
 
+		It("should not accept duplicate members", func() {
+			one, err := NewIntegerElement(1)
+			Ω(err).Should(BeNil())
+			other, err := NewIntegerElement(1)
+			Ω(err).Should(BeNil())
+			str, err := NewStringElement("1")
+			Ω(err).Should(BeNil())
+
+			group, err := NewSet(one, other)
+			Ω(err).Should(BeEquivalentTo(ErrDuplicateMember))
+			Ω(group).Should(BeNil())
+
+			group, err = NewSet(one, str)
+			Ω(err).Should(BeNil())
+			Ω(group.Append(other)).Should(BeEquivalentTo(ErrDuplicateMember))
+			Ω(group.Len()).Should(BeEquivalentTo(2))
+		})
+
 		It("should serialize a single nil entry in a set correctly", func() {
 			elem, err := NewNilElement()
 			Ω(err).Should(BeNil())
@@ -0,0 +1,57 @@
+package elements
+
+import (
+	"database/sql/driver"
+)
+
+const (
+
+	// ErrInvalidColumn defines the error for a database value that can not hold EDN.
+	ErrInvalidColumn = Error("Invalid EDN column value")
+)
+
+// Column holds an element stored as EDN text in a database column. It can be passed as a query argument and scanned
+// into. SQL NULL is held as a nil Element, which is distinct from the EDN nil element.
+type Column struct {
+
+	// Element held by the column.
+	Element Element
+}
+
+// Value returns the element in EDN, or nil for SQL NULL.
+func (c Column) Value() (value driver.Value, err error) {
+	if c.Element != nil {
+		var text string
+		if text, err = c.Element.Serialize(); err == nil {
+			value = text
+		}
+	}
+
+	return value, err
+}
+
+// Scan reads the EDN text of a database value into the column.
+func (c *Column) Scan(src interface{}) (err error) {
+	switch v := src.(type) {
+	case nil:
+		c.Element = nil
+	case string:
+		err = c.parse([]byte(v))
+	case []byte:
+		err = c.parse(v)
+	default:
+		err = &ElementError{Value: src, Err: ErrInvalidColumn}
+	}
+
+	return err
+}
+
+// parse reads the EDN into the column.
+func (c *Column) parse(text []byte) (err error) {
+	var elem Element
+	if elem, err = Parse(text); err == nil {
+		c.Element = elem
+	}
+
+	return err
+}
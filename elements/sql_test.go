@@ -0,0 +1,44 @@
+package elements
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Database columns", func() {
+
+	var _ driver.Valuer = Column{}
+	var _ sql.Scanner = &Column{}
+
+	It("should write EDN and NULL", func() {
+		elem, err := Parse([]byte(`{:a [1 2]}`))
+		Ω(err).Should(BeNil())
+
+		value, err := Column{Element: elem}.Value()
+		Ω(err).Should(BeNil())
+		Ω(value).Should(Equal(`{:a [1 2]}`))
+
+		value, err = Column{}.Value()
+		Ω(err).Should(BeNil())
+		Ω(value).Should(BeNil())
+	})
+
+	It("should scan EDN and NULL", func() {
+		col := Column{}
+		Ω(col.Scan([]byte(`#{:a}`))).Should(BeNil())
+		Ω(col.Element.Serialize()).Should(BeEquivalentTo(`#{:a}`))
+
+		Ω(col.Scan(`nil`)).Should(BeNil())
+		Ω(col.Element.ElementType()).Should(BeEquivalentTo(NilType))
+
+		Ω(col.Scan(nil)).Should(BeNil())
+		Ω(col.Element).Should(BeNil())
+
+		Ω(errors.Is(col.Scan(int64(1)), ErrInvalidColumn)).Should(BeTrue())
+		Ω(errors.Is(col.Scan(`[1`), ErrUnexpectedEnd)).Should(BeTrue())
+	})
+})
@@ -5,8 +5,8 @@ import (
 )
 
 // init will add the element factory to the collection of factories
-func initString() error {
-	return AddElementTypeFactory(StringType, func(input interface{}) (elem Element, err error) {
+func initString(registry *Registry) error {
+	return registry.AddFactory(StringType, func(input interface{}) (elem Element, err error) {
 		if v, ok := input.(string); ok {
 			elem, err = NewStringElement(v)
 		} else {
@@ -19,8 +19,8 @@ func initString() error {
 // NewStringElement creates a new string element or an error.
 func NewStringElement(value string) (elem Element, err error) {
 
-	elem, err = makeBaseElement(value, StringType, func(value interface{}) (out string, e error) {
-		out = strconv.Quote(value.(string))
+	elem, err = makeBaseElement(value, StringType, func(dst []byte, value interface{}) (out []byte, e error) {
+		out = strconv.AppendQuote(dst, value.(string))
 		return out, e
 	})
 
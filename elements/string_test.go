@@ -9,13 +9,14 @@ var _ = Describe("String in EDN", func() {
 	Context("", func() {
 
 		It("should initialize without issue", func() {
-			delete(typeFactories, StringType)
-			err := initString()
+			registry := NewRegistry()
+			registry.RemoveFactory(StringType)
+			err := initString(registry)
 			Ω(err).Should(BeNil())
-			_, has := typeFactories[StringType]
+			_, has := registry.Factory(StringType)
 			Ω(has).Should(BeTrue())
 
-			err = initString()
+			err = initString(registry)
 			Ω(err).ShouldNot(BeNil())
 			Ω(err).Should(BeEquivalentTo(ErrInvalidFactory))
 		})
@@ -23,7 +24,10 @@ var _ = Describe("String in EDN", func() {
 		It("should create elements from the factory", func() {
 			v := "Hello world"
 
-			elem, err := typeFactories[StringType](v)
+			factory, has := defaultRegistry.Factory(StringType)
+			Ω(has).Should(BeTrue())
+
+			elem, err := factory(v)
 			Ω(err).Should(BeNil())
 			Ω(elem.ElementType()).Should(BeEquivalentTo(StringType))
 			Ω(elem.Value()).Should(BeEquivalentTo(v))
@@ -32,7 +36,10 @@ var _ = Describe("String in EDN", func() {
 		It("should not create elements from the factory if the input is not a the right type", func() {
 			v := 123
 
-			elem, err := typeFactories[StringType](v)
+			factory, has := defaultRegistry.Factory(StringType)
+			Ω(has).Should(BeTrue())
+
+			elem, err := factory(v)
 			Ω(err).ShouldNot(BeNil())
 			Ω(err).Should(BeEquivalentTo(ErrInvalidInput))
 			Ω(elem).Should(BeNil())
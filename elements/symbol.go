@@ -1,8 +1,6 @@
 package elements
 
 import (
-	"fmt"
-	"regexp"
 	"strings"
 )
 
@@ -28,16 +26,13 @@ const (
 	// symbols that are marked as not being allowed to be first characters other then numeric
 	specialSymbols = KeywordPrefix + `|` + TagPrefix
 
-	// symbolRegex defines the valid symbols.
+	// symbolRegex defines the valid symbols. It documents the rules that validSymbol implements.
 	// Symbols begin with a non-numeric character and can contain alphanumeric characters and . * + ! - _ ? $ % & = < >.
 	// If -, + or . are the first character, the second character (if any) must be non-numeric. Additionally, : # are
 	// allowed as constituent characters in symbols other than as the first character.
 	symbolRegex = `^((` + numericModifierSymbols + `)|((((` + numericModifierSymbols + `)(` + legalFirstSymbols + `|[[:alpha:]]))|(` + legalFirstSymbols + `|[[:alpha:]]))+(` + numericModifierSymbols + `|` + legalFirstSymbols + `|` + specialSymbols + `|[[:alnum:]])*))$`
 )
 
-// symbolMatcher is the matching mechanism for symbols
-var symbolMatcher = regexp.MustCompile(symbolRegex).MatchString
-
 // Symbols are used to represent identifiers, and should map to something other than strings, if possible.
 type SymbolElement interface {
 	Element
@@ -58,111 +53,190 @@ type SymbolElement interface {
 // symbolElemImpl implements the symbolElemImpl
 type symbolElemImpl struct {
 	*baseElemImpl
-	prefix    string
+	*symbolName
 	direction KeywordDirection
-	name      string
-	modifier  string
 }
 
 // NewSymbolElement creates a new character element or an error.
 func NewSymbolElement(parts ...string) (elem SymbolElement, err error) {
+	return newSymbolElement(SymbolType, "", parts)
+}
+
+// newSymbolElement creates a symbol or keyword element with the given type and modifier. The name is looked up in the
+// intern table first, so the parts are only validated the first time a name is seen.
+func newSymbolElement(elemType ElementType, modifier string, parts []string) (elem *symbolElemImpl, err error) {
+
+	key := symbolName{modifier: modifier}
+	if key.prefix, key.name, err = splitSymbolParts(parts); err == nil {
+		name := symbolNames.lookup(key)
+		if name == nil {
+			if validSymbolName(key.prefix, key.name) {
+				name = symbolNames.store(key)
+			} else {
+				err = ErrInvalidSymbol
+			}
+		}
+
+		if err == nil {
+			symElem := &symbolElemImpl{
+				symbolName: name,
+				direction:  ForwardDirection,
+			}
+
+			var base *baseElemImpl
+			if base, err = makeBaseElement(symElem, elemType, appendSymbol); err == nil {
+
+				symElem.baseElemImpl = base
+				symElem.outer = symElem
+
+				// equality for symbols are different then the normal path.
+				symElem.baseElemImpl.equality = symbolEquality
+
+				elem = symElem
+			}
+		}
+	}
+
+	return elem, err
+}
 
-	var prefix string
-	var name string
+// splitSymbolParts splits the parts of a symbol into the prefix and the name without validating either of them.
+func splitSymbolParts(parts []string) (prefix string, name string, err error) {
 
 	switch len(parts) {
 	case 1:
 
-		switch name = parts[0]; {
-
 		// handle the case where the name was really sent in with the separator
+		switch name = parts[0]; {
 		case name == SymbolSeparator:
 			// Fine, break
 
-		case strings.Contains(name, SymbolSeparator):
-			if parts = strings.Split(name, SymbolSeparator); len(parts) == 2 {
-				if prefix = parts[0]; len(prefix) != 0 && symbolMatcher(prefix) {
-					if name = parts[1]; len(name) == 0 || !symbolMatcher(name) {
-						err = ErrInvalidSymbol
-					}
-				} else {
-					err = ErrInvalidSymbol
-				}
-			} else {
-				err = ErrInvalidSymbol
-			}
 		default:
-			if !symbolMatcher(name) {
+			if i := strings.Index(name, SymbolSeparator); i == 0 {
 				err = ErrInvalidSymbol
+			} else if i > 0 {
+				prefix, name = name[:i], name[i+len(SymbolSeparator):]
 			}
 		}
 
 	case 2:
-		if prefix = parts[0]; len(prefix) != 0 && symbolMatcher(prefix) {
-			if name = parts[1]; !symbolMatcher(name) {
-				err = ErrInvalidSymbol
-			}
-		} else {
+		if prefix, name = parts[0], parts[1]; len(prefix) == 0 {
 			err = ErrInvalidSymbol
 		}
+
 	default:
 		err = ErrInvalidSymbol
 	}
 
-	if err == nil {
+	return prefix, name, err
+}
+
+// validSymbolName checks the prefix and name of a symbol. A lone separator is a valid name, but only without a prefix.
+func validSymbolName(prefix string, name string) (valid bool) {
+	if len(prefix) == 0 {
+		valid = name == SymbolSeparator || validSymbol(name)
+	} else {
+		valid = validSymbol(prefix) && validSymbol(name)
+	}
+	return valid
+}
+
+// validSymbol implements the symbol rules described at symbolRegex without a regular expression or any allocation.
+// Symbols begin with a non-numeric character and can contain alphanumeric characters and . * + ! - _ ? $ % & = < >.
+// If -, + or . are the first character, the second character (if any) must be non-numeric. Additionally, : # are
+// allowed as constituent characters in symbols other than as the first character.
+func validSymbol(s string) (valid bool) {
 
-		symElem := &symbolElemImpl{
-			prefix:    prefix,
-			name:      name,
-			direction: ForwardDirection,
-		}
+	switch {
+	case len(s) == 0:
 
-		var base *baseElemImpl
-		if base, err = makeBaseElement(symElem, SymbolType, func(value interface{}) (out string, err error) {
-			if elem, ok := value.(SymbolElement); ok {
-				symbol := elem.Name()
-				if len(prefix) > 0 {
-					symbol = fmt.Sprintf("%s%s%s%s", elem.Prefix(), SymbolSeparator, elem.Direction(), symbol)
-				}
+	// a lone numeric modifier, e.g. + or -
+	case len(s) == 1 && isNumericModifier(s[0]):
+		valid = true
 
-				out = elem.Modifier() + symbol
-			}
+	// a numeric modifier must be followed by a character that can begin a symbol
+	case isNumericModifier(s[0]):
+		valid = isSymbolFirst(s[1]) && validSymbolRest(s[2:])
 
-			return out, err
-		}); err == nil {
+	default:
+		valid = isSymbolFirst(s[0]) && validSymbolRest(s[1:])
+	}
 
-			symElem.baseElemImpl = base
+	return valid
+}
 
-			// equality for symbols are different then the normal path.
-			symElem.baseElemImpl.equality = func(left, right Element) (result bool) {
-				if leftSym, has := left.(SymbolElement); has {
-					if rightSym, has := right.(SymbolElement); has {
-						if leftSym.Name() == rightSym.Name() && leftSym.Prefix() == rightSym.Prefix() && leftSym.Modifier() == rightSym.Modifier() {
-							result = true
-						}
-					}
-				}
+// validSymbolRest checks the characters that follow the start of a symbol.
+func validSymbolRest(s string) (valid bool) {
+	valid = true
+	for i := 0; valid && i < len(s); i++ {
+		c := s[i]
+		valid = isSymbolFirst(c) || isNumericModifier(c) || isDigit(c) || c == KeywordPrefix[0] || c == TagPrefix[0]
+	}
+	return valid
+}
 
-				return result
-			}
+// isNumericModifier is true for the characters that can modify a numeric: . + -
+func isNumericModifier(c byte) bool {
+	return c == '.' || c == '+' || c == '-'
+}
+
+// isSymbolFirst is true for the characters that can begin a symbol other than the numeric modifiers.
+func isSymbolFirst(c byte) (result bool) {
+	switch c {
+	case '*', '!', '_', '?', '$', '%', '&', '=', '<', '>':
+		result = true
+	default:
+		result = (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+	}
+	return result
+}
+
+// isDigit is true for the decimal digits.
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
 
-			elem = symElem
+// appendSymbol is the appender for symbols and keywords.
+func appendSymbol(dst []byte, value interface{}) (out []byte, err error) {
+	out = dst
+	if elem, ok := value.(SymbolElement); ok {
+		out = append(out, elem.Modifier()...)
+		if prefix := elem.Prefix(); len(prefix) > 0 {
+			out = append(append(append(out, prefix...), SymbolSeparator...), elem.Direction()...)
 		}
+
+		out = append(out, elem.Name()...)
 	}
 
-	return elem, err
+	return out, err
 }
 
-// Equals checks if the input element is equal to this element.
-func (elem *symbolElemImpl) Equals(e Element) (result bool) {
-	if elem.ElementType() == e.ElementType() {
-		if elem.Tag() == e.Tag() {
-			result = elem.baseElemImpl.equality(elem, e)
+// symbolEquality compares symbols and keywords, which must have the same direction. Interned names are compared by
+// pointer, anything else by its parts.
+func symbolEquality(left, right Element) (result bool) {
+	if leftSym, has := left.(SymbolElement); has {
+		if rightSym, has := right.(SymbolElement); has {
+			leftImpl, leftIs := leftSym.(*symbolElemImpl)
+			rightImpl, rightIs := rightSym.(*symbolElemImpl)
+
+			switch {
+			case leftSym.Direction() != rightSym.Direction():
+			case leftIs && rightIs && leftImpl.symbolName == rightImpl.symbolName:
+				result = true
+			case leftSym.Name() == rightSym.Name() && leftSym.Prefix() == rightSym.Prefix() && leftSym.Modifier() == rightSym.Modifier():
+				result = true
+			}
 		}
 	}
+
 	return result
 }
 
+// Equals checks if the input element is equal to this element.
+func (elem *symbolElemImpl) Equals(e Element) (result bool) {
+	return equals(elem, elem.baseElemImpl.equality, e)
+}
+
 // Prefix to this symbol
 func (elem *symbolElemImpl) Prefix() string {
 	return elem.prefix
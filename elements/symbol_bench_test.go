@@ -0,0 +1,103 @@
+package elements
+
+import (
+	"regexp"
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// symbolInputs are names used to compare the validator with the regular expression, and to benchmark both.
+var symbolInputs = []string{
+	"", "a", "ident", "db.type", "+", "-", ".", "+a", "-1", ".5", "+.", "--", "-+a", "a-1", "1a", "a1", "a:b", ":a",
+	"#a", "a#", "*", "!x", "_1", "?1", "$a", "%a", "&a", "=a", "<a", ">a", "a/b", "/", "é", "aé", "a b", "a\n",
+	"ok?", "->", "-->", "a.b.c", "x'", "a.", "+:", ".*", "-_", "+<", "a<=>b",
+}
+
+var _ = Describe("Symbol validation", func() {
+
+	It("should accept exactly what the symbol regular expression accepts", func() {
+		matcher := regexp.MustCompile(symbolRegex).MatchString
+
+		// every input, and every two character combination of the interesting bytes.
+		inputs := append([]string{}, symbolInputs...)
+		for c := 0; c < 128; c++ {
+			inputs = append(inputs, string(rune(c)))
+			for _, d := range []byte("a1+.-:#*_/ ") {
+				inputs = append(inputs, string(rune(c))+string(d), string(d)+string(rune(c)))
+			}
+		}
+
+		for _, input := range inputs {
+			Ω(validSymbol(input)).Should(BeEquivalentTo(matcher(input)), input)
+		}
+	})
+
+	It("should reject the illegal keywords", func() {
+		for _, input := range []string{":/", ":/anything", "::kw", ":1kw", "1kw"} {
+			_, err := NewKeywordElement(input)
+			Ω(err).Should(BeEquivalentTo(ErrInvalidKeyword), input)
+		}
+	})
+
+	It("should reject names with more than one separator", func() {
+		for _, input := range []string{"a/b/c", "//", "/a", "a/", "a//"} {
+			_, err := NewSymbolElement(input)
+			Ω(err).Should(BeEquivalentTo(ErrInvalidSymbol), input)
+		}
+	})
+
+	It("should not allocate to validate", func() {
+		allocs := testing.AllocsPerRun(100, func() {
+			validSymbolName("db.type", "keyword")
+		})
+		Ω(allocs).Should(BeZero())
+	})
+})
+
+// BenchmarkValidSymbol measures the hand written validator.
+func BenchmarkValidSymbol(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, input := range symbolInputs {
+			validSymbol(input)
+		}
+	}
+}
+
+// BenchmarkSymbolRegex measures the regular expression the validator replaced.
+func BenchmarkSymbolRegex(b *testing.B) {
+	matcher := regexp.MustCompile(symbolRegex).MatchString
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, input := range symbolInputs {
+			matcher(input)
+		}
+	}
+}
+
+// BenchmarkNewKeywordElement measures creating an interned keyword.
+func BenchmarkNewKeywordElement(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewKeywordElement(":db/ident"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkNewKeywordElementUninterned measures creating a keyword that has to be validated every time.
+func BenchmarkNewKeywordElementUninterned(b *testing.B) {
+	previous := SetInternLimit(0)
+	defer SetInternLimit(previous)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewKeywordElement(":db/ident"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package elements
+
+// TaggedElement is an element under a tag that is not built in, e.g. #myapp/Person {:first "Fred"}. The reader returns
+// one for every tag it does not recognise, so that the data can be inspected, compared and written back unchanged.
+type TaggedElement interface {
+	Element
+
+	// TagSymbol returns the tag as a symbol.
+	TagSymbol() SymbolElement
+
+	// Tagged returns the element the tag applies to.
+	Tagged() Element
+}
+
+// taggedElemImpl implements the TaggedElement interface. The value of the base element is the tagged element, and the
+// tag of the base element is the serialized tag symbol.
+type taggedElemImpl struct {
+	*baseElemImpl
+	symbol SymbolElement
+}
+
+// NewTaggedElement creates a new tagged element or an error. The tag must be an untagged symbol that starts with an
+// alphabetic character.
+func NewTaggedElement(tag SymbolElement, value Element) (elem TaggedElement, err error) {
+
+	var text string
+	if tag == nil || value == nil {
+		err = ErrInvalidInput
+	} else {
+		text, err = tagText(tag)
+	}
+
+	if err == nil {
+		tagged := &taggedElemImpl{
+			symbol: tag,
+		}
+
+		var base *baseElemImpl
+		if base, err = makeBaseElement(value, TaggedType, func(dst []byte, value interface{}) (out []byte, e error) {
+			return value.(Element).AppendEDN(dst)
+		}); err == nil {
+			base.tag = text
+			base.equality = func(left, right Element) bool {
+				return left.Value().(Element).Equals(right.Value().(Element))
+			}
+
+			tagged.baseElemImpl = base
+			tagged.outer = tagged
+			elem = tagged
+		}
+	}
+
+	return elem, err
+}
+
+// tagText returns the text of the tag, or an error if the symbol can not be a tag.
+func tagText(tag SymbolElement) (text string, err error) {
+	if tag.ElementType() != SymbolType || tag.HasTag() {
+		err = ErrInvalidTag
+	} else if text, err = tag.Serialize(); err == nil {
+		if c := text[0]; (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+			err = ErrInvalidTag
+		}
+	}
+
+	return text, err
+}
+
+// TagSymbol returns the tag as a symbol.
+func (elem *taggedElemImpl) TagSymbol() SymbolElement {
+	return elem.symbol
+}
+
+// Tagged returns the element the tag applies to.
+func (elem *taggedElemImpl) Tagged() Element {
+	return elem.value.(Element)
+}
+
+// SetTag replaces the tag. A tagged element always has a tag, so the value must be a valid tag. ErrFrozen is returned
+// if the element is frozen.
+func (elem *taggedElemImpl) SetTag(value string) (err error) {
+
+	var symbol SymbolElement
+	var text string
+	if elem.frozen {
+		err = ErrFrozen
+	} else if symbol, err = NewSymbolElement(value); err == nil {
+		if text, err = tagText(symbol); err == nil {
+			elem.symbol = symbol
+			elem.tag = text
+		}
+	}
+
+	if err != nil && err != ErrFrozen {
+		err = ErrInvalidTag
+	}
+
+	return err
+}
@@ -0,0 +1,189 @@
+package elements
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tagged elements", func() {
+
+	It("should be returned by the reader for unknown tags", func() {
+		elem, err := Parse([]byte(`#myapp/Person {:first "Fred"}`))
+		Ω(err).Should(BeNil())
+		Ω(elem.ElementType()).Should(BeEquivalentTo(TaggedType))
+
+		tagged, is := elem.(TaggedElement)
+		Ω(is).Should(BeTrue())
+		Ω(tagged.Tag()).Should(BeEquivalentTo("myapp/Person"))
+		Ω(tagged.TagSymbol().Prefix()).Should(BeEquivalentTo("myapp"))
+		Ω(tagged.TagSymbol().Name()).Should(BeEquivalentTo("Person"))
+		Ω(tagged.Tagged().ElementType()).Should(BeEquivalentTo(MapType))
+		Ω(tagged.Value()).Should(BeIdenticalTo(tagged.Tagged()))
+		Ω(tagged.Tagged().Span().Start.Offset).Should(BeEquivalentTo(14))
+		Ω(tagged.Span().Start.Offset).Should(BeZero())
+	})
+
+	It("should keep the built in tags as their elements", func() {
+		elem, err := Parse([]byte(`#inst "1985-04-12T23:20:50.52Z"`))
+		Ω(err).Should(BeNil())
+		Ω(elem.ElementType()).Should(BeEquivalentTo(InstantType))
+	})
+
+	It("should serialize back exactly", func() {
+		for _, src := range []string{`#a/b 1`, `#a #b [1 #c "x"]`, `#point [1 2]`, `[#a/b :c #a/b nil]`} {
+			elem, err := Parse([]byte(src))
+			Ω(err).Should(BeNil())
+			Ω(elem.Serialize()).Should(BeEquivalentTo(src))
+		}
+	})
+
+	It("should compare the tag and the tagged element", func() {
+		elem := func(src string) Element {
+			e, err := Parse([]byte(src))
+			Ω(err).Should(BeNil())
+			return e
+		}
+
+		Ω(elem(`#a/b [1 2]`).Equals(elem(`#a/b [1 2]`))).Should(BeTrue())
+		Ω(elem(`#a/b [1 2]`).Equals(elem(`#a/c [1 2]`))).Should(BeFalse())
+		Ω(elem(`#a/b [1 2]`).Equals(elem(`#a/b [1 3]`))).Should(BeFalse())
+		Ω(elem(`#a/b [1 2]`).Equals(elem(`[1 2]`))).Should(BeFalse())
+		Ω(elem(`#a #b 1`).Equals(elem(`#b #a 1`))).Should(BeFalse())
+	})
+
+	It("should equal the same element given the tag with SetTag", func() {
+		for _, test := range []struct {
+			src   string
+			value string
+			equal bool
+		}{
+			{`#my/tag [1]`, `[1]`, true},
+			{`#my/tag {:a #{1}}`, `{:a #{1}}`, true},
+			{`#my/tag :k`, `:k`, true},
+			{`#my/tag "x"`, `"x"`, true},
+			{`#my/tag [1]`, `[2]`, false},
+			{`#my/tag [1]`, `(1)`, false},
+			{`#other [1]`, `[1]`, false},
+			{`#my/tag #other [1]`, `[1]`, false},
+		} {
+			value, err := Parse([]byte(test.value))
+			Ω(err).Should(BeNil())
+			Ω(value.SetTag("my/tag")).Should(Succeed())
+
+			tagged, err := Parse([]byte(test.src))
+			Ω(err).Should(BeNil())
+			Ω(tagged.Equals(value)).Should(Equal(test.equal), test.src)
+			Ω(value.Equals(tagged)).Should(Equal(test.equal), test.src)
+		}
+
+		vector, err := Parse([]byte(`[1]`))
+		Ω(err).Should(BeNil())
+		Ω(vector.SetTag("my/tag")).Should(Succeed())
+		tagged, err := Parse([]byte(`#my/tag [1]`))
+		Ω(err).Should(BeNil())
+
+		first, err := NewPair(vector, int64(1))
+		Ω(err).Should(BeNil())
+		second, err := NewPair(tagged, int64(2))
+		Ω(err).Should(BeNil())
+		_, err = NewMap(first, second)
+		Ω(err).Should(Equal(ErrDuplicateKey))
+	})
+
+	It("should be constructed from a symbol and an element", func() {
+		symbol, err := NewSymbolElement("my", "tag")
+		Ω(err).Should(BeNil())
+
+		value, err := NewIntegerElement(1)
+		Ω(err).Should(BeNil())
+
+		elem, err := NewTaggedElement(symbol, value)
+		Ω(err).Should(BeNil())
+		Ω(elem.Serialize()).Should(BeEquivalentTo("#my/tag 1"))
+		Ω(elem.HasTag()).Should(BeTrue())
+	})
+
+	It("should reject tags that are not plain symbols starting with a letter", func() {
+		value, err := NewIntegerElement(1)
+		Ω(err).Should(BeNil())
+
+		keyword, err := NewKeywordElement("my/tag")
+		Ω(err).Should(BeNil())
+		_, err = NewTaggedElement(keyword, value)
+		Ω(err).Should(BeEquivalentTo(ErrInvalidTag))
+
+		symbol, err := NewSymbolElement("_tag")
+		Ω(err).Should(BeNil())
+		_, err = NewTaggedElement(symbol, value)
+		Ω(err).Should(BeEquivalentTo(ErrInvalidTag))
+
+		symbol, err = NewSymbolElement("tag")
+		Ω(err).Should(BeNil())
+		Ω(symbol.SetTag("other")).Should(BeNil())
+		_, err = NewTaggedElement(symbol, value)
+		Ω(err).Should(BeEquivalentTo(ErrInvalidTag))
+
+		_, err = NewTaggedElement(nil, value)
+		Ω(err).Should(BeEquivalentTo(ErrInvalidInput))
+
+		_, err = NewTaggedElement(keyword, nil)
+		Ω(err).Should(BeEquivalentTo(ErrInvalidInput))
+	})
+
+	It("should only accept valid tags when the tag is replaced", func() {
+		elem, err := Parse([]byte(`#a/b 1`))
+		Ω(err).Should(BeNil())
+
+		Ω(elem.SetTag("c/d")).Should(BeNil())
+		Ω(elem.Serialize()).Should(BeEquivalentTo("#c/d 1"))
+		Ω(elem.(TaggedElement).TagSymbol().Prefix()).Should(BeEquivalentTo("c"))
+
+		for _, tag := range []string{"", "1a", "_a", ":a"} {
+			Ω(elem.SetTag(tag)).Should(BeEquivalentTo(ErrInvalidTag), tag)
+		}
+		Ω(elem.Serialize()).Should(BeEquivalentTo("#c/d 1"))
+	})
+
+	It("should round trip through typed JSON", func() {
+		elem, err := Parse([]byte(`#my/tag {:a #other/tag [1]}`))
+		Ω(err).Should(BeNil())
+
+		data, err := ToJSON(elem, TypedJSON)
+		Ω(err).Should(BeNil())
+
+		back, err := FromJSON(data, TypedJSON)
+		Ω(err).Should(BeNil())
+		Ω(back.Equals(elem)).Should(BeTrue())
+		Ω(back.ElementType()).Should(BeEquivalentTo(TaggedType))
+	})
+
+	It("should be looked through by paths and walks", func() {
+		elem, err := Parse([]byte(`#my/tag {:a #other/tag [1]}`))
+		Ω(err).Should(BeNil())
+
+		value, err := GetIn(elem, ":a", 0)
+		Ω(err).Should(BeNil())
+		Ω(value.Value()).Should(BeEquivalentTo(1))
+
+		result, err := AssocIn(elem, int64(2), ":a", 0)
+		Ω(err).Should(BeNil())
+		Ω(result.Serialize()).Should(BeEquivalentTo(`#my/tag {:a #other/tag [2]}`))
+
+		types := []ElementType{}
+		err = Walk(elem, func(path []Element, e Element) error {
+			types = append(types, e.ElementType())
+			return nil
+		})
+		Ω(err).Should(BeNil())
+		Ω(types).Should(Equal([]ElementType{TaggedType, MapType, KeywordType, TaggedType, VectorType, IntegerType}))
+
+		result, err = Postwalk(elem, func(path []Element, e Element) (Element, error) {
+			if e.ElementType() == VectorType {
+				e = nil
+			}
+			return e, nil
+		})
+		Ω(err).Should(BeNil())
+		Ω(result.Serialize()).Should(BeEquivalentTo(`#my/tag {}`))
+	})
+})
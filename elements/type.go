@@ -74,6 +74,9 @@ const (
 	// URIType is the value type for URIs. Maps to java.net.URI on Java platforms.
 	URIType = ElementType(typeNamespace + SymbolSeparator + "uri")
 
+	// TaggedType is the type of elements under a tag that is not built in.
+	TaggedType = ElementType(typeNamespace + SymbolSeparator + "tagged")
+
 	// BytesType is the value type for small binary data. Maps to byte array on Java platforms. See limitations.
 	BytesType = ElementType(typeNamespace + SymbolSeparator + "bytes")
 
@@ -84,12 +87,10 @@ const (
 	ErrInvalidInput = Error("Invalid input")
 )
 
-// typeFactories hold the collection of element factories.
-var typeFactories = map[ElementType]ElementTypeFactory{}
-
+// elementDefinition defines an element type, and how to add its factory to a registry.
 type elementDefinition struct {
 	isCollection bool
-	initializer  func() error
+	initializer  func(registry *Registry) error
 }
 
 // typeDefinitions holds the type to name/initializer mappings
@@ -102,48 +103,27 @@ var typeDefinitions = map[ElementType]*elementDefinition{
 	SymbolType:    {false, nil},
 	KeywordType:   {false, initKeyword},
 	IntegerType:   {false, initInteger},
+	BigIntType:    {false, initBigInt},
 	FloatType:     {false, initFloat},
+	BigDecType:    {false, initBigDec},
 	InstantType:   {false, initInstant},
 	UUIDType:      {false, initUUID},
 	GroupingType:  {true, nil},
 	VectorType:    {true, nil},
 	MapType:       {true, nil},
 	SetType:       {true, nil},
+	TaggedType:    {false, nil},
 
 	// TODO
 	URIType:    {false, nil},
 	BytesType:  {false, nil},
-	BigIntType: {false, nil},
-	BigDecType: {false, nil},
 	DoubleType: {false, nil},
 	RefType:    {false, nil},
 }
 
-// init will initialize the package - NOTE this is not testable
-func init() {
-	initAll()
-}
-
-// AddElementTypeFactory adds an element factory to the factory collection.
+// AddElementTypeFactory adds an element factory to the default registry.
 func AddElementTypeFactory(elemType ElementType, elemFactory ElementTypeFactory) (err error) {
-	if _, has := typeFactories[elemType]; !has {
-		typeFactories[elemType] = elemFactory
-	} else {
-		err = ErrInvalidFactory
-	}
-
-	return err
-}
-
-// init the package
-func initAll() {
-	for _, def := range typeDefinitions {
-		if def.initializer != nil {
-			if e := def.initializer(); e != nil {
-				panic(e)
-			}
-		}
-	}
+	return defaultRegistry.AddFactory(elemType, elemFactory)
 }
 
 // IsCollection indicates that this type is a collection
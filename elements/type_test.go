@@ -9,13 +9,12 @@ import (
 
 var _ = Describe("Types in EDN", func() {
 	Context("with the default usage", func() {
-		It("initialization should not panic the first iteration, but should on the second.", func() {
-
-			for key := range typeFactories {
-				delete(typeFactories, key)
+		It("should give every new registry the built in factories", func() {
+			registry := NewRegistry()
+			for elemType, def := range typeDefinitions {
+				_, has := registry.Factory(elemType)
+				Ω(has).Should(BeEquivalentTo(def.initializer != nil), elemType.Name())
 			}
-			Ω(initAll).ShouldNot(Panic())
-			Ω(initAll).Should(Panic())
 		})
 
 		typeCollectionMap := map[ElementType]struct {
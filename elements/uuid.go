@@ -11,8 +11,8 @@ const (
 )
 
 // init will add the element factory to the collection of factories
-func initUUID() error {
-	return AddElementTypeFactory(UUIDType, func(input interface{}) (elem Element, err error) {
+func initUUID(registry *Registry) error {
+	return registry.AddFactory(UUIDType, func(input interface{}) (elem Element, err error) {
 		if v, ok := input.(uuid.UUID); ok {
 			elem, err = NewUUIDElement(v)
 		} else {
@@ -24,8 +24,8 @@ func initUUID() error {
 
 // NewInstantElement creates a new instant element or an error.
 func NewUUIDElement(value uuid.UUID) (elem Element, err error) {
-	if elem, err = makeBaseElement(value, UUIDType, func(value interface{}) (out string, e error) {
-		out = value.(uuid.UUID).String()
+	if elem, err = makeBaseElement(value, UUIDType, func(dst []byte, value interface{}) (out []byte, e error) {
+		out = appendUUID(dst, value.(uuid.UUID))
 		return out, e
 	}); err == nil {
 		elem.SetTag(UUIDElementTag)
@@ -33,3 +33,15 @@ func NewUUIDElement(value uuid.UUID) (elem Element, err error) {
 
 	return elem, err
 }
+
+// appendUUID appends the uuid in its canonical form, e.g. f81d4fae-7dec-11d0-a765-00a0c91e6bf6.
+func appendUUID(dst []byte, value uuid.UUID) []byte {
+	const digits = "0123456789abcdef"
+	for i, b := range value {
+		if i == 4 || i == 6 || i == 8 || i == 10 {
+			dst = append(dst, '-')
+		}
+		dst = append(dst, digits[b>>4], digits[b&0xf])
+	}
+	return dst
+}
@@ -10,13 +10,14 @@ var _ = Describe("UUID in EDN", func() {
 	Context("", func() {
 
 		It("should initialize without issue", func() {
-			delete(typeFactories, UUIDType)
-			err := initUUID()
+			registry := NewRegistry()
+			registry.RemoveFactory(UUIDType)
+			err := initUUID(registry)
 			Ω(err).Should(BeNil())
-			_, has := typeFactories[UUIDType]
+			_, has := registry.Factory(UUIDType)
 			Ω(has).Should(BeTrue())
 
-			err = initUUID()
+			err = initUUID(registry)
 			Ω(err).ShouldNot(BeNil())
 			Ω(err).Should(BeEquivalentTo(ErrInvalidFactory))
 		})
@@ -26,7 +27,10 @@ var _ = Describe("UUID in EDN", func() {
 			v, err := uuid.ParseUUID(uuidValue)
 			Ω(err).Should(BeNil())
 
-			elem, err := typeFactories[UUIDType](v)
+			factory, has := defaultRegistry.Factory(UUIDType)
+			Ω(has).Should(BeTrue())
+
+			elem, err := factory(v)
 			Ω(err).Should(BeNil())
 			Ω(elem.ElementType()).Should(BeEquivalentTo(UUIDType))
 			Ω(elem.Value()).Should(BeEquivalentTo(v))
@@ -35,7 +39,10 @@ var _ = Describe("UUID in EDN", func() {
 		It("should not create elements from the factory if the input is not a the right type", func() {
 			v := "foo"
 
-			elem, err := typeFactories[UUIDType](v)
+			factory, has := defaultRegistry.Factory(UUIDType)
+			Ω(has).Should(BeTrue())
+
+			elem, err := factory(v)
 			Ω(err).ShouldNot(BeNil())
 			Ω(err).Should(BeEquivalentTo(ErrInvalidInput))
 			Ω(elem).Should(BeNil())
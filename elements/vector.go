@@ -32,8 +32,10 @@ func NewVector(elements ...Element) (elem CollectionElement, err error) {
 		}
 
 		var base *baseElemImpl
-		if base, err = makeBaseElement(coll, VectorType, collectionSerialization(false)); err == nil {
+		if base, err = makeBaseElement(coll, VectorType, collectionAppender(false)); err == nil {
 			coll.baseElemImpl = base
+			coll.outer = coll
+			coll.baseElemImpl.equality = collectionEquality
 			elem = coll
 			err = elem.Append(elements...)
 		}
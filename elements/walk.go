@@ -0,0 +1,188 @@
+package elements
+
+const (
+
+	// SkipChildren is returned by a walk or transform function to not descend into the children of the current element.
+	SkipChildren = Error("Skip children")
+
+	// StopWalk is returned by a walk or transform function to end the walk early. It is not returned to the caller.
+	StopWalk = Error("Stop walk")
+)
+
+// WalkFunc is called for every element visited by Walk. The path holds the keys from the root to the element: map keys
+// for map entries and integer indexes for everything else. Map keys are visited as well, just before their values, with
+// the same path as their value. The element under a tag is visited after the tagged element, with the same path.
+type WalkFunc func(path []Element, elem Element) (err error)
+
+// TransformFunc is called for every element visited by Prewalk and Postwalk, with the same paths as WalkFunc. The
+// returned element replaces the one passed in, returning the same element keeps it and returning nil removes it from
+// the parent collection (for map keys and values the whole entry is removed).
+type TransformFunc func(path []Element, elem Element) (replacement Element, err error)
+
+// Walk visits the element and every element within it, parents before children. Returning SkipChildren from the
+// function skips the children of that element, StopWalk ends the walk and any other error ends the walk and is
+// returned.
+func Walk(elem Element, fn WalkFunc) (err error) {
+	if err = walk(nil, elem, fn); err == StopWalk {
+		err = nil
+	}
+
+	return err
+}
+
+// Prewalk transforms the element tree from the top down. The function is called on each element before its children,
+// and the children of the replacement are walked. Collections are rebuilt only when one of their children changes.
+// StopWalk ends the walk and returns the tree with the replacements made so far.
+func Prewalk(elem Element, fn TransformFunc) (result Element, err error) {
+	if result, err = transform(nil, elem, fn, true); err == StopWalk {
+		err = nil
+	}
+
+	return result, err
+}
+
+// Postwalk transforms the element tree from the bottom up. The function is called on each element after its children
+// have been transformed, so it sees the rebuilt collection. StopWalk ends the walk and returns the tree with the
+// replacements made so far.
+func Postwalk(elem Element, fn TransformFunc) (result Element, err error) {
+	if result, err = transform(nil, elem, fn, false); err == StopWalk {
+		err = nil
+	}
+
+	return result, err
+}
+
+// childPath extends the path without sharing the backing array with siblings.
+func childPath(path []Element, key Element) []Element {
+	extended := make([]Element, len(path)+1)
+	copy(extended, path)
+	extended[len(path)] = key
+	return extended
+}
+
+// walk visits the element and its children.
+func walk(path []Element, elem Element, fn WalkFunc) (err error) {
+	if err = fn(path, elem); err == nil {
+		if coll, is := elem.(CollectionElement); is {
+			isMap := coll.ElementType() == MapType
+			err = coll.IterateChildren(func(key Element, child Element) (e error) {
+				p := childPath(path, key)
+				if isMap {
+					e = walk(p, key, fn)
+				}
+				if e == nil {
+					e = walk(p, child, fn)
+				}
+				return e
+			})
+		} else if tagged, is := elem.(TaggedElement); is {
+			err = walk(path, tagged.Tagged(), fn)
+		}
+	} else if err == SkipChildren {
+		err = nil
+	}
+
+	return err
+}
+
+// transform applies the function to the element and its children. When the walk is stopped, the partially
+// transformed element is returned with StopWalk.
+func transform(path []Element, elem Element, fn TransformFunc, pre bool) (result Element, err error) {
+
+	result = elem
+	descend := true
+	if pre {
+		if result, err = fn(path, elem); err == SkipChildren {
+			err = nil
+			descend = false
+		}
+	}
+
+	if err == nil && descend && result != nil {
+		if coll, is := result.(CollectionElement); is {
+			result, err = transformChildren(path, coll, fn, pre)
+		} else if tagged, is := result.(TaggedElement); is {
+			result, err = transformTagged(path, tagged, fn, pre)
+		}
+	}
+
+	if err == nil && !pre && result != nil {
+		if result, err = fn(path, result); err == SkipChildren {
+			err = nil
+		}
+	}
+
+	return result, err
+}
+
+// transformChildren applies the transform to all children and rebuilds the collection if any of them changed.
+func transformChildren(path []Element, coll CollectionElement, fn TransformFunc, pre bool) (result Element, err error) {
+
+	isMap := coll.ElementType() == MapType
+	children := make([]Element, 0, coll.Len()*2)
+	changed := false
+	stopped := false
+
+	err = coll.IterateChildren(func(key Element, child Element) (e error) {
+		newKey, newChild := key, child
+
+		if !stopped {
+			p := childPath(path, key)
+			if isMap {
+				newKey, e = transform(p, key, fn, pre)
+			}
+			if e == nil && newKey != nil {
+				newChild, e = transform(p, child, fn, pre)
+			}
+
+			if e == StopWalk {
+				stopped = true
+				e = nil
+			}
+		}
+
+		if e == nil {
+			changed = changed || newKey != key || newChild != child
+			switch {
+			case isMap && newKey != nil && newChild != nil:
+				children = append(children, newKey, newChild)
+			case !isMap && newChild != nil:
+				children = append(children, newChild)
+			}
+		}
+
+		return e
+	})
+
+	result = coll
+	if err == nil && changed {
+		result, err = rebuildCollection(coll, children)
+	}
+
+	if err == nil && stopped {
+		err = StopWalk
+	}
+
+	return result, err
+}
+
+// transformTagged applies the transform to the tagged element, which has the same path as its tag, and tags the result
+// again if it changed. Removing the tagged element removes the tag as well.
+func transformTagged(path []Element, tagged TaggedElement, fn TransformFunc, pre bool) (result Element, err error) {
+
+	var inner Element
+	result = tagged
+	if inner, err = transform(path, tagged.Tagged(), fn, pre); err == nil || err == StopWalk {
+		switch {
+		case inner == nil:
+			result = nil
+		case inner != tagged.Tagged():
+			var e error
+			if result, e = NewTaggedElement(tagged.TagSymbol(), inner); e != nil {
+				err = e
+			}
+		}
+	}
+
+	return result, err
+}
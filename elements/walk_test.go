@@ -0,0 +1,193 @@
+package elements
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Walking element trees", func() {
+
+	parse := func(src string) Element {
+		elem, err := Parse([]byte(src))
+		Ω(err).Should(BeNil())
+		return elem
+	}
+
+	serialize := func(elem Element) string {
+		str, err := elem.Serialize()
+		Ω(err).Should(BeNil())
+		return str
+	}
+
+	pathOf := func(path []Element) string {
+		parts := []interface{}{}
+		for _, p := range path {
+			parts = append(parts, p)
+		}
+		return formatPath(parts)
+	}
+
+	Context("with Walk", func() {
+		It("should visit every element, keys included, with its path", func() {
+			visits := map[string][]string{}
+			err := Walk(parse(`[{:db/id 1 :db/ident :person/name} #{:a}]`), func(path []Element, elem Element) error {
+				p := pathOf(path)
+				visits[p] = append(visits[p], serialize(elem))
+				return nil
+			})
+			Ω(err).Should(BeNil())
+
+			Ω(visits["[]"]).Should(HaveLen(1))
+			Ω(visits["[0]"]).Should(HaveLen(1))
+			Ω(visits["[0 :db/id]"]).Should(Equal([]string{":db/id", "1"}))
+			Ω(visits["[0 :db/ident]"]).Should(Equal([]string{":db/ident", ":person/name"}))
+			Ω(visits["[1]"]).Should(Equal([]string{"#{:a}"}))
+			Ω(visits["[1 0]"]).Should(Equal([]string{":a"}))
+		})
+
+		It("should collect every keyword", func() {
+			keywords := []string{}
+			err := Walk(parse(`{:a [:b (:c "d")] :e #{:f}}`), func(path []Element, elem Element) error {
+				if elem.ElementType() == KeywordType {
+					keywords = append(keywords, serialize(elem))
+				}
+				return nil
+			})
+			Ω(err).Should(BeNil())
+			Ω(keywords).Should(ConsistOf(":a", ":b", ":c", ":e", ":f"))
+		})
+
+		It("should skip children and stop early", func() {
+			count := 0
+			err := Walk(parse(`[[1 2 3] [4 5]]`), func(path []Element, elem Element) error {
+				count++
+				if len(path) == 1 {
+					return SkipChildren
+				}
+				return nil
+			})
+			Ω(err).Should(BeNil())
+			Ω(count).Should(BeEquivalentTo(3))
+
+			count = 0
+			err = Walk(parse(`[1 2 3 4 5]`), func(path []Element, elem Element) error {
+				if count++; count == 3 {
+					return StopWalk
+				}
+				return nil
+			})
+			Ω(err).Should(BeNil())
+			Ω(count).Should(BeEquivalentTo(3))
+
+			failure := Error("failure")
+			err = Walk(parse(`[1 2 3]`), func(path []Element, elem Element) error {
+				return failure
+			})
+			Ω(err).Should(BeIdenticalTo(failure))
+		})
+	})
+
+	Context("with transforms", func() {
+
+		It("should replace temp ids bottom up", func() {
+			original := parse(`[{:db/id #db/id [:db.part/user] :person/name "x"} {:db/id 42}]`)
+
+			result, err := Postwalk(original, func(path []Element, elem Element) (Element, error) {
+				if elem.Tag() == "db/id" {
+					return NewIntegerElement(-1)
+				}
+				return elem, nil
+			})
+			Ω(err).Should(BeNil())
+
+			for index, expected := range []int64{-1, 42} {
+				entity, err := result.(CollectionElement).Get(index)
+				Ω(err).Should(BeNil())
+
+				var id Element
+				id, err = entity.(CollectionElement).Get(":db/id")
+				Ω(err).Should(BeNil())
+				Ω(id.Value()).Should(BeEquivalentTo(expected))
+			}
+
+			// the original is untouched.
+			Ω(serialize(original)).Should(ContainSubstring("#db/id [:db.part/user]"))
+		})
+
+		It("should strip tags top down", func() {
+			result, err := Prewalk(parse(`#my/wrapper [#my/thing 1 2]`), func(path []Element, elem Element) (Element, error) {
+				if elem.HasTag() {
+					if coll, is := elem.(CollectionElement); is {
+						children := []Element{}
+						coll.IterateChildren(func(_ Element, child Element) error {
+							children = append(children, child)
+							return nil
+						})
+						return NewVector(children...)
+					}
+					return NewElement(elem.Value())
+				}
+				return elem, nil
+			})
+			Ω(err).Should(BeNil())
+			Ω(serialize(result)).Should(BeEquivalentTo("[1 2]"))
+		})
+
+		It("should remove elements and entries when nil is returned", func() {
+			result, err := Postwalk(parse(`[1 nil 2 {:a nil :b 3}]`), func(path []Element, elem Element) (Element, error) {
+				if elem.ElementType() == NilType {
+					return nil, nil
+				}
+				return elem, nil
+			})
+			Ω(err).Should(BeNil())
+			Ω(serialize(result)).Should(BeEquivalentTo("[1 2 {:b 3}]"))
+		})
+
+		It("should keep the collections that did not change", func() {
+			original := parse(`[[1] [2]]`)
+			first, err := original.(CollectionElement).Get(0)
+			Ω(err).Should(BeNil())
+
+			result, err := Postwalk(original, func(path []Element, elem Element) (Element, error) {
+				if elem.Value() == int64(2) {
+					return NewIntegerElement(3)
+				}
+				return elem, nil
+			})
+			Ω(err).Should(BeNil())
+			Ω(result).ShouldNot(BeIdenticalTo(original))
+
+			resultFirst, err := result.(CollectionElement).Get(0)
+			Ω(err).Should(BeNil())
+			Ω(resultFirst).Should(BeIdenticalTo(first))
+			Ω(serialize(result)).Should(BeEquivalentTo("[[1] [3]]"))
+		})
+
+		It("should stop early with the replacements made so far", func() {
+			count := 0
+			result, err := Prewalk(parse(`[1 2 3]`), func(path []Element, elem Element) (Element, error) {
+				if elem.ElementType() == IntegerType {
+					if count++; count == 2 {
+						return elem, StopWalk
+					}
+					return NewIntegerElement(elem.Value().(int64) * 10)
+				}
+				return elem, nil
+			})
+			Ω(err).Should(BeNil())
+			Ω(serialize(result)).Should(BeEquivalentTo("[10 2 3]"))
+		})
+
+		It("should keep the collection tag when rebuilding", func() {
+			result, err := Postwalk(parse(`#my/tag [1]`), func(path []Element, elem Element) (Element, error) {
+				if elem.ElementType() == IntegerType {
+					return NewIntegerElement(2)
+				}
+				return elem, nil
+			})
+			Ω(err).Should(BeNil())
+			Ω(serialize(result)).Should(BeEquivalentTo("#my/tag [2]"))
+		})
+	})
+})
@@ -0,0 +1,86 @@
+package fressian
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/martinkreibe-wk/geneva/elements"
+)
+
+// benchmarkDatoms builds a datom set shaped like a bulk export: repeated attributes over many entities.
+func benchmarkDatoms(b *testing.B) elements.Element {
+	coll, err := elements.NewVector()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for i := 0; i < 1000; i++ {
+		datom, err := elements.Parse([]byte(`#datom [17592186045422 :person/name "The Goonies" 13194139534317 true]`))
+		if err == nil {
+			err = coll.Append(datom)
+		}
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	return coll
+}
+
+// BenchmarkEncodeFressian measures writing the datom set as Fressian.
+func BenchmarkEncodeFressian(b *testing.B) {
+	elem := benchmarkDatoms(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Marshal(elem); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkEncodeEDN measures writing the datom set as EDN text.
+func BenchmarkEncodeEDN(b *testing.B) {
+	elem := benchmarkDatoms(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := elem.Serialize(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkDecodeFressian measures reading the datom set from Fressian.
+func BenchmarkDecodeFressian(b *testing.B) {
+	data, err := Marshal(benchmarkDatoms(b))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewDecoder(bytes.NewReader(data)).Decode(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkDecodeEDN measures reading the datom set from EDN text.
+func BenchmarkDecodeEDN(b *testing.B) {
+	text, err := benchmarkDatoms(b).Serialize()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.SetBytes(int64(len(text)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := elements.Parse([]byte(text)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
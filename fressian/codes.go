@@ -0,0 +1,106 @@
+// Package fressian reads and writes elements and datoms in the Fressian binary format used by Datomic-family systems
+// such as Eva. Keywords and symbols share their namespace and name strings through the priority cache, tagged
+// structures after their first use are written as a single byte through the struct cache, and long strings and byte
+// arrays are written in chunks.
+package fressian
+
+import (
+	"github.com/martinkreibe-wk/geneva/elements"
+)
+
+const (
+
+	// ErrInvalidFressian defines the error for input that is not valid Fressian.
+	ErrInvalidFressian = elements.Error("Invalid fressian")
+
+	// ErrInvalidCacheIndex defines the error for a cache reference to an entry that was never written.
+	ErrInvalidCacheIndex = elements.Error("Invalid cache reference")
+
+	// ErrUnsupportedCode defines the error for a value that has no element type, such as byte or primitive arrays.
+	ErrUnsupportedCode = elements.Error("Unsupported fressian code")
+
+	// ErrNotDatom defines the error for a value that is not a datom.
+	ErrNotDatom = elements.Error("Value is not a datom")
+)
+
+// the codes of the Fressian format.
+const (
+	priorityCachePackedStart = 0x80
+	priorityCachePackedEnd   = 0xA0
+	structCachePackedStart   = 0xA0
+	structCachePackedEnd     = 0xB0
+	mapCode                  = 0xC0
+	setCode                  = 0xC1
+	uuidCode                 = 0xC3
+	bigIntCode               = 0xC6
+	bigDecCode               = 0xC7
+	instCode                 = 0xC8
+	symCode                  = 0xC9
+	keyCode                  = 0xCA
+	getPriorityCache         = 0xCC
+	putPriorityCache         = 0xCD
+	footerCode               = 0xCF
+	bytesPackedLengthStart   = 0xD0
+	bytesPackedLengthEnd     = 0xD8
+	bytesChunk               = 0xD8
+	bytesCode                = 0xD9
+	stringPackedLengthStart  = 0xDA
+	stringPackedLengthEnd    = 0xE2
+	stringChunk              = 0xE2
+	stringCode               = 0xE3
+	listPackedLengthStart    = 0xE4
+	listPackedLengthEnd      = 0xEC
+	listCode                 = 0xEC
+	beginClosedList          = 0xED
+	beginOpenList            = 0xEE
+	structType               = 0xEF
+	structCode               = 0xF0
+	metaCode                 = 0xF1
+	trueCode                 = 0xF5
+	falseCode                = 0xF6
+	nullCode                 = 0xF7
+	intCode                  = 0xF8
+	doubleCode               = 0xFA
+	double0                  = 0xFB
+	double1                  = 0xFC
+	endCollection            = 0xFD
+	resetCaches              = 0xFE
+	intPacked1Neg            = 0xFF
+	intPacked1End            = 0x40
+	intPacked2Zero           = 0x50
+	intPacked2End            = 0x60
+	intPacked3Zero           = 0x68
+	intPacked3End            = 0x70
+	intPacked4Zero           = 0x72
+	intPacked4End            = 0x74
+	intPacked5Zero           = 0x76
+	intPacked5End            = 0x78
+	intPacked6Zero           = 0x7A
+	intPacked6End            = 0x7C
+	intPacked7Zero           = 0x7E
+	intPacked7End            = 0x80
+
+	// footerMagic starts the footer written at the end of a Fressian file.
+	footerMagic = 0xCFCFCFCF
+)
+
+// the tags of the structures without a code of their own.
+const (
+	charTag  = "char"
+	listTag  = "list"
+	datomTag = elements.DatomTag
+)
+
+// chunkSize is the largest string or byte array written without chunks, and the size of each chunk.
+var chunkSize = 1 << 16
+
+// builtinTag returns the tag that is part of the element type rather than a tagged structure.
+func builtinTag(elemType elements.ElementType) (tag string) {
+	switch elemType {
+	case elements.InstantType:
+		tag = elements.InstantElementTag
+	case elements.UUIDType:
+		tag = elements.UUIDElementTag
+	}
+	return tag
+}
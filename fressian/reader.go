@@ -0,0 +1,686 @@
+package fressian
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+	"unicode/utf16"
+
+	"github.com/martinkreibe-wk/geneva/elements"
+	"github.com/mattrobenolt/gocql/uuid"
+)
+
+// Unmarshal reads the Fressian value into an element. The data must hold exactly one value.
+func Unmarshal(data []byte) (elem elements.Element, err error) {
+	dec := NewDecoder(bytes.NewReader(data))
+	if elem, err = dec.Decode(); err == nil {
+		if _, e := dec.r.Peek(1); e != io.EOF {
+			elem, err = nil, elements.ErrTrailingInput
+		}
+	} else if err == io.EOF {
+		err = elements.ErrUnexpectedEnd
+	}
+
+	return elem, err
+}
+
+// Decoder reads a stream of Fressian values. Like the encoder, the caches are shared by every value in the stream.
+type Decoder struct {
+
+	// r is the source of the stream.
+	r *bufio.Reader
+
+	// priority holds the cached values by index.
+	priority []elements.Element
+
+	// structs holds the structure types by index.
+	structs []structDef
+
+	// limits bound the sizes read from the stream.
+	limits elements.Limits
+
+	// depth is the nesting level of the value being read, 0 for the values at the top.
+	depth int
+}
+
+// readChunkSize is the most that is allocated ahead of the data read for a string or byte array, so a length larger
+// than the input fails at the end of the input rather than allocating it all up front.
+const readChunkSize = 64 << 10
+
+// structDef defines a structure type.
+type structDef struct {
+	tag    string
+	fields int
+}
+
+// NewDecoder creates a decoder that reads from the reader, with the default limits.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r), limits: elements.DefaultLimits()}
+}
+
+// SetLimits sets the limits on the sizes read from the stream. MaxCollectionSize bounds lists, sets, maps and the
+// fields of structures, MaxStringLength bounds strings and byte arrays, counted in bytes, and MaxDepth bounds how
+// deeply values nest, with every value within another adding a level. A zero field takes its default, a negative field
+// removes the limit, and the other limits do not apply. A size past a limit fails with ErrInvalidFressian, which also
+// matches the LimitError of the limit. Without a depth limit, deeply nested input can exhaust the stack.
+func (dec *Decoder) SetLimits(limits elements.Limits) {
+	defaults := elements.DefaultLimits()
+	if limits.MaxDepth == 0 {
+		limits.MaxDepth = defaults.MaxDepth
+	}
+	if limits.MaxCollectionSize == 0 {
+		limits.MaxCollectionSize = defaults.MaxCollectionSize
+	}
+	if limits.MaxStringLength == 0 {
+		limits.MaxStringLength = defaults.MaxStringLength
+	}
+
+	dec.limits = limits
+}
+
+// checkSize fails a size read from the stream that is negative or passes the limit.
+func checkSize(size int64, limit int, reason elements.LimitError) (err error) {
+	if size < 0 {
+		err = ErrInvalidFressian
+	} else if limit >= 0 && size > int64(limit) {
+		err = elements.NewError("%w: %w", ErrInvalidFressian, reason)
+	}
+
+	return err
+}
+
+// Decode reads the next element, io.EOF is returned at the end of the stream or at the footer. Datoms are read as a
+// vector under a #datom tag.
+func (dec *Decoder) Decode() (elem elements.Element, err error) {
+	var code byte
+	if code, err = dec.top(); err == nil {
+		if elem, err = dec.element(code); errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			err = elements.ErrUnexpectedEnd
+		}
+	}
+
+	return elem, err
+}
+
+// DecodeDatom reads the next value, which must be a datom.
+func (dec *Decoder) DecodeDatom() (datom elements.Datom, err error) {
+
+	var elem elements.Element
+	if elem, err = dec.Decode(); err == nil {
+		var fields []elements.Element
+		if coll, is := untagged(elem, datomTag).(elements.CollectionElement); is && coll.Len() == 5 {
+			err = coll.IterateChildren(func(_ elements.Element, child elements.Element) error {
+				fields = append(fields, child)
+				return nil
+			})
+		} else {
+			err = ErrNotDatom
+		}
+
+		if err == nil {
+			e, eIs := fields[0].Value().(int64)
+			a, aIs := fields[1].Value().(int64)
+			t, tIs := fields[3].Value().(int64)
+			added, addedIs := fields[4].Value().(bool)
+			if eIs && aIs && tIs && addedIs {
+				datom, err = elements.NewDatom(e, a, fields[2].Value(), elements.T(t), added)
+			} else {
+				err = ErrNotDatom
+			}
+		}
+	}
+
+	return datom, err
+}
+
+// top reads the code that starts the next top level value, skipping cache resets and stopping at the footer.
+func (dec *Decoder) top() (code byte, err error) {
+	for code, err = dec.r.ReadByte(); err == nil && code == resetCaches; code, err = dec.r.ReadByte() {
+		dec.reset()
+	}
+
+	if err == nil && code == footerCode {
+		var magic []byte
+		if magic, err = dec.r.Peek(3); err == nil && bytes.Equal(magic, []byte{0xCF, 0xCF, 0xCF}) {
+			err = io.EOF
+		} else {
+			err = ErrInvalidFressian
+		}
+	}
+
+	return code, err
+}
+
+// reset clears the caches.
+func (dec *Decoder) reset() {
+	dec.priority = nil
+	dec.structs = nil
+}
+
+// next reads the code of the next value.
+func (dec *Decoder) next() (elem elements.Element, err error) {
+	var code byte
+	if code, err = dec.r.ReadByte(); err == nil {
+		elem, err = dec.element(code)
+	}
+
+	return elem, err
+}
+
+// element reads the value that starts with the code, one level deeper than the value it is within.
+func (dec *Decoder) element(code byte) (elem elements.Element, err error) {
+	if err = checkSize(int64(dec.depth), dec.limits.MaxDepth, elements.ErrDepthLimit); err == nil {
+		dec.depth++
+		elem, err = dec.value(code)
+		dec.depth--
+	}
+
+	return elem, err
+}
+
+// value reads the value that starts with the code.
+func (dec *Decoder) value(code byte) (elem elements.Element, err error) {
+
+	switch {
+	case code == nullCode:
+		elem, err = elements.NewNilElement()
+
+	case code == trueCode || code == falseCode:
+		elem, err = elements.NewBooleanElement(code == trueCode)
+
+	case isInt(code):
+		var i int64
+		if i, err = dec.intValue(code); err == nil {
+			elem, err = elements.NewIntegerElement(i)
+		}
+
+	case code == double0 || code == double1 || code == doubleCode:
+		var f float64
+		if f, err = dec.double(code); err == nil {
+			elem, err = elements.NewFloatElement(f)
+		}
+
+	case code >= stringPackedLengthStart && code <= stringCode:
+		var s string
+		if s, err = dec.str(code); err == nil {
+			elem, err = elements.NewStringElement(s)
+		}
+
+	case code >= listPackedLengthStart && code <= beginOpenList:
+		var items []elements.Element
+		if items, err = dec.list(code); err == nil {
+			elem, err = elements.NewVector(items...)
+		}
+
+	case code == setCode || code == mapCode:
+		elem, err = dec.collection(code)
+
+	case code == keyCode || code == symCode:
+		elem, err = dec.symbol(code)
+
+	case code == bigIntCode:
+		var i *big.Int
+		if i, err = dec.bigInt(); err == nil {
+			elem, err = elements.NewBigIntElement(i)
+		}
+
+	case code == bigDecCode:
+		elem, err = dec.bigDec()
+
+	case code == instCode:
+		var ms int64
+		if ms, err = dec.int(); err == nil {
+			elem, err = elements.NewInstantElement(time.Unix(0, ms*int64(time.Millisecond)).UTC())
+		}
+
+	case code == uuidCode:
+		var data []byte
+		if data, err = dec.bytes(); err == nil {
+			var u uuid.UUID
+			if u, err = uuid.FromBytes(data); err == nil {
+				elem, err = elements.NewUUIDElement(u)
+			} else {
+				err = ErrInvalidFressian
+			}
+		}
+
+	case code == structType || code == structCode || (code >= structCachePackedStart && code < structCachePackedEnd):
+		elem, err = dec.structure(code)
+
+	case code == putPriorityCache:
+		if elem, err = dec.next(); err == nil {
+			dec.priority = append(dec.priority, elem)
+		}
+
+	case code == getPriorityCache || (code >= priorityCachePackedStart && code < priorityCachePackedEnd):
+		index := int64(code - priorityCachePackedStart)
+		if code == getPriorityCache {
+			index, err = dec.int()
+		}
+
+		if err == nil {
+			if index >= 0 && index < int64(len(dec.priority)) {
+				elem = dec.priority[index]
+			} else {
+				err = ErrInvalidCacheIndex
+			}
+		}
+
+	case code == metaCode:
+
+		// metadata has no place in the element model, so it is dropped.
+		if _, err = dec.next(); err == nil {
+			elem, err = dec.next()
+		}
+
+	case code == resetCaches:
+		dec.reset()
+		elem, err = dec.next()
+
+	default:
+		err = ErrUnsupportedCode
+	}
+
+	if err != nil {
+		elem = nil
+	}
+
+	return elem, err
+}
+
+// isInt is true if the code starts an integer.
+func isInt(code byte) bool {
+	return code < intPacked7End || code == intPacked1Neg || code == intCode
+}
+
+// int reads an integer.
+func (dec *Decoder) int() (i int64, err error) {
+	var code byte
+	if code, err = dec.r.ReadByte(); err == nil {
+		if isInt(code) {
+			i, err = dec.intValue(code)
+		} else {
+			err = ErrInvalidFressian
+		}
+	}
+
+	return i, err
+}
+
+// intValue reads the rest of the integer that starts with the code.
+func (dec *Decoder) intValue(code byte) (i int64, err error) {
+
+	var zero int64
+	var size int
+	switch {
+	case code == intPacked1Neg:
+		i = -1
+	case code < intPacked1End:
+		i = int64(code)
+	case code < intPacked2End:
+		zero, size = intPacked2Zero, 1
+	case code < intPacked3End:
+		zero, size = intPacked3Zero, 2
+	case code < intPacked4End:
+		zero, size = intPacked4Zero, 3
+	case code < intPacked5End:
+		zero, size = intPacked5Zero, 4
+	case code < intPacked6End:
+		zero, size = intPacked6Zero, 5
+	case code < intPacked7End:
+		zero, size = intPacked7Zero, 6
+	default:
+		size = 8
+	}
+
+	if size > 0 {
+		raw := make([]byte, 8)
+		if _, err = io.ReadFull(dec.r, raw[8-size:]); err == nil {
+			i = int64(binary.BigEndian.Uint64(raw))
+			if size < 8 {
+				i |= (int64(code) - zero) << (8 * uint(size))
+			}
+		}
+	}
+
+	return i, err
+}
+
+// double reads the rest of the float that starts with the code.
+func (dec *Decoder) double(code byte) (f float64, err error) {
+	switch code {
+	case double0:
+		f = 0
+	case double1:
+		f = 1
+	default:
+		raw := make([]byte, 8)
+		if _, err = io.ReadFull(dec.r, raw); err == nil {
+			f = math.Float64frombits(binary.BigEndian.Uint64(raw))
+		}
+	}
+
+	return f, err
+}
+
+// chunked reads data written with the packed, chunk and final codes, starting with the code.
+func (dec *Decoder) chunked(code, packedStart, packedEnd, chunk, final byte) (data []byte, err error) {
+	for more := true; more && err == nil; {
+		var size int64
+		switch {
+		case code >= packedStart && code < packedEnd:
+			size = int64(code - packedStart)
+		case code == chunk || code == final:
+			size, err = dec.int()
+		default:
+			err = ErrInvalidFressian
+		}
+
+		if err == nil {
+			err = checkSize(int64(len(data))+size, dec.limits.MaxStringLength, elements.ErrStringLimit)
+		}
+
+		for err == nil && size > 0 {
+			part := int64(readChunkSize)
+			if size < part {
+				part = size
+			}
+
+			start := len(data)
+			data = append(data, make([]byte, part)...)
+			_, err = io.ReadFull(dec.r, data[start:])
+			size -= part
+		}
+
+		if more = err == nil && code == chunk; more {
+			code, err = dec.r.ReadByte()
+		}
+	}
+
+	return data, err
+}
+
+// bytes reads a byte array.
+func (dec *Decoder) bytes() (data []byte, err error) {
+	var code byte
+	if code, err = dec.r.ReadByte(); err == nil {
+		data, err = dec.chunked(code, bytesPackedLengthStart, bytesPackedLengthEnd, bytesChunk, bytesCode)
+	}
+
+	return data, err
+}
+
+// str reads the rest of the string that starts with the code.
+func (dec *Decoder) str(code byte) (s string, err error) {
+	var data []byte
+	if data, err = dec.chunked(code, stringPackedLengthStart, stringPackedLengthEnd, stringChunk, stringCode); err == nil {
+		s, err = decodeString(data)
+	}
+
+	return s, err
+}
+
+// decodeString reverses encodeString.
+func decodeString(data []byte) (s string, err error) {
+	units := make([]uint16, 0, len(data))
+	for i := 0; err == nil && i < len(data); {
+		switch b := data[i]; {
+		case b < 0x80:
+			units = append(units, uint16(b))
+			i++
+		case b&0xE0 == 0xC0 && i+1 < len(data):
+			units = append(units, uint16(b&0x1F)<<6|uint16(data[i+1]&0x3F))
+			i += 2
+		case b&0xF0 == 0xE0 && i+2 < len(data):
+			units = append(units, uint16(b&0x0F)<<12|uint16(data[i+1]&0x3F)<<6|uint16(data[i+2]&0x3F))
+			i += 3
+		default:
+			err = ErrInvalidFressian
+		}
+	}
+
+	if err == nil {
+		s = string(utf16.Decode(units))
+	}
+
+	return s, err
+}
+
+// list reads the items of a list that starts with the code.
+func (dec *Decoder) list(code byte) (items []elements.Element, err error) {
+
+	var size int64
+	switch {
+	case code < listPackedLengthEnd:
+		size = int64(code - listPackedLengthStart)
+	case code == listCode:
+		size, err = dec.int()
+	}
+
+	if err == nil {
+		err = checkSize(size, dec.limits.MaxCollectionSize, elements.ErrCollectionLimit)
+	}
+
+	if code == beginClosedList || code == beginOpenList {
+		for done := false; !done && err == nil; {
+			var next byte
+			switch next, err = dec.r.ReadByte(); {
+			case err == io.EOF && code == beginOpenList:
+				err, done = nil, true
+			case err != nil:
+			case next == endCollection:
+				done = true
+			default:
+				var item elements.Element
+				if item, err = dec.element(next); err == nil {
+					if err = checkSize(int64(len(items)+1), dec.limits.MaxCollectionSize, elements.ErrCollectionLimit); err == nil {
+						items = append(items, item)
+					}
+				}
+			}
+		}
+	} else if err == nil {
+		items, err = dec.items(size)
+	}
+
+	return items, err
+}
+
+// items reads the number of elements. Every element takes at least a byte, so a count larger than the input fails at
+// the end of the input, and the slice only grows with the elements read.
+func (dec *Decoder) items(count int64) (items []elements.Element, err error) {
+	if count < readChunkSize {
+		items = make([]elements.Element, 0, count)
+	}
+
+	for i := int64(0); err == nil && i < count; i++ {
+		var item elements.Element
+		if item, err = dec.next(); err == nil {
+			items = append(items, item)
+		}
+	}
+
+	return items, err
+}
+
+// collection reads the list of a set or of a map's alternating keys and values.
+func (dec *Decoder) collection(code byte) (elem elements.Element, err error) {
+
+	var next byte
+	var items []elements.Element
+	if next, err = dec.r.ReadByte(); err == nil {
+		if next >= listPackedLengthStart && next <= beginOpenList {
+			items, err = dec.list(next)
+		} else {
+			err = ErrInvalidFressian
+		}
+	}
+
+	if err == nil {
+		if code == setCode {
+			elem, err = elements.NewSet(items...)
+		} else if len(items)%2 == 0 {
+			pairs := make([]elements.Pair, len(items)/2)
+			for i := 0; err == nil && i < len(pairs); i++ {
+				pairs[i], err = elements.NewPair(items[2*i], items[2*i+1])
+			}
+			if err == nil {
+				elem, err = elements.NewMap(pairs...)
+			}
+		} else {
+			err = elements.ErrInvalidPair
+		}
+	}
+
+	return elem, err
+}
+
+// symbol reads the namespace and name of a keyword or symbol.
+func (dec *Decoder) symbol(code byte) (elem elements.Element, err error) {
+
+	var ns, name elements.Element
+	if ns, err = dec.next(); err == nil {
+		name, err = dec.next()
+	}
+
+	parts := []string{}
+	if err == nil {
+		nsText, nsIs := ns.Value().(string)
+		nameText, nameIs := name.Value().(string)
+		switch {
+		case nsIs && nameIs:
+			parts = append(parts, nsText, nameText)
+		case ns.ElementType() == elements.NilType && nameIs:
+			parts = append(parts, nameText)
+		default:
+			err = ErrInvalidFressian
+		}
+	}
+
+	if err == nil {
+		if code == keyCode {
+			elem, err = elements.NewKeywordElement(parts...)
+		} else {
+			elem, err = elements.NewSymbolElement(parts...)
+		}
+	}
+
+	return elem, err
+}
+
+// bigInt reads the two's complement bytes of a big integer.
+func (dec *Decoder) bigInt() (i *big.Int, err error) {
+	var data []byte
+	if data, err = dec.bytes(); err == nil {
+		i = new(big.Int).SetBytes(data)
+		if len(data) > 0 && data[0]&0x80 != 0 {
+			i.Sub(i, new(big.Int).Lsh(big.NewInt(1), uint(len(data)*8)))
+		}
+	}
+
+	return i, err
+}
+
+// bigDec reads the unscaled value and scale of a big decimal.
+func (dec *Decoder) bigDec() (elem elements.Element, err error) {
+	var unscaled *big.Int
+	var scale int64
+	if unscaled, err = dec.bigInt(); err == nil {
+		if scale, err = dec.int(); err == nil {
+			text := unscaled.String() + "e" + strconv.FormatInt(-scale, 10) + elements.BigDecSuffix
+			if elem, err = elements.Parse([]byte(text)); err != nil {
+				err = ErrInvalidFressian
+			}
+		}
+	}
+
+	return elem, err
+}
+
+// structure reads a tagged structure. Structures with a tag other than the known ones are read as tagged elements, of the
+// field if there is a single one and of a vector of the fields otherwise.
+func (dec *Decoder) structure(code byte) (elem elements.Element, err error) {
+
+	var def structDef
+	switch code {
+	case structType:
+		var tag elements.Element
+		var fields int64
+		if tag, err = dec.next(); err == nil {
+			if fields, err = dec.int(); err == nil {
+				err = checkSize(fields, dec.limits.MaxCollectionSize, elements.ErrCollectionLimit)
+			}
+
+			if err == nil {
+				if name, is := tag.Value().(string); is && tag.ElementType() == elements.StringType {
+					def = structDef{tag: name, fields: int(fields)}
+					dec.structs = append(dec.structs, def)
+				} else {
+					err = ErrInvalidFressian
+				}
+			}
+		}
+
+	default:
+		index := int64(code - structCachePackedStart)
+		if code == structCode {
+			index, err = dec.int()
+		}
+
+		if err == nil {
+			if index >= 0 && index < int64(len(dec.structs)) {
+				def = dec.structs[index]
+			} else {
+				err = ErrInvalidCacheIndex
+			}
+		}
+	}
+
+	var fields []elements.Element
+	if err == nil {
+		fields, err = dec.items(int64(def.fields))
+	}
+
+	if err == nil {
+		switch {
+		case def.tag == charTag && len(fields) == 1 && fields[0].ElementType() == elements.IntegerType:
+			elem, err = elements.NewCharacterElement(rune(fields[0].Value().(int64)))
+
+		case def.tag == listTag && len(fields) == 1 && fields[0].ElementType() == elements.VectorType:
+			var items []elements.Element
+			fields[0].(elements.CollectionElement).IterateChildren(func(_ elements.Element, child elements.Element) error {
+				items = append(items, child)
+				return nil
+			})
+			elem, err = elements.NewGroup(items...)
+
+		default:
+			var symbol elements.SymbolElement
+			var value elements.Element
+			if symbol, err = elements.NewSymbolElement(def.tag); err != nil {
+				err = elements.ErrInvalidTag
+			} else if len(fields) == 1 {
+				elem, err = elements.NewTaggedElement(symbol, fields[0])
+			} else if value, err = elements.NewVector(fields...); err == nil {
+				elem, err = elements.NewTaggedElement(symbol, value)
+			}
+		}
+	}
+
+	return elem, err
+}
+
+// untagged returns the element under the tag, or nil if the element is not tagged with it.
+func untagged(elem elements.Element, tag string) (value elements.Element) {
+	if tagged, is := elem.(elements.TaggedElement); is && tagged.Tag() == tag {
+		value = tagged.Tagged()
+	}
+
+	return value
+}
@@ -0,0 +1,214 @@
+package fressian
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/martinkreibe-wk/geneva/elements"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reading fressian", func() {
+
+	parse := func(src string) elements.Element {
+		elem, err := elements.Parse([]byte(src))
+		Ω(err).Should(BeNil(), src)
+		return elem
+	}
+
+	It("should round trip every element type", func() {
+		sources := []string{
+			`nil`, `true`, `false`, `0`, `-7`, `4096`, `9223372036854775807`, `0.0`, `1.0`, `-2.5`, `""`, `"short"`,
+			`"a string long enough to need a length"`, `\c`, `\λ`, `:db/ident`, `:db/_ident`, `:plain`, `sym`,
+			`my/sym`, `123456789012345678901234567890N`, `-5N`, `0.001M`, `-12.5e40M`,
+			`#inst "2020-01-02T03:04:05.123Z"`, `#uuid "f81d4fae-7dec-11d0-a765-00a0c91e6bf6"`, `[]`, `()`, `#{}`,
+			`{}`, `[1 [2 [3]] (4 5) #{6 :seven}]`, `{:a 1 "b" [2] [3] {:c #{4}}}`, `#my/tag {:a #other/tag [1]}`,
+			`[1 2 3 4 5 6 7 8 9 10 11 12]`,
+		}
+
+		for _, src := range sources {
+			elem := parse(src)
+			data, err := Marshal(elem)
+			Ω(err).Should(BeNil(), src)
+
+			back, err := Unmarshal(data)
+			Ω(err).Should(BeNil(), src)
+			Ω(back.Equals(elem)).Should(BeTrue(), src)
+		}
+	})
+
+	It("should share the caches across a stream", func() {
+		buf := &bytes.Buffer{}
+		enc := NewEncoder(buf)
+		Ω(enc.Encode(parse(`[:db/ident \a]`))).Should(BeNil())
+		Ω(enc.Encode(parse(`[:db/ident \b]`))).Should(BeNil())
+		Ω(enc.Reset()).Should(BeNil())
+		Ω(enc.Encode(parse(`:db/ident`))).Should(BeNil())
+
+		dec := NewDecoder(buf)
+		for _, expected := range []string{`[:db/ident \a]`, `[:db/ident \b]`, `:db/ident`} {
+			elem, err := dec.Decode()
+			Ω(err).Should(BeNil(), expected)
+			Ω(elem.Equals(parse(expected))).Should(BeTrue(), expected)
+		}
+
+		_, err := dec.Decode()
+		Ω(err).Should(BeIdenticalTo(io.EOF))
+	})
+
+	It("should keep the stream readable after a failed write", func() {
+		buf := &bytes.Buffer{}
+		enc := NewEncoder(buf)
+
+		bad, err := elements.NewVector()
+		Ω(err).Should(BeNil())
+		Ω(bad.Append(parse(`:new/kw`), &unknownElement{bad})).Should(BeNil())
+		Ω(enc.Encode(bad)).ShouldNot(BeNil())
+		Ω(buf.Len()).Should(BeZero())
+
+		Ω(enc.Encode(parse(`[:other/kw :new/kw]`))).Should(BeNil())
+		elem, err := Unmarshal(buf.Bytes())
+		Ω(err).Should(BeNil())
+		Ω(elem.Equals(parse(`[:other/kw :new/kw]`))).Should(BeTrue())
+	})
+
+	It("should read and write datoms", func() {
+		buf := &bytes.Buffer{}
+		enc := NewEncoder(buf)
+		for i := int64(0); i < 3; i++ {
+			datom, err := elements.NewDatom(17592186045422+i, 63, "The Goonies", 13194139534317, i != 1)
+			Ω(err).Should(BeNil())
+			Ω(enc.EncodeDatom(datom)).Should(BeNil())
+		}
+		Ω(enc.Encode(parse(`1`))).Should(BeNil())
+
+		dec := NewDecoder(buf)
+		for i := int64(0); i < 3; i++ {
+			datom, err := dec.DecodeDatom()
+			Ω(err).Should(BeNil())
+			Ω(datom.EntityId()).Should(BeEquivalentTo(17592186045422 + i))
+			Ω(datom.AttributeId()).Should(BeEquivalentTo(63))
+			Ω(datom.Value()).Should(BeEquivalentTo("The Goonies"))
+			Ω(datom.Transaction()).Should(BeEquivalentTo(13194139534317))
+			Ω(datom.Added()).Should(Equal(i != 1))
+		}
+
+		_, err := dec.DecodeDatom()
+		Ω(err).Should(BeIdenticalTo(ErrNotDatom))
+	})
+
+	It("should read the lists and markers other writers use", func() {
+		sources := map[string][]byte{
+			`[1 2]`:   {0xED, 0x01, 0x02, 0xFD},
+			`[1 2 3]`: {0xEE, 0x01, 0x02, 0x03},
+			`:a/b`:    {0xF1, 0xC0, 0xE4, 0xCA, 0xDB, 'a', 0xDB, 'b'},
+			`"x"`:     {0xFE, 0xDB, 'x'},
+			`2`:       {0x02, 0xCF, 0xCF, 0xCF, 0xCF},
+		}
+
+		for expected, data := range sources {
+			elem, err := NewDecoder(bytes.NewReader(data)).Decode()
+			Ω(err).Should(BeNil(), expected)
+			Ω(elem.Equals(parse(expected))).Should(BeTrue(), expected)
+		}
+	})
+
+	It("should refuse malformed fressian", func() {
+		malformed := map[string]struct {
+			data []byte
+			err  error
+		}{
+			"empty":          {[]byte{}, elements.ErrUnexpectedEnd},
+			"short int":      {[]byte{0xF8, 0x01}, elements.ErrUnexpectedEnd},
+			"short list":     {[]byte{0xE6, 0x01}, elements.ErrUnexpectedEnd},
+			"priority cache": {[]byte{0x80}, ErrInvalidCacheIndex},
+			"struct cache":   {[]byte{0xA3}, ErrInvalidCacheIndex},
+			"int array":      {[]byte{0xB3, 0x00}, ErrUnsupportedCode},
+			"odd map":        {[]byte{0xC0, 0xE5, 0x01}, elements.ErrInvalidPair},
+			"bad keyword":    {[]byte{0xCA, 0xF7, 0x01}, ErrInvalidFressian},
+			"bad string":     {[]byte{0xDB, 0xFF}, ErrInvalidFressian},
+			"trailing":       {[]byte{0x01, 0x02}, elements.ErrTrailingInput},
+			"struct tag":     {[]byte{0xEF, 0x01, 0x01, 0x01}, ErrInvalidFressian},
+			"negative list":  {[]byte{0xEC, 0xF8, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, ErrInvalidFressian},
+			"huge list":      {[]byte{0xEC, 0xF8, 0x7F, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, ErrInvalidFressian},
+			"huge string":    {[]byte{0xE3, 0xF8, 0x7F, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, ErrInvalidFressian},
+			"large list":     {[]byte{0xEC, 0xF8, 0, 0, 0, 0, 0x40, 0, 0, 0}, elements.ErrCollectionLimit},
+			"large string":   {[]byte{0xE3, 0xF8, 0, 0, 0, 0, 0x40, 0, 0, 0}, elements.ErrStringLimit},
+			"large struct":   {[]byte{0xEF, 0xDB, 0x61, 0xF8, 0, 0, 0, 0, 0x40, 0, 0, 0}, ErrInvalidFressian},
+		}
+
+		for name, bad := range malformed {
+			elem, err := Unmarshal(bad.data)
+			Ω(elem).Should(BeNil(), name)
+			Ω(errors.Is(err, bad.err)).Should(BeTrue(), name)
+		}
+	})
+
+	It("should not allocate lengths the input does not hold when there are no limits", func() {
+		for _, data := range [][]byte{
+			{0xEC, 0xF8, 0x7F, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF},
+			{0xE3, 0xF8, 0x7F, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF},
+			{0xEC, 0xF8, 0, 0, 0, 0, 0x40, 0, 0, 0},
+			{0xC3, 0xD9, 0xF8, 0, 0, 0, 0, 0x40, 0, 0, 0, 0x61},
+		} {
+			dec := NewDecoder(bytes.NewReader(data))
+			dec.SetLimits(elements.Limits{MaxCollectionSize: -1, MaxStringLength: -1})
+			elem, err := dec.Decode()
+			Ω(elem).Should(BeNil())
+			Ω(err).Should(Equal(elements.ErrUnexpectedEnd))
+		}
+	})
+
+	It("should read strings and lists up to the limits", func() {
+		data, err := Marshal(parse(`["` + strings.Repeat("x", 100000) + `" [1 2 3]]`))
+		Ω(err).Should(BeNil())
+
+		dec := NewDecoder(bytes.NewReader(data))
+		dec.SetLimits(elements.Limits{MaxCollectionSize: 3, MaxStringLength: 100000})
+		_, err = dec.Decode()
+		Ω(err).Should(BeNil())
+
+		dec = NewDecoder(bytes.NewReader(data))
+		dec.SetLimits(elements.Limits{MaxCollectionSize: 2})
+		_, err = dec.Decode()
+		Ω(errors.Is(err, elements.ErrCollectionLimit)).Should(BeTrue())
+
+		dec = NewDecoder(bytes.NewReader(data))
+		dec.SetLimits(elements.Limits{MaxStringLength: 99999})
+		_, err = dec.Decode()
+		Ω(errors.Is(err, elements.ErrStringLimit)).Should(BeTrue())
+	})
+
+	It("should refuse values nested deeper than the limit", func() {
+		elem, err := Unmarshal(bytes.Repeat([]byte{0xE5}, 20<<20))
+		Ω(elem).Should(BeNil())
+		Ω(errors.Is(err, ErrInvalidFressian)).Should(BeTrue())
+		Ω(errors.Is(err, elements.ErrDepthLimit)).Should(BeTrue())
+
+		data, err := Marshal(parse(strings.Repeat("[", 10) + strings.Repeat("]", 10)))
+		Ω(err).Should(BeNil())
+
+		dec := NewDecoder(bytes.NewReader(data))
+		dec.SetLimits(elements.Limits{MaxDepth: 9})
+		_, err = dec.Decode()
+		Ω(err).Should(BeNil())
+
+		dec = NewDecoder(bytes.NewReader(data))
+		dec.SetLimits(elements.Limits{MaxDepth: 8})
+		_, err = dec.Decode()
+		Ω(errors.Is(err, elements.ErrDepthLimit)).Should(BeTrue())
+	})
+})
+
+// unknownElement is an element of a type fressian cannot write.
+type unknownElement struct {
+	elements.Element
+}
+
+// ElementType returns a type without an encoding.
+func (elem *unknownElement) ElementType() elements.ElementType {
+	return elements.URIType
+}
@@ -0,0 +1,418 @@
+package fressian
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"math/big"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/martinkreibe-wk/geneva/elements"
+	"github.com/mattrobenolt/gocql/uuid"
+)
+
+// Marshal writes the element as Fressian.
+func Marshal(elem elements.Element) (data []byte, err error) {
+	buf := &bytes.Buffer{}
+	if err = NewEncoder(buf).Encode(elem); err == nil {
+		data = buf.Bytes()
+	}
+
+	return data, err
+}
+
+// Encoder writes a stream of elements and datoms as Fressian. The caches are shared by every value written, so later
+// values refer to the keywords and structures of earlier ones.
+type Encoder struct {
+
+	// w receives each value once it is complete.
+	w io.Writer
+
+	// buf holds the value being written.
+	buf bytes.Buffer
+
+	// priority maps the cached strings to their index.
+	priority map[string]int
+
+	// structs maps the structure tags to their index.
+	structs map[string]int
+}
+
+// NewEncoder creates an encoder that writes to the writer.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{
+		w:        w,
+		priority: map[string]int{},
+		structs:  map[string]int{},
+	}
+}
+
+// Encode writes the element.
+func (enc *Encoder) Encode(elem elements.Element) (err error) {
+	priority, structs := len(enc.priority), len(enc.structs)
+	if err = enc.element(elem); err == nil {
+		err = enc.flush()
+	} else {
+		enc.rollback(priority, structs)
+	}
+
+	return err
+}
+
+// EncodeDatom writes the datom as a datom structure of entity, attribute, value, transaction and added.
+func (enc *Encoder) EncodeDatom(datom elements.Datom) (err error) {
+
+	priority, structs := len(enc.priority), len(enc.structs)
+
+	var value elements.Element
+	if value, err = elements.NewElement(datom.Value()); err == nil {
+		enc.structure(datomTag, 5)
+		enc.int(datom.EntityId())
+		enc.int(datom.AttributeId())
+		if err = enc.element(value); err == nil {
+			enc.int(int64(datom.Transaction()))
+			enc.boolean(datom.Added())
+		}
+	}
+
+	if err == nil {
+		err = enc.flush()
+	} else {
+		enc.rollback(priority, structs)
+	}
+
+	return err
+}
+
+// rollback drops the value being written and the cache entries it added, so the stream stays readable.
+func (enc *Encoder) rollback(priority, structs int) {
+	enc.buf.Reset()
+	for s, index := range enc.priority {
+		if index >= priority {
+			delete(enc.priority, s)
+		}
+	}
+	for tag, index := range enc.structs {
+		if index >= structs {
+			delete(enc.structs, tag)
+		}
+	}
+}
+
+// Reset clears the caches. Readers clear theirs when they reach the reset.
+func (enc *Encoder) Reset() (err error) {
+	enc.buf.WriteByte(resetCaches)
+	enc.priority = map[string]int{}
+	enc.structs = map[string]int{}
+	return enc.flush()
+}
+
+// flush writes the completed value.
+func (enc *Encoder) flush() (err error) {
+	_, err = enc.w.Write(enc.buf.Bytes())
+	enc.buf.Reset()
+	return err
+}
+
+// element writes the element with its tag.
+func (enc *Encoder) element(elem elements.Element) (err error) {
+	if tag := elem.Tag(); tag != builtinTag(elem.ElementType()) {
+		enc.structure(tag, 1)
+	}
+
+	switch elem.ElementType() {
+	case elements.NilType:
+		enc.buf.WriteByte(nullCode)
+
+	case elements.BooleanType:
+		enc.boolean(elem.Value().(bool))
+
+	case elements.IntegerType:
+		enc.int(elem.Value().(int64))
+
+	case elements.FloatType:
+		enc.double(elem.Value().(float64))
+
+	case elements.StringType:
+		enc.string(elem.Value().(string))
+
+	case elements.CharacterType:
+		enc.structure(charTag, 1)
+		enc.int(int64(elem.Value().(rune)))
+
+	case elements.KeywordType, elements.SymbolType:
+		enc.symbol(elem.(elements.SymbolElement))
+
+	case elements.BigIntType:
+		enc.buf.WriteByte(bigIntCode)
+		enc.bytes(bigIntBytes(elem.Value().(*big.Int)))
+
+	case elements.BigDecType:
+		unscaled, scale := bigDecParts(elem.Value().(*big.Float))
+		enc.buf.WriteByte(bigDecCode)
+		enc.bytes(bigIntBytes(unscaled))
+		enc.int(scale)
+
+	case elements.InstantType:
+		enc.buf.WriteByte(instCode)
+		enc.int(elem.Value().(time.Time).UnixNano() / int64(time.Millisecond))
+
+	case elements.UUIDType:
+		enc.buf.WriteByte(uuidCode)
+		enc.bytes(elem.Value().(uuid.UUID).Bytes())
+
+	case elements.VectorType:
+		err = enc.list(elem.(elements.CollectionElement), false)
+
+	case elements.GroupingType:
+		enc.structure(listTag, 1)
+		err = enc.list(elem.(elements.CollectionElement), false)
+
+	case elements.SetType:
+		enc.buf.WriteByte(setCode)
+		err = enc.list(elem.(elements.CollectionElement), false)
+
+	case elements.MapType:
+		enc.buf.WriteByte(mapCode)
+		err = enc.list(elem.(elements.CollectionElement), true)
+
+	case elements.TaggedType:
+		err = enc.element(elem.(elements.TaggedElement).Tagged())
+
+	default:
+		err = &elements.ElementError{Type: elem.ElementType(), Value: elem.Value(), Err: elements.ErrUnknownType}
+	}
+
+	return err
+}
+
+// boolean writes the boolean.
+func (enc *Encoder) boolean(b bool) {
+	if b {
+		enc.buf.WriteByte(trueCode)
+	} else {
+		enc.buf.WriteByte(falseCode)
+	}
+}
+
+// int writes the integer in the fewest bytes.
+func (enc *Encoder) int(i int64) {
+	var raw [8]byte
+	binary.BigEndian.PutUint64(raw[:], uint64(i))
+
+	switch zeros := bits.LeadingZeros64(uint64(i ^ (i >> 63))); {
+	case zeros < 15:
+		enc.buf.WriteByte(intCode)
+		enc.buf.Write(raw[:])
+	case zeros < 23:
+		enc.buf.WriteByte(byte(intPacked7Zero + (i >> 48)))
+		enc.buf.Write(raw[2:])
+	case zeros < 31:
+		enc.buf.WriteByte(byte(intPacked6Zero + (i >> 40)))
+		enc.buf.Write(raw[3:])
+	case zeros < 39:
+		enc.buf.WriteByte(byte(intPacked5Zero + (i >> 32)))
+		enc.buf.Write(raw[4:])
+	case zeros < 45:
+		enc.buf.WriteByte(byte(intPacked4Zero + (i >> 24)))
+		enc.buf.Write(raw[5:])
+	case zeros < 52:
+		enc.buf.WriteByte(byte(intPacked3Zero + (i >> 16)))
+		enc.buf.Write(raw[6:])
+	case zeros < 58 || i < -1:
+		enc.buf.WriteByte(byte(intPacked2Zero + (i >> 8)))
+		enc.buf.Write(raw[7:])
+	default:
+		enc.buf.WriteByte(byte(i))
+	}
+}
+
+// double writes the float.
+func (enc *Encoder) double(f float64) {
+	switch {
+	case f == 0 && !math.Signbit(f):
+		enc.buf.WriteByte(double0)
+	case f == 1:
+		enc.buf.WriteByte(double1)
+	default:
+		var raw [8]byte
+		binary.BigEndian.PutUint64(raw[:], math.Float64bits(f))
+		enc.buf.WriteByte(doubleCode)
+		enc.buf.Write(raw[:])
+	}
+}
+
+// chunked writes data with the packed, chunk and final codes, splitting it so no chunk is longer than chunkSize. The
+// split function returns how many bytes of the data form the next chunk.
+func (enc *Encoder) chunked(data []byte, packedStart, packedEnd, chunk, final byte, split func([]byte) int) {
+	for len(data) > chunkSize {
+		n := split(data)
+		enc.buf.WriteByte(chunk)
+		enc.int(int64(n))
+		enc.buf.Write(data[:n])
+		data = data[n:]
+	}
+
+	if len(data) < int(packedEnd-packedStart) {
+		enc.buf.WriteByte(packedStart + byte(len(data)))
+	} else {
+		enc.buf.WriteByte(final)
+		enc.int(int64(len(data)))
+	}
+	enc.buf.Write(data)
+}
+
+// bytes writes the byte array.
+func (enc *Encoder) bytes(data []byte) {
+	enc.chunked(data, bytesPackedLengthStart, bytesPackedLengthEnd, bytesChunk, bytesCode, func([]byte) int {
+		return chunkSize
+	})
+}
+
+// string writes the string. Chunks never split a character.
+func (enc *Encoder) string(s string) {
+	enc.chunked(encodeString(s), stringPackedLengthStart, stringPackedLengthEnd, stringChunk, stringCode, func(data []byte) int {
+		n := chunkSize
+		for n > 0 && data[n]&0xC0 == 0x80 {
+			n--
+		}
+		return n
+	})
+}
+
+// cachedString writes the string through the priority cache.
+func (enc *Encoder) cachedString(s string) {
+	if index, has := enc.priority[s]; has {
+		if index < priorityCachePackedEnd-priorityCachePackedStart {
+			enc.buf.WriteByte(byte(priorityCachePackedStart + index))
+		} else {
+			enc.buf.WriteByte(getPriorityCache)
+			enc.int(int64(index))
+		}
+	} else {
+		enc.priority[s] = len(enc.priority)
+		enc.buf.WriteByte(putPriorityCache)
+		enc.string(s)
+	}
+}
+
+// symbol writes the namespace and name of the keyword or symbol.
+func (enc *Encoder) symbol(sym elements.SymbolElement) {
+	if sym.ElementType() == elements.KeywordType {
+		enc.buf.WriteByte(keyCode)
+	} else {
+		enc.buf.WriteByte(symCode)
+	}
+
+	if prefix := sym.Prefix(); prefix == "" {
+		enc.buf.WriteByte(nullCode)
+	} else {
+		enc.cachedString(prefix)
+	}
+
+	enc.cachedString(string(sym.Direction()) + sym.Name())
+}
+
+// structure writes the start of a tagged structure with the number of fields that follow.
+func (enc *Encoder) structure(tag string, fields int) {
+	if index, has := enc.structs[tag]; has {
+		if index < structCachePackedEnd-structCachePackedStart {
+			enc.buf.WriteByte(byte(structCachePackedStart + index))
+		} else {
+			enc.buf.WriteByte(structCode)
+			enc.int(int64(index))
+		}
+	} else {
+		enc.structs[tag] = len(enc.structs)
+		enc.buf.WriteByte(structType)
+		enc.string(tag)
+		enc.int(int64(fields))
+	}
+}
+
+// list writes the children of the collection, for maps the keys and values alternate.
+func (enc *Encoder) list(coll elements.CollectionElement, withKeys bool) (err error) {
+	count := coll.Len()
+	if withKeys {
+		count *= 2
+	}
+
+	if count < listPackedLengthEnd-listPackedLengthStart {
+		enc.buf.WriteByte(byte(listPackedLengthStart + count))
+	} else {
+		enc.buf.WriteByte(listCode)
+		enc.int(int64(count))
+	}
+
+	err = coll.IterateChildren(func(key elements.Element, child elements.Element) (e error) {
+		if withKeys {
+			e = enc.element(key)
+		}
+		if e == nil {
+			e = enc.element(child)
+		}
+		return e
+	})
+
+	return err
+}
+
+// encodeString encodes the string the way Fressian does: every UTF-16 unit is written as one to three bytes, so
+// characters outside the basic plane take six.
+func encodeString(s string) []byte {
+	data := make([]byte, 0, len(s))
+	for _, unit := range utf16.Encode([]rune(s)) {
+		switch {
+		case unit <= 0x7F:
+			data = append(data, byte(unit))
+		case unit <= 0x7FF:
+			data = append(data, byte(0xC0|unit>>6), byte(0x80|unit&0x3F))
+		default:
+			data = append(data, byte(0xE0|unit>>12), byte(0x80|(unit>>6)&0x3F), byte(0x80|unit&0x3F))
+		}
+	}
+
+	return data
+}
+
+// bigIntBytes returns the minimal big endian two's complement bytes of the integer.
+func bigIntBytes(i *big.Int) (data []byte) {
+	if i.Sign() >= 0 {
+		data = append([]byte{0}, i.Bytes()...)
+	} else {
+		size := new(big.Int).Not(i).BitLen()/8 + 1
+		complement := new(big.Int).Add(i, new(big.Int).Lsh(big.NewInt(1), uint(size*8)))
+		data = complement.FillBytes(make([]byte, size))
+	}
+
+	// drop the leading bytes that only repeat the sign.
+	for len(data) > 1 && ((data[0] == 0 && data[1]&0x80 == 0) || (data[0] == 0xFF && data[1]&0x80 != 0)) {
+		data = data[1:]
+	}
+
+	return data
+}
+
+// bigDecParts returns the unscaled value and the scale of the decimal, so that it equals unscaled × 10^-scale.
+func bigDecParts(f *big.Float) (unscaled *big.Int, scale int64) {
+	text := f.Text('e', -1)
+
+	exponent := int64(0)
+	if at := strings.IndexByte(text, 'e'); at >= 0 {
+		exponent, _ = strconv.ParseInt(text[at+1:], 10, 64)
+		text = text[:at]
+	}
+
+	if at := strings.IndexByte(text, '.'); at >= 0 {
+		scale = int64(len(text) - at - 1)
+		text = text[:at] + text[at+1:]
+	}
+
+	unscaled, _ = new(big.Int).SetString(text, 10)
+	return unscaled, scale - exponent
+}
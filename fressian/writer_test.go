@@ -0,0 +1,123 @@
+package fressian
+
+import (
+	"bytes"
+
+	"github.com/martinkreibe-wk/geneva/elements"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Writing fressian", func() {
+
+	parse := func(src string) elements.Element {
+		elem, err := elements.Parse([]byte(src))
+		Ω(err).Should(BeNil(), src)
+		return elem
+	}
+
+	It("should pack integers into the fewest bytes", func() {
+		packed := map[int64][]byte{
+			0:        {0x00},
+			63:       {0x3F},
+			-1:       {0xFF},
+			64:       {0x50, 0x40},
+			-2:       {0x4F, 0xFE},
+			4095:     {0x5F, 0xFF},
+			-4096:    {0x40, 0x00},
+			4096:     {0x68, 0x10, 0x00},
+			1 << 40:  {0x7B, 0x00, 0x00, 0x00, 0x00, 0x00},
+			-1 << 62: {0xF8, 0xC0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+		}
+
+		for i, expected := range packed {
+			enc := NewEncoder(&bytes.Buffer{})
+			enc.int(i)
+			Ω(enc.buf.Bytes()).Should(Equal(expected), "%d", i)
+		}
+
+		for _, i := range []int64{0, 1, -1, 63, 64, -64, -65, 1<<12 - 1, -1 << 12, 1 << 19, -1 << 19, 1 << 25, 1<<33 + 5,
+			-1<<41 + 3, 1<<49 - 1, -1 << 49, 1 << 62, -1 << 63, 1<<63 - 1} {
+			buf := &bytes.Buffer{}
+			enc := NewEncoder(buf)
+			enc.int(i)
+			Ω(enc.flush()).Should(BeNil())
+
+			read, err := NewDecoder(buf).int()
+			Ω(err).Should(BeNil())
+			Ω(read).Should(BeEquivalentTo(i))
+		}
+	})
+
+	It("should share keyword parts through the priority cache", func() {
+		buf := &bytes.Buffer{}
+		enc := NewEncoder(buf)
+		Ω(enc.Encode(parse(`:db/ident`))).Should(BeNil())
+		Ω(buf.Bytes()).Should(Equal([]byte{0xCA, 0xCD, 0xDC, 'd', 'b', 0xCD, 0xDF, 'i', 'd', 'e', 'n', 't'}))
+
+		buf.Reset()
+		Ω(enc.Encode(parse(`:db/ident`))).Should(BeNil())
+		Ω(buf.Bytes()).Should(Equal([]byte{0xCA, 0x80, 0x81}))
+
+		buf.Reset()
+		Ω(enc.Encode(parse(`name`))).Should(BeNil())
+		Ω(buf.Bytes()).Should(Equal([]byte{0xC9, 0xF7, 0xCD, 0xDE, 'n', 'a', 'm', 'e'}))
+	})
+
+	It("should cache the structure types", func() {
+		data, err := Marshal(parse(`[\a \b]`))
+		Ω(err).Should(BeNil())
+		Ω(data).Should(Equal([]byte{0xE6, 0xEF, 0xDE, 'c', 'h', 'a', 'r', 0x01, 0x50, 'a', 0xA0, 0x50, 'b'}))
+	})
+
+	It("should write long strings and bytes in chunks", func() {
+		defer func(size int) { chunkSize = size }(chunkSize)
+		chunkSize = 8
+
+		data, err := Marshal(parse(`"abcdefghijkl"`))
+		Ω(err).Should(BeNil())
+		Ω(data).Should(Equal([]byte{0xE2, 0x08, 'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h', 0xDE, 'i', 'j', 'k', 'l'}))
+
+		elem, err := Unmarshal(data)
+		Ω(err).Should(BeNil())
+		Ω(elem.Value()).Should(BeEquivalentTo("abcdefghijkl"))
+
+		// chunks end on a character boundary.
+		data, err = Marshal(parse(`"abcdefgéééé"`))
+		Ω(err).Should(BeNil())
+		Ω(data[:2]).Should(Equal([]byte{0xE2, 0x07}))
+
+		elem, err = Unmarshal(data)
+		Ω(err).Should(BeNil())
+		Ω(elem.Value()).Should(BeEquivalentTo("abcdefgéééé"))
+
+		enc := NewEncoder(&bytes.Buffer{})
+		enc.bytes([]byte("0123456789"))
+		Ω(enc.buf.Bytes()).Should(Equal([]byte{0xD8, 0x08, '0', '1', '2', '3', '4', '5', '6', '7', 0xD2, '8', '9'}))
+	})
+
+	It("should encode strings as UTF-16 units", func() {
+		Ω(encodeString("aé€😀")).Should(Equal([]byte{'a', 0xC3, 0xA9, 0xE2, 0x82, 0xAC, 0xED, 0xA0, 0xBD, 0xED, 0xB8, 0x80}))
+
+		s, err := decodeString(encodeString("aé€😀"))
+		Ω(err).Should(BeNil())
+		Ω(s).Should(BeEquivalentTo("aé€😀"))
+	})
+
+	It("should write big numbers as two's complement bytes", func() {
+		for src, expected := range map[string][]byte{
+			`0N`:    {0xC6, 0xD1, 0x00},
+			`127N`:  {0xC6, 0xD1, 0x7F},
+			`128N`:  {0xC6, 0xD2, 0x00, 0x80},
+			`-1N`:   {0xC6, 0xD1, 0xFF},
+			`-128N`: {0xC6, 0xD1, 0x80},
+			`-129N`: {0xC6, 0xD2, 0xFF, 0x7F},
+			`1.25M`: {0xC7, 0xD1, 0x7D, 0x02},
+			`1e3M`:  {0xC7, 0xD1, 0x01, 0x4F, 0xFD},
+		} {
+			data, err := Marshal(parse(src))
+			Ω(err).Should(BeNil(), src)
+			Ω(data).Should(Equal(expected), src)
+		}
+	})
+})
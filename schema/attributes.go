@@ -81,7 +81,7 @@ func NewAttribute(name string, elementType elements.ElementType, cardinality Att
 			AttrDocument:    d,
 		}
 	} else {
-		err = elements.ErrInvalidInput
+		err = wrapAttributeError(name, elements.ErrInvalidInput)
 	}
 
 	return attr, err
@@ -182,5 +182,5 @@ func (attr *attrImpl) BuildCollection() (elem elements.CollectionElement, err er
 		elem, err = elements.NewMap(pairs.Raw()...)
 	}
 
-	return elem, err
+	return elem, wrapAttributeError(attr.AttrName, err)
 }
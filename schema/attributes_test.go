@@ -1,6 +1,7 @@
 package schema
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/martinkreibe-wk/geneva/elements"
@@ -69,5 +70,23 @@ var _ = Describe("Attributes", func() {
 			Ω(edn).Should(ContainSubstring(":db/valueType " + string(attrType)))
 			Ω(edn).Should(ContainSubstring(":db/cardinality " + string(attrCard)))
 		})
+
+		It("should wrap the element errors with the attribute", func() {
+			_, err := NewAttribute("test", elements.StringType, OneCardinality, "one", "two")
+			Ω(errors.Is(err, elements.ErrInvalidInput)).Should(BeTrue())
+
+			var attrErr *AttributeError
+			Ω(errors.As(err, &attrErr)).Should(BeTrue())
+			Ω(attrErr.Attribute).Should(BeEquivalentTo("test"))
+
+			var attr Attribute
+			attr, err = NewAttribute("/bad", elements.StringType, OneCardinality)
+			Ω(err).Should(BeNil())
+
+			_, err = attr.BuildCollection()
+			Ω(errors.Is(err, elements.ErrInvalidKeyword)).Should(BeTrue())
+			Ω(errors.As(err, &attrErr)).Should(BeTrue())
+			Ω(attrErr.Attribute).Should(BeEquivalentTo("/bad"))
+		})
 	})
 })
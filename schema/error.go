@@ -0,0 +1,37 @@
+package schema
+
+import (
+	"fmt"
+)
+
+// AttributeError defines an error while building or validating an attribute. The underlying error, usually from the
+// elements package, is kept so errors.Is and errors.As still see it.
+type AttributeError struct {
+
+	// Attribute is the name of the attribute.
+	Attribute string
+
+	// Err is the underlying reason.
+	Err error
+}
+
+// Error returns the error message.
+func (e *AttributeError) Error() string {
+	return fmt.Sprintf("attribute %s: %s", e.Attribute, e.Err)
+}
+
+// Unwrap returns the underlying reason.
+func (e *AttributeError) Unwrap() error {
+	return e.Err
+}
+
+// wrapAttributeError wraps the error with the attribute name, nil stays nil.
+func wrapAttributeError(name string, err error) error {
+	if err != nil {
+		err = &AttributeError{
+			Attribute: name,
+			Err:       err,
+		}
+	}
+	return err
+}
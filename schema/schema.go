@@ -56,6 +56,9 @@ func (schema *schemaImpl) BuildCollection() (elem elements.CollectionElement, er
 						var coll elements.CollectionElement
 						if coll, err = attr.BuildCollection(); err == nil {
 
+							// the attribute may hand out a shared collection, so the install is added to a copy.
+							coll = elements.Clone(coll).(elements.CollectionElement)
+
 							var kw elements.Element
 							if kw, err = elements.NewKeywordElement(InstallOperation); err == nil {
 
@@ -65,7 +68,11 @@ func (schema *schemaImpl) BuildCollection() (elem elements.CollectionElement, er
 								}
 							}
 
-							err = attrs.Append(coll)
+							if err == nil {
+								err = attrs.Append(coll)
+							} else {
+								err = wrapAttributeError(attr.Name(), err)
+							}
 						}
 
 						if err != nil {
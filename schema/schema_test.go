@@ -1,6 +1,8 @@
 package schema
 
 import (
+	"errors"
+
 	"github.com/martinkreibe-wk/geneva/elements"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -101,7 +103,42 @@ var _ = Describe("Schema building", func() {
 			attr, err = schema.AddAttribute(attrName, attrType, attrCard, attrDoc, attrDoc2)
 			Ω(err).ShouldNot(BeNil())
 			Ω(attr).Should(BeNil())
-			Ω(err).Should(BeEquivalentTo(elements.ErrInvalidInput))
+			Ω(errors.Is(err, elements.ErrInvalidInput)).Should(BeTrue())
+		})
+
+		It("should leave frozen attribute collections unchanged", func() {
+
+			attr, err := NewAttribute("test", elements.StringType, OneCardinality)
+			Ω(err).Should(BeNil())
+
+			shared, err := attr.BuildCollection()
+			Ω(err).Should(BeNil())
+			elements.Freeze(shared)
+
+			schema := &schemaImpl{
+				name:       "my/foo",
+				attributes: []Attribute{&sharedAttribute{Attribute: attr, shared: shared}},
+			}
+
+			for i := 0; i < 2; i++ {
+				edn, err := schema.Serialize()
+				Ω(err).Should(BeNil())
+				Ω(edn).Should(ContainSubstring(":db.install/_attribute :db.part/db"))
+			}
+
+			_, err = shared.Get(":db.install/_attribute")
+			Ω(errors.Is(err, elements.ErrNoValue)).Should(BeTrue())
 		})
 	})
 })
+
+// sharedAttribute hands out the same collection every time it is built.
+type sharedAttribute struct {
+	Attribute
+	shared elements.CollectionElement
+}
+
+// BuildCollection returns the shared collection.
+func (attr *sharedAttribute) BuildCollection() (elements.CollectionElement, error) {
+	return attr.shared, nil
+}
@@ -0,0 +1,103 @@
+package transit
+
+const (
+
+	// cacheCodeDigits is the number of digits used to write a cache index.
+	cacheCodeDigits = 44
+
+	// baseCharIndex is the character of the first digit of a cache index.
+	baseCharIndex = 48
+
+	// maxCacheEntries is the number of entries after which the cache starts over.
+	maxCacheEntries = cacheCodeDigits * cacheCodeDigits
+
+	// minCacheableSize is the shortest string that is cached.
+	minCacheableSize = 4
+
+	// cachePrefix starts a cache reference.
+	cachePrefix = "^"
+)
+
+// cacheable is true if the encoded string is cached: it must be long enough and be a map key, a keyword, a symbol or
+// a tag.
+func cacheable(s string, asMapKey bool) bool {
+	return len(s) >= minCacheableSize && (asMapKey || (s[0] == escapeChar && (s[1] == keywordCode || s[1] == symbolCode || s[1] == tagCode)))
+}
+
+// isCacheCode is true if the string refers to a cache entry.
+func isCacheCode(s string) bool {
+	return len(s) > 1 && s[0] == cachePrefix[0] && s != mapAsArrayMarker
+}
+
+// cacheCode returns the reference for the cache index.
+func cacheCode(index int) string {
+	if index < cacheCodeDigits {
+		return cachePrefix + string(rune(index+baseCharIndex))
+	}
+	return cachePrefix + string(rune(index/cacheCodeDigits+baseCharIndex)) + string(rune(index%cacheCodeDigits+baseCharIndex))
+}
+
+// cacheIndex returns the cache index of the reference, or -1 if it is malformed.
+func cacheIndex(code string) (index int) {
+	switch len(code) {
+	case 2:
+		index = int(code[1]) - baseCharIndex
+	case 3:
+		index = (int(code[1])-baseCharIndex)*cacheCodeDigits + int(code[2]) - baseCharIndex
+	default:
+		index = -1
+	}
+	return index
+}
+
+// writeCache replaces repeated strings with references while writing.
+type writeCache struct {
+	codes map[string]string
+}
+
+// newWriteCache creates an empty cache.
+func newWriteCache() *writeCache {
+	return &writeCache{codes: map[string]string{}}
+}
+
+// cache returns the reference for the string if it was seen before, otherwise it remembers the string if it can be
+// cached and returns it.
+func (c *writeCache) cache(s string, asMapKey bool) (out string) {
+	out = s
+	if cacheable(s, asMapKey) {
+		if code, has := c.codes[s]; has {
+			out = code
+		} else {
+			if len(c.codes) == maxCacheEntries {
+				c.codes = map[string]string{}
+			}
+			c.codes[s] = cacheCode(len(c.codes))
+		}
+	}
+
+	return out
+}
+
+// readCache resolves references while reading.
+type readCache struct {
+	entries []string
+}
+
+// resolve returns the string the reference stands for, or remembers the string if it can be cached and returns it.
+func (c *readCache) resolve(s string, asMapKey bool) (out string, err error) {
+	out = s
+	if isCacheCode(s) {
+		if index := cacheIndex(s); index >= 0 && index < len(c.entries) {
+			out = c.entries[index]
+		} else {
+			err = ErrInvalidCacheCode
+		}
+	} else if cacheable(s, asMapKey) {
+		if len(c.entries) == maxCacheEntries {
+			c.entries = c.entries[:0]
+		}
+		c.entries = append(c.entries, s)
+	}
+
+	return out, err
+}
@@ -0,0 +1,392 @@
+package transit
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/martinkreibe-wk/geneva/elements"
+	"github.com/mattrobenolt/gocql/uuid"
+)
+
+// Unmarshal reads the transit+json value into an element. The data must hold exactly one value.
+func Unmarshal(data []byte) (elem elements.Element, err error) {
+	dec := NewDecoder(bytes.NewReader(data))
+	if elem, err = dec.Decode(); err == nil {
+		if _, e := dec.dec.Token(); e != io.EOF {
+			elem, err = nil, elements.ErrTrailingInput
+		}
+	} else if err == io.EOF {
+		err = elements.ErrUnexpectedEnd
+	}
+
+	return elem, err
+}
+
+// Decoder reads a stream of transit+json values. Each value has its own cache.
+type Decoder struct {
+	dec *json.Decoder
+}
+
+// NewDecoder creates a decoder that reads from the reader.
+func NewDecoder(r io.Reader) *Decoder {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	return &Decoder{dec: dec}
+}
+
+// Decode reads the next element, io.EOF is returned when there are no more.
+func (dec *Decoder) Decode() (elem elements.Element, err error) {
+	var value interface{}
+	if err = dec.dec.Decode(&value); err == nil {
+		rd := &reader{}
+		elem, err = rd.value(value)
+	}
+
+	return elem, err
+}
+
+// reader converts the values read by encoding/json to elements, in document order so that the cache references match.
+type reader struct {
+	cache readCache
+}
+
+// value converts a value.
+func (rd *reader) value(value interface{}) (elem elements.Element, err error) {
+
+	switch v := value.(type) {
+	case nil:
+		elem, err = elements.NewNilElement()
+
+	case bool:
+		elem, err = elements.NewBooleanElement(v)
+
+	case json.Number:
+		elem, err = number(v)
+
+	case string:
+		if v, err = rd.cache.resolve(v, false); err == nil {
+			elem, err = decodeString(v)
+		}
+
+	case []interface{}:
+		elem, err = rd.array(v)
+
+	case map[string]interface{}:
+		elem, err = rd.object(v)
+
+	default:
+		err = ErrInvalidTransit
+	}
+
+	return elem, err
+}
+
+// array converts a map-as-array, a tagged value or a vector.
+func (rd *reader) array(values []interface{}) (elem elements.Element, err error) {
+
+	first := ""
+	if len(values) > 0 {
+		if s, is := values[0].(string); is {
+			if s == mapAsArrayMarker {
+				first = s
+			} else {
+				first, err = rd.cache.resolve(s, false)
+			}
+		}
+	}
+
+	switch {
+	case err != nil:
+
+	case first == mapAsArrayMarker:
+		if len(values)%2 == 1 {
+			elem, err = rd.entries(values[1:], true)
+		} else {
+			err = elements.ErrInvalidPair
+		}
+
+	case len(values) == 2 && isTag(first):
+		elem, err = rd.tagged(first[2:], values[1])
+
+	default:
+		items := make([]elements.Element, len(values))
+		for i := 0; err == nil && i < len(values); i++ {
+			if i == 0 && first != "" {
+				items[i], err = decodeString(first)
+			} else {
+				items[i], err = rd.value(values[i])
+			}
+		}
+
+		if err == nil {
+			elem, err = elements.NewVector(items...)
+		}
+	}
+
+	return elem, err
+}
+
+// object converts a verbose map or tagged value.
+func (rd *reader) object(object map[string]interface{}) (elem elements.Element, err error) {
+
+	tag, tagged := "", interface{}(nil)
+	if len(object) == 1 {
+		for key, value := range object {
+			if isTag(key) {
+				tag, tagged = key[2:], value
+			}
+		}
+	}
+
+	if tag != "" {
+		elem, err = rd.tagged(tag, tagged)
+	} else {
+		items := make([]interface{}, 0, len(object)*2)
+		for key, value := range object {
+			items = append(items, key, value)
+		}
+
+		elem, err = rd.entries(items, true)
+	}
+
+	return elem, err
+}
+
+// entries converts alternating keys and values to a map. String keys of a map are cached as keys.
+func (rd *reader) entries(items []interface{}, stringKeys bool) (elem elements.Element, err error) {
+
+	var coll elements.CollectionElement
+	if coll, err = elements.NewMap(); err == nil {
+		for i := 0; err == nil && i < len(items); i += 2 {
+			var key, value elements.Element
+			if s, is := items[i].(string); is && stringKeys {
+				if s, err = rd.cache.resolve(s, true); err == nil {
+					key, err = decodeString(s)
+				}
+			} else {
+				key, err = rd.value(items[i])
+			}
+
+			if err == nil {
+				if value, err = rd.value(items[i+1]); err == nil {
+					// maps find keys by type and value, so only a key that equals the new one is a duplicate.
+					if _, e := coll.Get(key); e == nil {
+						err = elements.ErrDuplicateKey
+					} else {
+						err = coll.Append(key, value)
+					}
+				}
+			}
+		}
+		elem = coll
+	}
+
+	if err != nil {
+		elem = nil
+	}
+
+	return elem, err
+}
+
+// tagged converts a tagged value. Tags other than the built in ones are kept as tagged elements.
+func (rd *reader) tagged(tag string, value interface{}) (elem elements.Element, err error) {
+
+	items, isArray := value.([]interface{})
+
+	switch tag {
+	case quoteTag:
+		elem, err = rd.value(value)
+
+	case listTag, setTag:
+		if isArray {
+			children := make([]elements.Element, len(items))
+			for i := 0; err == nil && i < len(items); i++ {
+				children[i], err = rd.value(items[i])
+			}
+
+			if err == nil {
+				if tag == listTag {
+					elem, err = elements.NewGroup(children...)
+				} else {
+					elem, err = elements.NewSet(children...)
+				}
+			}
+		} else {
+			err = ErrInvalidTransit
+		}
+
+	case cmapTag:
+		if isArray && len(items)%2 == 0 {
+			elem, err = rd.entries(items, false)
+		} else {
+			err = ErrInvalidTransit
+		}
+
+	default:
+		var symbol elements.SymbolElement
+		var tagged elements.Element
+		if symbol, err = elements.NewSymbolElement(tag); err != nil {
+			err = elements.ErrInvalidTag
+		} else if tagged, err = rd.value(value); err == nil {
+			elem, err = elements.NewTaggedElement(symbol, tagged)
+		}
+	}
+
+	if err != nil {
+		elem = nil
+	}
+
+	return elem, err
+}
+
+// isTag is true if the string names a tagged value.
+func isTag(s string) bool {
+	return len(s) > 2 && s[0] == escapeChar && s[1] == tagCode
+}
+
+// number converts a JSON number to an integer, a big integer when it does not fit, or a float.
+func number(n json.Number) (elem elements.Element, err error) {
+	text := n.String()
+	if strings.ContainsAny(text, ".eE") {
+		var f float64
+		if f, err = n.Float64(); err == nil {
+			elem, err = elements.NewFloatElement(f)
+		}
+	} else if i, e := n.Int64(); e == nil {
+		elem, err = elements.NewIntegerElement(i)
+	} else if b, ok := new(big.Int).SetString(text, 10); ok {
+		elem, err = elements.NewBigIntElement(b)
+	} else {
+		err = elements.ErrInvalidNumber
+	}
+
+	return elem, err
+}
+
+// decodeString converts a string after its cache reference is resolved.
+func decodeString(s string) (elem elements.Element, err error) {
+
+	if len(s) >= 2 && s[0] == escapeChar {
+		elem, err = decodeEncoded(s[1], s[2:])
+	} else {
+		elem, err = elements.NewStringElement(s)
+	}
+
+	if err != nil {
+		elem, err = nil, &elements.ElementError{Value: s, Err: err}
+	}
+
+	return elem, err
+}
+
+// decodeEncoded converts the text of a string with a ~ code.
+func decodeEncoded(code byte, text string) (elem elements.Element, err error) {
+
+	switch code {
+	case escapeChar, cachePrefix[0], reservedTag:
+		elem, err = elements.NewStringElement(string(code) + text)
+
+	case nilCode:
+		elem, err = elements.NewNilElement()
+
+	case boolCode:
+		if text == "t" || text == "f" {
+			elem, err = elements.NewBooleanElement(text == "t")
+		} else {
+			err = ErrInvalidTransit
+		}
+
+	case intCode:
+		if i, e := strconv.ParseInt(text, 10, 64); e == nil {
+			elem, err = elements.NewIntegerElement(i)
+		} else if b, ok := new(big.Int).SetString(text, 10); ok {
+			elem, err = elements.NewBigIntElement(b)
+		} else {
+			err = elements.ErrInvalidNumber
+		}
+
+	case floatCode:
+		var f float64
+		if f, err = strconv.ParseFloat(text, 64); err == nil {
+			elem, err = elements.NewFloatElement(f)
+		} else {
+			err = elements.ErrInvalidNumber
+		}
+
+	case specialCode:
+		switch text {
+		case nanText:
+			elem, err = elements.NewFloatElement(math.NaN())
+		case infText:
+			elem, err = elements.NewFloatElement(math.Inf(1))
+		case negInfText:
+			elem, err = elements.NewFloatElement(math.Inf(-1))
+		default:
+			err = ErrInvalidTransit
+		}
+
+	case bigIntCode:
+		if b, ok := new(big.Int).SetString(text, 10); ok {
+			elem, err = elements.NewBigIntElement(b)
+		} else {
+			err = elements.ErrInvalidNumber
+		}
+
+	case bigDecCode:
+		if elem, err = elements.Parse([]byte(text + elements.BigDecSuffix)); err == nil && elem.ElementType() != elements.BigDecType {
+			err = elements.ErrInvalidNumber
+		}
+
+	case charCode:
+		if r, size := utf8.DecodeRuneInString(text); r != utf8.RuneError && size == len(text) {
+			elem, err = elements.NewCharacterElement(r)
+		} else {
+			err = elements.ErrInvalidCharacter
+		}
+
+	case keywordCode:
+		elem, err = elements.NewKeywordElement(elements.KeywordPrefix + text)
+
+	case symbolCode:
+		elem, err = elements.NewSymbolElement(text)
+
+	case millisCode:
+		var ms int64
+		if ms, err = strconv.ParseInt(text, 10, 64); err == nil {
+			elem, err = elements.NewInstantElement(time.Unix(0, ms*int64(time.Millisecond)).UTC())
+		} else {
+			err = ErrInvalidTransit
+		}
+
+	case timeCode:
+		var t time.Time
+		if t, err = time.Parse(time.RFC3339Nano, text); err == nil {
+			elem, err = elements.NewInstantElement(t)
+		} else {
+			err = ErrInvalidTransit
+		}
+
+	case uuidCode:
+		var u uuid.UUID
+		if u, err = uuid.ParseUUID(text); err == nil {
+			elem, err = elements.NewUUIDElement(u)
+		} else {
+			err = ErrInvalidTransit
+		}
+
+	case tagCode:
+		err = ErrInvalidTransit
+
+	default:
+		err = ErrUnknownEncoding
+	}
+
+	return elem, err
+}
@@ -0,0 +1,117 @@
+package transit
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/martinkreibe-wk/geneva/elements"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reading transit", func() {
+
+	parse := func(src string) elements.Element {
+		elem, err := elements.Parse([]byte(src))
+		Ω(err).Should(BeNil(), src)
+		return elem
+	}
+
+	It("should read what other writers produce", func() {
+		sources := map[string]string{
+			`[["^ ","~:db/ident","~:person/name"],["^ ","^0","~:person/age"],"^1"]`: `[{:db/ident :person/name} {:db/ident :person/age} :person/name]`,
+			`["~#'","~t1985-04-12T23:20:50.520Z"]`:                                  `#inst "1985-04-12T23:20:50.52Z"`,
+			`["~#'","~~escaped"]`:                                                   `"~escaped"`,
+			`["~#'","~?f"]`:                                                         `false`,
+			`["~#'","~_"]`:                                                          `nil`,
+			`["~#'","~d2.5"]`:                                                       `2.5`,
+			`["~#'","~i99999999999999999999"]`:                                      `99999999999999999999N`,
+			`{"~:a":1,"b":{"~#set":[]}}`:                                            `{:a 1 "b" #{}}`,
+			`["~#point",[1,2]]`:                                                     `#point [1 2]`,
+			`["~:ab","^0"]`:                                                         `[:ab :ab]`,
+			`["^ ","~~#tag",["~#tag",1]]`:                                           `{"~#tag" #tag 1}`,
+			`["^ ","a",1,"~$a",2]`:                                                  `{"a" 1 a 2}`,
+			`["~#cmap",["1","a",1,"b"]]`:                                            `{"1" "a" 1 "b"}`,
+		}
+
+		for src, expected := range sources {
+			elem, err := Unmarshal([]byte(src))
+			Ω(err).Should(BeNil(), src)
+			Ω(elem.Equals(parse(expected))).Should(BeTrue(), src)
+		}
+	})
+
+	It("should round trip the element types", func() {
+		sources := []string{
+			`nil`, `true`, `-7`, `9007199254740993`, `1.0`, `"~weird ^string"`, `"^ "`, `\λ`, `:db/ident`,
+			`:db/_ident`, `sym`, `123456789012345678901234567890N`, `0.001M`, `#inst "2020-01-02T03:04:05.123Z"`,
+			`#uuid "f81d4fae-7dec-11d0-a765-00a0c91e6bf6"`, `[]`, `()`, `#{}`, `{}`,
+			`{:a [1 (2 3) #{4}] "b" {:c nil} 1 2 \x 3 nil 4 2.5 5 #inst "2020-01-02T03:04:05Z" 6}`,
+			`{[1] #{2} {:k :v} (3)}`, `#my/tag {:a #other/tag :b}`, `[#a/b 1 #a/b 2 #a/b 3]`,
+			`{"a" 1 a 2 :a 3 "1" 4 1 5 1N 6}`,
+		}
+
+		for _, src := range sources {
+			elem := parse(src)
+			data, err := Marshal(elem)
+			Ω(err).Should(BeNil(), src)
+
+			back, err := Unmarshal(data)
+			Ω(err).Should(BeNil(), string(data))
+			Ω(back.Equals(elem)).Should(BeTrue(), string(data))
+		}
+	})
+
+	It("should restart the cache once it is full", func() {
+		coll, err := elements.NewVector()
+		Ω(err).Should(BeNil())
+		for i := 0; i < maxCacheEntries+10; i++ {
+			kw, err := elements.NewKeywordElement("k", "n"+strconv.Itoa(i))
+			Ω(err).Should(BeNil())
+			Ω(coll.Append(kw, kw)).Should(BeNil())
+		}
+
+		data, err := Marshal(coll)
+		Ω(err).Should(BeNil())
+		back, err := Unmarshal(data)
+		Ω(err).Should(BeNil())
+		Ω(back.Equals(coll)).Should(BeTrue())
+	})
+
+	It("should decode a stream of values", func() {
+		dec := NewDecoder(bytes.NewBufferString("[\"~:ab\",\"^0\"]\n[\"^0\"]\n"))
+
+		elem, err := dec.Decode()
+		Ω(err).Should(BeNil())
+		Ω(elem.Equals(parse(`[:ab :ab]`))).Should(BeTrue())
+
+		_, err = dec.Decode()
+		Ω(errors.Is(err, ErrInvalidCacheCode)).Should(BeTrue())
+
+		_, err = dec.Decode()
+		Ω(err).Should(BeIdenticalTo(io.EOF))
+	})
+
+	It("should refuse malformed transit", func() {
+		malformed := map[string]error{
+			`["^0"]`:                 ErrInvalidCacheCode,
+			`["~#'","~rhttp://x"]`:   ErrUnknownEncoding,
+			`["~#'","~?x"]`:          ErrInvalidTransit,
+			`["~#'","~cab"]`:         elements.ErrInvalidCharacter,
+			`["~#set",1]`:            ErrInvalidTransit,
+			`["^ ","~:a"]`:           elements.ErrInvalidPair,
+			`["^ ","~:ab",1,"^0",2]`: elements.ErrDuplicateKey,
+			`["~#'","~i1x"]`:         elements.ErrInvalidNumber,
+			`[1] [2]`:                elements.ErrTrailingInput,
+			``:                       elements.ErrUnexpectedEnd,
+		}
+
+		for src, expected := range malformed {
+			elem, err := Unmarshal([]byte(src))
+			Ω(elem).Should(BeNil(), src)
+			Ω(errors.Is(err, expected)).Should(BeTrue(), src)
+		}
+	})
+})
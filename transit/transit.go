@@ -0,0 +1,83 @@
+// Package transit reads and writes elements as transit+json, the wire format used for EDN data across the Clojure
+// ecosystem. Scalars that JSON cannot hold are written as strings with a ~ code (~: keywords, ~$ symbols, ~m instants,
+// ~u UUIDs and so on), lists, sets and maps with composite keys as tagged arrays, and maps as ["^ ", key, value, ...]
+// arrays. Map keys, keywords, symbols and tags that repeat are replaced by ^ cache references.
+package transit
+
+import (
+	"github.com/martinkreibe-wk/geneva/elements"
+)
+
+const (
+
+	// ErrInvalidTransit defines the error for JSON that is not valid transit.
+	ErrInvalidTransit = elements.Error("Invalid transit")
+
+	// ErrInvalidCacheCode defines the error for a cache reference to an entry that was never written.
+	ErrInvalidCacheCode = elements.Error("Invalid cache reference")
+
+	// ErrUnknownEncoding defines the error for a ~ code that has no element type, such as ~r URIs or ~b bytes.
+	ErrUnknownEncoding = elements.Error("Unknown transit encoding")
+
+	// escapeChar starts an encoded string.
+	escapeChar = '~'
+
+	// the codes following the escape character.
+	nilCode     = '_'
+	boolCode    = '?'
+	intCode     = 'i'
+	floatCode   = 'd'
+	specialCode = 'z'
+	bigIntCode  = 'n'
+	bigDecCode  = 'f'
+	charCode    = 'c'
+	keywordCode = ':'
+	symbolCode  = '$'
+	millisCode  = 'm'
+	timeCode    = 't'
+	uuidCode    = 'u'
+	tagCode     = '#'
+	reservedTag = '`'
+
+	// mapAsArrayMarker starts a map written as an array.
+	mapAsArrayMarker = "^ "
+
+	// the tags of the built in composite types.
+	quoteTag = "'"
+	listTag  = "list"
+	setTag   = "set"
+	cmapTag  = "cmap"
+
+	// the special floats.
+	nanText    = "NaN"
+	infText    = "INF"
+	negInfText = "-INF"
+
+	// maxJSONInt is the largest integer written as a JSON number, larger ones are written as ~i strings so that
+	// JavaScript readers do not lose precision.
+	maxJSONInt = 1<<53 - 1
+)
+
+// escape prefixes strings that would otherwise be read as encoded values.
+func escape(s string) string {
+	if len(s) > 0 && (s[0] == escapeChar || s[0] == cachePrefix[0] || s[0] == reservedTag) {
+		s = string(escapeChar) + s
+	}
+	return s
+}
+
+// encoded returns the string for the code and the text.
+func encoded(code byte, text string) string {
+	return string([]byte{escapeChar, code}) + text
+}
+
+// builtinTag returns the tag that is part of the element type rather than a tagged value.
+func builtinTag(elemType elements.ElementType) (tag string) {
+	switch elemType {
+	case elements.InstantType:
+		tag = elements.InstantElementTag
+	case elements.UUIDType:
+		tag = elements.UUIDElementTag
+	}
+	return tag
+}
@@ -0,0 +1,295 @@
+package transit
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/martinkreibe-wk/geneva/elements"
+	"github.com/mattrobenolt/gocql/uuid"
+)
+
+// Marshal writes the element as transit+json.
+func Marshal(elem elements.Element) (data []byte, err error) {
+	buf := &bytes.Buffer{}
+	if err = NewEncoder(buf).Encode(elem); err == nil {
+		data = bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+	}
+
+	return data, err
+}
+
+// Encoder writes a stream of elements as transit+json, one value per line. Each value has its own cache.
+type Encoder struct {
+	enc *json.Encoder
+}
+
+// NewEncoder creates an encoder that writes to the writer.
+func NewEncoder(w io.Writer) *Encoder {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &Encoder{enc: enc}
+}
+
+// Encode writes the element.
+func (enc *Encoder) Encode(elem elements.Element) (err error) {
+	wr := &writer{cache: newWriteCache()}
+
+	var value interface{}
+	if value, err = wr.value(elem); err == nil {
+
+		// top level scalars are quoted, as some JSON parsers only accept arrays and objects.
+		if _, is := value.([]interface{}); !is {
+			value = []interface{}{encoded(tagCode, quoteTag), value}
+		}
+
+		err = enc.enc.Encode(value)
+	}
+
+	return err
+}
+
+// writer converts elements to the values encoding/json writes, in the order they are written so that the cache
+// references match.
+type writer struct {
+	cache *writeCache
+}
+
+// value converts the element.
+func (wr *writer) value(elem elements.Element) (out interface{}, err error) {
+
+	// custom tags are written as tagged values around the element.
+	tag := elem.Tag()
+	if tag != builtinTag(elem.ElementType()) {
+		tagged := wr.cache.cache(encoded(tagCode, tag), false)
+		if out, err = wr.untagged(elem); err == nil {
+			out = []interface{}{tagged, out}
+		}
+	} else {
+		out, err = wr.untagged(elem)
+	}
+
+	return out, err
+}
+
+// untagged converts the element ignoring any custom tag.
+func (wr *writer) untagged(elem elements.Element) (out interface{}, err error) {
+
+	switch elem.ElementType() {
+	case elements.NilType:
+		out = nil
+
+	case elements.BooleanType:
+		out = elem.Value()
+
+	case elements.IntegerType:
+		if i := elem.Value().(int64); i <= maxJSONInt && i >= -maxJSONInt {
+			out = json.Number(strconv.FormatInt(i, 10))
+		} else {
+			out = encoded(intCode, strconv.FormatInt(i, 10))
+		}
+
+	case elements.FloatType:
+		if f := elem.Value().(float64); math.IsNaN(f) || math.IsInf(f, 0) {
+			out, err = scalarString(elem)
+		} else {
+			text := strconv.FormatFloat(f, 'g', -1, 64)
+			if !strings.ContainsAny(text, ".e") {
+				text += ".0"
+			}
+			out = json.Number(text)
+		}
+
+	case elements.VectorType:
+		out, err = wr.items(elem.(elements.CollectionElement))
+
+	case elements.GroupingType, elements.SetType:
+		name := listTag
+		if elem.ElementType() == elements.SetType {
+			name = setTag
+		}
+
+		tagged := wr.cache.cache(encoded(tagCode, name), false)
+		var items []interface{}
+		if items, err = wr.items(elem.(elements.CollectionElement)); err == nil {
+			out = []interface{}{tagged, items}
+		}
+
+	case elements.MapType:
+		out, err = wr.mapping(elem.(elements.CollectionElement))
+
+	case elements.TaggedType:
+		out, err = wr.value(elem.(elements.TaggedElement).Tagged())
+
+	default:
+		var s string
+		if s, err = scalarString(elem); err == nil {
+			out = wr.cache.cache(s, false)
+		}
+	}
+
+	return out, err
+}
+
+// items converts the children of a vector, list or set.
+func (wr *writer) items(coll elements.CollectionElement) (items []interface{}, err error) {
+	items = []interface{}{}
+	err = coll.IterateChildren(func(_ elements.Element, child elements.Element) (e error) {
+		var item interface{}
+		if item, e = wr.value(child); e == nil {
+			items = append(items, item)
+		}
+		return e
+	})
+
+	return items, err
+}
+
+// entry is a map entry with the sort key used to write maps in a stable order.
+type entry struct {
+	sortKey string
+	key     elements.Element
+	value   elements.Element
+}
+
+// mapping converts the map to a map-as-array when every key can be written as a string, and to a cmap otherwise.
+func (wr *writer) mapping(coll elements.CollectionElement) (out interface{}, err error) {
+
+	entries := []entry{}
+	stringKeys := true
+	err = coll.IterateChildren(func(key elements.Element, value elements.Element) (e error) {
+		var sortKey string
+		if isStringable(key) {
+			sortKey, e = scalarString(key)
+		} else {
+			stringKeys = false
+		}
+		entries = append(entries, entry{sortKey: sortKey, key: key, value: value})
+		return e
+	})
+
+	// cmap keys have no string form, so they are ordered by their EDN form.
+	for i := 0; err == nil && !stringKeys && i < len(entries); i++ {
+		entries[i].sortKey, err = entries[i].key.Serialize()
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].sortKey < entries[j].sortKey
+	})
+
+	if err == nil {
+		if stringKeys {
+			items := []interface{}{mapAsArrayMarker}
+			for i := 0; err == nil && i < len(entries); i++ {
+				var value interface{}
+				items = append(items, wr.cache.cache(entries[i].sortKey, true))
+				if value, err = wr.value(entries[i].value); err == nil {
+					items = append(items, value)
+				}
+			}
+			out = items
+		} else {
+			tagged := wr.cache.cache(encoded(tagCode, cmapTag), false)
+			items := []interface{}{}
+			for i := 0; err == nil && i < len(entries); i++ {
+				var key, value interface{}
+				if key, err = wr.value(entries[i].key); err == nil {
+					if value, err = wr.value(entries[i].value); err == nil {
+						items = append(items, key, value)
+					}
+				}
+			}
+			out = []interface{}{tagged, items}
+		}
+	}
+
+	return out, err
+}
+
+// isStringable is true if the element can be written as a string, which is what map keys must be.
+func isStringable(elem elements.Element) bool {
+	return !elem.ElementType().IsCollection() && elem.Tag() == builtinTag(elem.ElementType())
+}
+
+// symbolText returns the text of a symbol or keyword without its tag and keyword prefix.
+func symbolText(elem elements.Element) (text string, err error) {
+	if text, err = elem.Serialize(); err == nil {
+		if elem.HasTag() {
+			text = strings.TrimPrefix(text, elements.TagPrefix+elem.Tag()+" ")
+		}
+		text = strings.TrimPrefix(text, elements.KeywordPrefix)
+	}
+
+	return text, err
+}
+
+// scalarString returns the string form of a scalar element.
+func scalarString(elem elements.Element) (s string, err error) {
+
+	switch elem.ElementType() {
+	case elements.NilType:
+		s = encoded(nilCode, "")
+
+	case elements.BooleanType:
+		s = encoded(boolCode, "f")
+		if elem.Value().(bool) {
+			s = encoded(boolCode, "t")
+		}
+
+	case elements.IntegerType:
+		s = encoded(intCode, strconv.FormatInt(elem.Value().(int64), 10))
+
+	case elements.FloatType:
+		switch f := elem.Value().(float64); {
+		case math.IsNaN(f):
+			s = encoded(specialCode, nanText)
+		case math.IsInf(f, 1):
+			s = encoded(specialCode, infText)
+		case math.IsInf(f, -1):
+			s = encoded(specialCode, negInfText)
+		default:
+			s = encoded(floatCode, strconv.FormatFloat(f, 'g', -1, 64))
+		}
+
+	case elements.BigIntType:
+		s = encoded(bigIntCode, elem.Value().(*big.Int).String())
+
+	case elements.BigDecType:
+		s = encoded(bigDecCode, elem.Value().(*big.Float).Text('g', -1))
+
+	case elements.StringType:
+		s = escape(elem.Value().(string))
+
+	case elements.CharacterType:
+		s = encoded(charCode, string(elem.Value().(rune)))
+
+	case elements.KeywordType:
+		var text string
+		if text, err = symbolText(elem); err == nil {
+			s = encoded(keywordCode, text)
+		}
+
+	case elements.SymbolType:
+		var text string
+		if text, err = symbolText(elem); err == nil {
+			s = encoded(symbolCode, text)
+		}
+
+	case elements.InstantType:
+		s = encoded(millisCode, strconv.FormatInt(elem.Value().(time.Time).UnixNano()/int64(time.Millisecond), 10))
+
+	case elements.UUIDType:
+		s = encoded(uuidCode, elem.Value().(uuid.UUID).String())
+
+	default:
+		err = &elements.ElementError{Type: elem.ElementType(), Value: elem.Value(), Err: elements.ErrUnknownType}
+	}
+
+	return s, err
+}
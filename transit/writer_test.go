@@ -0,0 +1,83 @@
+package transit
+
+import (
+	"bytes"
+
+	"github.com/martinkreibe-wk/geneva/elements"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Writing transit", func() {
+
+	parse := func(src string) elements.Element {
+		elem, err := elements.Parse([]byte(src))
+		Ω(err).Should(BeNil(), src)
+		return elem
+	}
+
+	It("should write the scalars with their codes", func() {
+		scalars := map[string]string{
+			`nil`:                             `["~#'",null]`,
+			`true`:                            `["~#'",true]`,
+			`42`:                              `["~#'",42]`,
+			`9007199254740993`:                `["~#'","~i9007199254740993"]`,
+			`2.0`:                             `["~#'",2.0]`,
+			`"plain"`:                         `["~#'","plain"]`,
+			`"~tilde"`:                        `["~#'","~~tilde"]`,
+			`"^caret"`:                        `["~#'","~^caret"]`,
+			`\c`:                              `["~#'","~cc"]`,
+			`:db/ident`:                       `["~#'","~:db/ident"]`,
+			`my/symbol`:                       `["~#'","~$my/symbol"]`,
+			`12345678901234567890N`:           `["~#'","~n12345678901234567890"]`,
+			`1.5M`:                            `["~#'","~f1.5"]`,
+			`#inst "1985-04-12T23:20:50.52Z"`: `["~#'","~m482196050520"]`,
+			`#uuid "f81d4fae-7dec-11d0-a765-00a0c91e6bf6"`: `["~#'","~uf81d4fae-7dec-11d0-a765-00a0c91e6bf6"]`,
+		}
+
+		for src, expected := range scalars {
+			data, err := Marshal(parse(src))
+			Ω(err).Should(BeNil(), src)
+			Ω(string(data)).Should(BeEquivalentTo(expected), src)
+		}
+	})
+
+	It("should write collections and tags", func() {
+		collections := map[string]string{
+			`[1 (2) #{3}]`:         `[1,["~#list",[2]],["~#set",[3]]]`,
+			`{:b 2 :a 1}`:          `["^ ","~:a",1,"~:b",2]`,
+			`{[1] :x}`:             `["~#cmap",[[1],"~:x"]]`,
+			`{1 nil}`:              `["^ ","~i1",null]`,
+			`#db/id [:db.part/db]`: `["~#db/id",["~:db.part/db"]]`,
+		}
+
+		for src, expected := range collections {
+			data, err := Marshal(parse(src))
+			Ω(err).Should(BeNil(), src)
+			Ω(string(data)).Should(BeEquivalentTo(expected), src)
+		}
+	})
+
+	It("should cache repeated keys, keywords, symbols and tags", func() {
+		data, err := Marshal(parse(`[{:db/ident :person/name "doc" 1} {:db/ident :person/name "doc" 2} #{:a} #{:a}]`))
+		Ω(err).Should(BeNil())
+		Ω(string(data)).Should(BeEquivalentTo(`[["^ ","doc",1,"~:db/ident","~:person/name"],["^ ","doc",2,"^0","^1"],["~#set",["~:a"]],["^2",["~:a"]]]`))
+	})
+
+	It("should write one value per line with a fresh cache", func() {
+		buf := &bytes.Buffer{}
+		enc := NewEncoder(buf)
+		Ω(enc.Encode(parse(`[:db/ident :db/ident]`))).Should(BeNil())
+		Ω(enc.Encode(parse(`[:db/ident]`))).Should(BeNil())
+		Ω(buf.String()).Should(BeEquivalentTo("[\"~:db/ident\",\"^0\"]\n[\"~:db/ident\"]\n"))
+	})
+
+	It("should number the cache in base 44", func() {
+		Ω(cacheCode(0)).Should(BeEquivalentTo("^0"))
+		Ω(cacheCode(43)).Should(BeEquivalentTo("^["))
+		Ω(cacheCode(44)).Should(BeEquivalentTo("^10"))
+		for _, i := range []int{0, 1, 43, 44, 100, maxCacheEntries - 1} {
+			Ω(cacheIndex(cacheCode(i))).Should(BeEquivalentTo(i))
+		}
+	})
+})